@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/audit"
+	"github.com/MihailSergeenkov/gophermart/internal/app/clients"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/logger"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/notify"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/MihailSergeenkov/gophermart/internal/app/tokens"
+)
+
+// Пароль читается из окружения, чтобы не попадать в историю команд и список процессов.
+const adminPasswordEnv = "ADMIN_PASSWORD"
+
+var errAdminLoginRequired = errors.New("flag -admin-login is required")
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	ctx, cancelCtx := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancelCtx()
+
+	login := flag.String("admin-login", "", "login of the first admin")
+	email := flag.String("admin-email", "", "email of the first admin if the user has to be created")
+
+	c, err := config.Setup()
+	if err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+
+	if *login == "" {
+		return errAdminLoginRequired
+	}
+
+	l, err := logger.NewLogger(c.LogLevel)
+	if err != nil {
+		return fmt.Errorf("logger error: %w", err)
+	}
+
+	k, err := tokens.LoadKeyset(c)
+	if err != nil {
+		return fmt.Errorf("keyset error: %w", err)
+	}
+
+	n, err := notify.New(&c.Notify)
+	if err != nil {
+		return fmt.Errorf("notifier error: %w", err)
+	}
+
+	s, err := data.NewDBStorage(ctx, l, c.DatabaseURI)
+	if err != nil {
+		return fmt.Errorf("storage error: %w", err)
+	}
+	defer s.Close() //nolint:errcheck // Ошибка закрытия пула при завершении команды не важна
+
+	a := clients.NewAccrualClient(&c.Accrual, l)
+	au := audit.NewAuditor(s, l)
+
+	user, err := services.NewServices(s, k, n, a, au, nil, c).BootstrapAdmin(ctx, models.RegisterUserRequest{
+		Login:    *login,
+		Email:    *email,
+		Password: os.Getenv(adminPasswordEnv),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to bootstrap admin: %w", err)
+	}
+
+	log.Printf("user %q (ID %d) is now admin", user.Login, user.ID)
+
+	return nil
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/data"
 	"github.com/MihailSergeenkov/gophermart/internal/app/logger"
+	"github.com/MihailSergeenkov/gophermart/internal/app/notify"
+	"github.com/MihailSergeenkov/gophermart/internal/app/tokens"
+	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -53,6 +56,23 @@ func run() error {
 		return fmt.Errorf("logger error: %w", err)
 	}
 
+	k, err := tokens.LoadKeyset(c)
+	if err != nil {
+		return fmt.Errorf("keyset error: %w", err)
+	}
+
+	if !c.DevMode {
+		for _, kid := range k.ShortSecrets() {
+			l.Warn("HS256 secret is shorter than recommended",
+				zap.String("kid", kid), zap.Int("min_length", config.MinSecretKeyLength))
+		}
+	}
+
+	n, err := notify.New(&c.Notify)
+	if err != nil {
+		return fmt.Errorf("notifier error: %w", err)
+	}
+
 	s, err := data.NewDBStorage(ctx, l, c.DatabaseURI)
 	if err != nil {
 		return fmt.Errorf("storage error: %w", err)
@@ -69,7 +89,7 @@ func run() error {
 		return nil
 	})
 
-	a := app.InitApp(ctx, c, l, s)
+	a := app.InitApp(ctx, c, l, s, k, n)
 
 	g.Go(func() (err error) {
 		defer func() {
@@ -2,26 +2,50 @@ package app
 
 import (
 	"context"
+	"expvar"
 	"net/http"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/audit"
+	"github.com/MihailSergeenkov/gophermart/internal/app/cache"
+	"github.com/MihailSergeenkov/gophermart/internal/app/clients"
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/events"
 	"github.com/MihailSergeenkov/gophermart/internal/app/handlers"
 	"github.com/MihailSergeenkov/gophermart/internal/app/jobs"
+	"github.com/MihailSergeenkov/gophermart/internal/app/notify"
 	"github.com/MihailSergeenkov/gophermart/internal/app/routes"
 	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/MihailSergeenkov/gophermart/internal/app/tokens"
 	"go.uber.org/zap"
 )
 
-func InitApp(ctx context.Context, settings *config.Settings, logger *zap.Logger, store *data.DBStorage) *http.Server {
-	s := services.NewServices(store, settings)
-	h := handlers.NewHandlers(s, logger)
-	r := routes.NewRouter(h, settings, logger, store)
-	j := jobs.NewBackgroudProcessing(settings, logger, store)
+func InitApp(ctx context.Context, settings *config.Settings, logger *zap.Logger,
+	store *data.DBStorage, keys *tokens.Keyset, notifier notify.Notifier) *http.Server {
+	users := cache.NewUserStorage(store, settings.UserCache)
+	expvar.Publish("user_cache", expvar.Func(func() any { return users.UserCacheStats() }))
+	expvar.Publish("session_cache", expvar.Func(func() any { return users.SessionCacheStats() }))
+
+	accrual := clients.NewAccrualClient(&settings.Accrual, logger)
+	auditor := audit.NewAuditor(store, logger)
+	updates := events.NewBroker(store, logger, settings.OrderEvents.RetryDelay)
+	updates.Start(ctx)
+
+	notifications := notify.NewQueue(notifier, logger, settings.Notify.QueueSize)
+	notifications.Start(ctx)
+
+	s := services.NewServices(users, keys, notifications, accrual, auditor, updates, settings)
+	h := handlers.NewHandlers(s, logger, settings)
+	r := routes.NewRouter(h, settings, keys, logger, users)
+	j := jobs.NewBackgroudProcessing(settings, logger, store, auditor)
 	j.Start(ctx)
 
-	return &http.Server{
+	srv := &http.Server{
 		Addr:    settings.RunAddr,
 		Handler: r,
 	}
+	// Shutdown ждет завершения активных запросов, поэтому потоки событий закрываются при остановке сервера.
+	srv.RegisterOnShutdown(updates.Close)
+
+	return srv
 }
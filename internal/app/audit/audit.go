@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"go.uber.org/zap"
+)
+
+type Storager interface {
+	AddAuditEvent(ctx context.Context, e models.AuditEvent) error
+}
+
+type Auditor struct {
+	store  Storager
+	logger *zap.Logger
+}
+
+func NewAuditor(store Storager, logger *zap.Logger) *Auditor {
+	return &Auditor{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Record дополняет событие данными запроса из контекста (пользователь, ID запроса, IP) и сохраняет его.
+// Журнал аудита не должен ломать основную операцию, поэтому ошибка сохранения только логируется,
+// а запись выполняется, даже если клиент уже отменил запрос.
+func (a *Auditor) Record(ctx context.Context, e models.AuditEvent) {
+	if e.ActorID == 0 {
+		e.ActorID, _ = ctx.Value(common.KeyUserID).(int)
+	}
+
+	if e.RequestID == "" {
+		e.RequestID, _ = ctx.Value(common.KeyRequestID).(string)
+	}
+
+	if e.IP == "" {
+		e.IP, _ = ctx.Value(common.KeyClientIP).(string)
+	}
+
+	if err := a.store.AddAuditEvent(context.WithoutCancel(ctx), e); err != nil {
+		a.logger.Error("failed to record audit event",
+			zap.String("action", e.Action),
+			zap.String("target_type", e.TargetType),
+			zap.String("target_id", e.TargetID),
+			zap.Error(err))
+	}
+}
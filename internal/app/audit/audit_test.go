@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeStorage struct {
+	err    error
+	ctx    context.Context
+	events []models.AuditEvent
+}
+
+func (f *fakeStorage) AddAuditEvent(ctx context.Context, e models.AuditEvent) error {
+	f.ctx = ctx
+	f.events = append(f.events, e)
+	return f.err
+}
+
+func TestRecord(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	ctx = context.WithValue(ctx, common.KeyRequestID, "request")
+	ctx = context.WithValue(ctx, common.KeyClientIP, "192.0.2.1")
+
+	t.Run("fills request data from context", func(t *testing.T) {
+		store := &fakeStorage{}
+		a := NewAuditor(store, zap.NewNop())
+
+		a.Record(ctx, models.AuditEvent{Action: models.AuditWithdrawal, TargetType: models.AuditTargetOrder, UserID: 1})
+
+		require.Len(t, store.events, 1)
+		assert.Equal(t, models.AuditEvent{
+			Action:     models.AuditWithdrawal,
+			TargetType: models.AuditTargetOrder,
+			UserID:     1,
+			ActorID:    1,
+			RequestID:  "request",
+			IP:         "192.0.2.1",
+		}, store.events[0])
+	})
+
+	t.Run("explicit values are kept", func(t *testing.T) {
+		store := &fakeStorage{}
+		a := NewAuditor(store, zap.NewNop())
+
+		a.Record(ctx, models.AuditEvent{Action: models.AuditLoginFailed, ActorID: 2, IP: "198.51.100.7"})
+
+		require.Len(t, store.events, 1)
+		assert.Equal(t, 2, store.events[0].ActorID)
+		assert.Equal(t, "198.51.100.7", store.events[0].IP)
+	})
+
+	t.Run("canceled request is still recorded", func(t *testing.T) {
+		store := &fakeStorage{}
+		a := NewAuditor(store, zap.NewNop())
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		a.Record(canceled, models.AuditEvent{Action: models.AuditLogin})
+
+		require.Len(t, store.events, 1)
+		assert.NoError(t, store.ctx.Err())
+	})
+
+	t.Run("storage error is logged", func(t *testing.T) {
+		store := &fakeStorage{err: errors.New("some error")}
+		core, logs := observer.New(zap.ErrorLevel)
+		a := NewAuditor(store, zap.New(core))
+
+		a.Record(ctx, models.AuditEvent{Action: models.AuditLogin})
+
+		assert.Equal(t, 1, logs.FilterMessage("failed to record audit event").Len())
+	})
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats — счетчики обращений к кешу.
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Size   int    `json:"size"`
+}
+
+type entry[K comparable, V any] struct {
+	expiresAt time.Time
+	value     V
+	key       K
+}
+
+// TTLCache — ограниченный по размеру кеш с вытеснением давно не использованных записей
+// и сроком жизни каждой записи.
+type TTLCache[K comparable, V any] struct {
+	now      func() time.Time
+	items    map[K]*list.Element
+	order    *list.List
+	ttl      time.Duration
+	capacity int
+	gen      uint64
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	mu       sync.Mutex
+}
+
+func NewTTLCache[K comparable, V any](capacity int, ttl time.Duration) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		now:      time.Now,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+		ttl:      ttl,
+		capacity: capacity,
+	}
+}
+
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V]) //nolint:forcetypeassert // В списке хранятся только *entry
+		if c.now().Before(e.expiresAt) {
+			c.order.MoveToFront(el)
+			c.hits.Add(1)
+			return e.value, true
+		}
+
+		c.remove(el)
+	}
+
+	c.misses.Add(1)
+
+	var zero V
+	return zero, false
+}
+
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+}
+
+func (c *TTLCache[K, V]) set(key K, value V) {
+	expiresAt := c.now().Add(c.ttl)
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V]) //nolint:forcetypeassert // В списке хранятся только *entry
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Generation возвращает счетчик сбросов. Значение, прочитанное из хранилища после вызова Generation,
+// сохраняется через SetIfGeneration, чтобы сброс во время чтения не перезаписался устаревшим значением.
+func (c *TTLCache[K, V]) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.gen
+}
+
+// SetIfGeneration сохраняет значение, если с получения gen не было ни одного сброса. Счетчик общий для всех
+// ключей: DeleteFunc сбрасывает записи по значению и не знает ключей, которые еще читаются из хранилища.
+func (c *TTLCache[K, V]) SetIfGeneration(key K, value V, gen uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != gen {
+		return false
+	}
+
+	c.set(key, value)
+
+	return true
+}
+
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// DeleteFunc удаляет все записи, для значений которых match возвращает true. Перебирает весь кеш,
+// поэтому предназначен для редких массовых сбросов.
+func (c *TTLCache[K, V]) DeleteFunc(match func(V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+
+		if match(el.Value.(*entry[K, V]).value) { //nolint:forcetypeassert // В списке хранятся только *entry
+			c.remove(el)
+		}
+
+		el = next
+	}
+}
+
+func (c *TTLCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: size}
+}
+
+func (c *TTLCache[K, V]) remove(el *list.Element) {
+	e := c.order.Remove(el).(*entry[K, V]) //nolint:forcetypeassert // В списке хранятся только *entry
+	delete(c.items, e.key)
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(capacity int, ttl time.Duration) (*TTLCache[int, string], *time.Time) {
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	c := NewTTLCache[int, string](capacity, ttl)
+	c.now = func() time.Time { return now }
+
+	return c, &now
+}
+
+func TestTTLCacheGet(t *testing.T) {
+	c, _ := newTestCache(2, time.Minute)
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+
+	c.Set(1, "one")
+
+	v, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "one", v)
+
+	assert.Equal(t, Stats{Hits: 1, Misses: 1, Size: 1}, c.Stats())
+}
+
+func TestTTLCacheExpiration(t *testing.T) {
+	c, now := newTestCache(2, time.Minute)
+
+	c.Set(1, "one")
+	*now = now.Add(time.Minute)
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, Stats{Misses: 1}, c.Stats())
+}
+
+func TestTTLCacheZeroTTL(t *testing.T) {
+	c, _ := newTestCache(2, 0)
+
+	c.Set(1, "one")
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+}
+
+func TestTTLCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestCache(2, time.Minute)
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+	_, _ = c.Get(1)
+	c.Set(3, "three")
+
+	_, ok := c.Get(2)
+	assert.False(t, ok, "least recently used entry is evicted")
+
+	_, ok = c.Get(1)
+	assert.True(t, ok)
+
+	_, ok = c.Get(3)
+	assert.True(t, ok)
+
+	assert.Equal(t, 2, c.Stats().Size)
+}
+
+func TestTTLCacheSetRefreshesEntry(t *testing.T) {
+	c, now := newTestCache(2, time.Minute)
+
+	c.Set(1, "one")
+	*now = now.Add(30 * time.Second)
+	c.Set(1, "uno")
+	*now = now.Add(45 * time.Second)
+
+	v, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "uno", v)
+}
+
+func TestTTLCacheDelete(t *testing.T) {
+	c, _ := newTestCache(2, time.Minute)
+
+	c.Set(1, "one")
+	c.Delete(1)
+	c.Delete(2)
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Stats().Size)
+}
+
+func TestTTLCacheDeleteFunc(t *testing.T) {
+	c, _ := newTestCache(3, time.Minute)
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Set(3, "three")
+	c.DeleteFunc(func(v string) bool { return v != "two" })
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+
+	_, ok = c.Get(2)
+	assert.True(t, ok)
+
+	assert.Equal(t, 1, c.Stats().Size)
+}
+
+func TestTTLCacheSetIfGeneration(t *testing.T) {
+	c, _ := newTestCache(3, time.Minute)
+
+	gen := c.Generation()
+	assert.True(t, c.SetIfGeneration(1, "one", gen))
+
+	// Сброс между чтением из хранилища и сохранением отменяет сохранение.
+	gen = c.Generation()
+	c.Delete(1)
+	assert.False(t, c.SetIfGeneration(1, "stale", gen))
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+
+	gen = c.Generation()
+	c.DeleteFunc(func(string) bool { return false })
+	assert.False(t, c.SetIfGeneration(2, "stale", gen))
+	assert.True(t, c.SetIfGeneration(2, "two", c.Generation()))
+}
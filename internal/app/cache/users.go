@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+// UserStorage кеширует пользователей и сессии, которые по каждому запросу проверяет authMiddleware.
+// Пользователь сбрасывается при смене пароля и удалении, сессия — при выходе, обновлении refresh токена,
+// смене и сбросе пароля и удалении пользователя. Сброс локален для экземпляра сервиса, поэтому в остальных
+// экземплярах устаревшая запись живет не дольше TTL. Сессии хранятся короткий SessionTTL: отозванный токен
+// перестает действовать на всех экземплярах почти сразу.
+type UserStorage struct {
+	*data.DBStorage
+	store    cachedStore
+	users    *TTLCache[int, models.User]
+	sessions *TTLCache[string, models.Session]
+}
+
+// cachedStore — методы хранилища, которые читают или меняют кешируемые записи.
+type cachedStore interface {
+	GetUserByID(ctx context.Context, userID int) (models.User, error)
+	UpdatePassword(ctx context.Context, password []byte) error
+	RehashPassword(ctx context.Context, userID int, oldHash []byte, newHash []byte) error
+	ResetPassword(ctx context.Context, tokenHash []byte, password []byte) (int, error)
+	AnonymizeUser(ctx context.Context) error
+	GetSession(ctx context.Context, sessionID string) (models.Session, error)
+	RotateSession(ctx context.Context, oldHash []byte, newHash []byte, expiresAt time.Time) (models.Session, error)
+	RevokeSession(ctx context.Context) error
+}
+
+func NewUserStorage(store *data.DBStorage, settings config.UserCacheSettings) *UserStorage {
+	s := newUserStorage(store, settings)
+	s.DBStorage = store
+
+	return s
+}
+
+func newUserStorage(store cachedStore, settings config.UserCacheSettings) *UserStorage {
+	return &UserStorage{
+		store:    store,
+		users:    NewTTLCache[int, models.User](settings.Size, settings.TTL),
+		sessions: NewTTLCache[string, models.Session](settings.Size, settings.SessionTTL),
+	}
+}
+
+func (s *UserStorage) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	if u, ok := s.users.Get(userID); ok {
+		return u, nil
+	}
+
+	gen := s.users.Generation()
+
+	u, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.User{}, err //nolint:wrapcheck // Прозрачная обертка над хранилищем
+	}
+
+	s.users.SetIfGeneration(userID, u, gen)
+
+	return u, nil
+}
+
+func (s *UserStorage) UpdatePassword(ctx context.Context, password []byte) error {
+	defer s.invalidateCurrent(ctx)
+
+	return s.store.UpdatePassword(ctx, password) //nolint:wrapcheck // Прозрачная обертка над хранилищем
+}
+
+func (s *UserStorage) RehashPassword(ctx context.Context, userID int, oldHash []byte, newHash []byte) error {
+	defer s.users.Delete(userID)
+
+	//nolint:wrapcheck // Прозрачная обертка над хранилищем
+	return s.store.RehashPassword(ctx, userID, oldHash, newHash)
+}
+
+func (s *UserStorage) ResetPassword(ctx context.Context, tokenHash []byte, password []byte) (int, error) {
+	userID, err := s.store.ResetPassword(ctx, tokenHash, password)
+	if err != nil {
+		return 0, err //nolint:wrapcheck // Прозрачная обертка над хранилищем
+	}
+
+	s.invalidateUser(userID)
+
+	return userID, nil
+}
+
+func (s *UserStorage) AnonymizeUser(ctx context.Context) error {
+	defer s.invalidateCurrent(ctx)
+
+	return s.store.AnonymizeUser(ctx) //nolint:wrapcheck // Прозрачная обертка над хранилищем
+}
+
+func (s *UserStorage) GetSession(ctx context.Context, sessionID string) (models.Session, error) {
+	if session, ok := s.sessions.Get(sessionID); ok {
+		return session, nil
+	}
+
+	gen := s.sessions.Generation()
+
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return models.Session{}, err //nolint:wrapcheck // Прозрачная обертка над хранилищем
+	}
+
+	s.sessions.SetIfGeneration(sessionID, session, gen)
+
+	return session, nil
+}
+
+func (s *UserStorage) RotateSession(ctx context.Context,
+	oldHash []byte, newHash []byte, expiresAt time.Time) (models.Session, error) {
+	session, err := s.store.RotateSession(ctx, oldHash, newHash, expiresAt)
+	if err != nil {
+		return models.Session{}, err //nolint:wrapcheck // Прозрачная обертка над хранилищем
+	}
+
+	s.sessions.Delete(session.ID)
+
+	return session, nil
+}
+
+func (s *UserStorage) RevokeSession(ctx context.Context) error {
+	defer func() {
+		if sessionID, ok := ctx.Value(common.KeySessionID).(string); ok {
+			s.sessions.Delete(sessionID)
+		}
+	}()
+
+	return s.store.RevokeSession(ctx) //nolint:wrapcheck // Прозрачная обертка над хранилищем
+}
+
+func (s *UserStorage) UserCacheStats() Stats {
+	return s.users.Stats()
+}
+
+func (s *UserStorage) SessionCacheStats() Stats {
+	return s.sessions.Stats()
+}
+
+func (s *UserStorage) invalidateCurrent(ctx context.Context) {
+	if userID, ok := ctx.Value(common.KeyUserID).(int); ok {
+		s.invalidateUser(userID)
+	}
+}
+
+// invalidateUser сбрасывает пользователя и все его сессии: их отзыв выполняется в той же операции хранилища.
+func (s *UserStorage) invalidateUser(userID int) {
+	s.users.Delete(userID)
+	s.sessions.DeleteFunc(func(session models.Session) bool {
+		return session.UserID == userID
+	})
+}
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore считает обращения к хранилищу и отзывает сессии так же, как DBStorage.
+type countingStore struct {
+	sessions     map[string]models.Session
+	onGetSession func()
+	userCalls    int
+	sessionCalls int
+}
+
+func (s *countingStore) GetUserByID(_ context.Context, userID int) (models.User, error) {
+	s.userCalls++
+	return models.User{ID: userID, Role: models.RoleUser}, nil
+}
+
+func (s *countingStore) UpdatePassword(ctx context.Context, _ []byte) error {
+	current := s.sessions[ctx.Value(common.KeySessionID).(string)] //nolint:forcetypeassert // Тест всегда передает ID
+	s.revokeUser(current.UserID)
+	s.sessions[current.ID] = current
+
+	return nil
+}
+
+func (s *countingStore) RehashPassword(context.Context, int, []byte, []byte) error {
+	return nil
+}
+
+func (s *countingStore) ResetPassword(context.Context, []byte, []byte) (int, error) {
+	s.revokeUser(1)
+	return 1, nil
+}
+
+func (s *countingStore) AnonymizeUser(ctx context.Context) error {
+	s.revokeUser(ctx.Value(common.KeyUserID).(int)) //nolint:forcetypeassert // Тест всегда передает ID
+	return nil
+}
+
+func (s *countingStore) GetSession(_ context.Context, sessionID string) (models.Session, error) {
+	s.sessionCalls++
+	session := s.sessions[sessionID]
+
+	if s.onGetSession != nil {
+		s.onGetSession()
+	}
+
+	return session, nil
+}
+
+func (s *countingStore) RotateSession(_ context.Context,
+	_ []byte, _ []byte, expiresAt time.Time) (models.Session, error) {
+	session := s.sessions["s1"]
+	session.ExpiresAt = expiresAt
+	s.sessions["s1"] = session
+
+	return session, nil
+}
+
+func (s *countingStore) RevokeSession(ctx context.Context) error {
+	sessionID := ctx.Value(common.KeySessionID).(string) //nolint:forcetypeassert // Тест всегда передает ID
+	session := s.sessions[sessionID]
+	session.Revoked = true
+	s.sessions[sessionID] = session
+
+	return nil
+}
+
+func (s *countingStore) revokeUser(userID int) {
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			session.Revoked = true
+			s.sessions[id] = session
+		}
+	}
+}
+
+func TestUserStorageCachesSessions(t *testing.T) {
+	settings := config.UserCacheSettings{TTL: time.Minute, SessionTTL: time.Minute, Size: 10}
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	ctx = context.WithValue(ctx, common.KeySessionID, "s1")
+
+	newStorage := func() (*UserStorage, *countingStore) {
+		store := &countingStore{sessions: map[string]models.Session{
+			"s1": {ID: "s1", UserID: 1},
+			"s2": {ID: "s2", UserID: 1},
+			"s3": {ID: "s3", UserID: 2},
+		}}
+
+		return newUserStorage(store, settings), store
+	}
+
+	// authRequest повторяет обращения authMiddleware к хранилищу.
+	authRequest := func(t *testing.T, s *UserStorage, sessionID string) models.Session {
+		t.Helper()
+
+		session, err := s.GetSession(ctx, sessionID)
+		require.NoError(t, err)
+
+		_, err = s.GetUserByID(ctx, session.UserID)
+		require.NoError(t, err)
+
+		return session
+	}
+
+	t.Run("repeated requests", func(t *testing.T) {
+		s, store := newStorage()
+
+		for range 5 {
+			authRequest(t, s, "s1")
+		}
+
+		assert.Equal(t, 1, store.sessionCalls)
+		assert.Equal(t, 1, store.userCalls)
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		s, store := newStorage()
+
+		authRequest(t, s, "s1")
+		authRequest(t, s, "s2")
+		require.NoError(t, s.RevokeSession(ctx))
+
+		assert.True(t, authRequest(t, s, "s1").Revoked)
+		assert.False(t, authRequest(t, s, "s2").Revoked)
+		assert.Equal(t, 3, store.sessionCalls)
+	})
+
+	t.Run("refresh rotation", func(t *testing.T) {
+		s, store := newStorage()
+
+		authRequest(t, s, "s1")
+		expiresAt := time.Now().Add(time.Hour)
+		_, err := s.RotateSession(ctx, nil, nil, expiresAt)
+		require.NoError(t, err)
+
+		assert.Equal(t, expiresAt, authRequest(t, s, "s1").ExpiresAt)
+		assert.Equal(t, 2, store.sessionCalls)
+	})
+
+	t.Run("password reset", func(t *testing.T) {
+		s, store := newStorage()
+
+		authRequest(t, s, "s1")
+		authRequest(t, s, "s2")
+		authRequest(t, s, "s3")
+		_, err := s.ResetPassword(context.Background(), nil, nil)
+		require.NoError(t, err)
+
+		assert.True(t, authRequest(t, s, "s1").Revoked)
+		assert.True(t, authRequest(t, s, "s2").Revoked)
+		assert.False(t, authRequest(t, s, "s3").Revoked)
+		assert.Equal(t, 5, store.sessionCalls)
+	})
+
+	t.Run("logout during session load", func(t *testing.T) {
+		s, store := newStorage()
+
+		// Выход завершается, пока сессия читается из хранилища: прочитанная до отзыва запись не кешируется.
+		store.onGetSession = func() {
+			store.onGetSession = nil
+			require.NoError(t, s.RevokeSession(ctx))
+		}
+
+		assert.False(t, authRequest(t, s, "s1").Revoked)
+		assert.True(t, authRequest(t, s, "s1").Revoked)
+		assert.Equal(t, 2, store.sessionCalls)
+	})
+
+	t.Run("password change and anonymization", func(t *testing.T) {
+		s, store := newStorage()
+
+		authRequest(t, s, "s2")
+		require.NoError(t, s.UpdatePassword(ctx, nil))
+		assert.True(t, authRequest(t, s, "s2").Revoked)
+
+		require.NoError(t, s.AnonymizeUser(ctx))
+		authRequest(t, s, "s2")
+		assert.Equal(t, 3, store.sessionCalls)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"go.uber.org/zap"
 )
 
@@ -21,10 +22,18 @@ var (
 	ErrUnexpectedStatusCode = errors.New("unexpected status code")
 )
 
+// OrderStatuses сопоставляет статусы системы начислений статусам заказов.
+var OrderStatuses = map[string]string{
+	"REGISTERED": "PROCESSING",
+	"PROCESSING": "PROCESSING",
+	"INVALID":    "INVALID",
+	"PROCESSED":  "PROCESSED",
+}
+
 type responseOrderAccrual struct {
-	Order   string  `json:"order"`
-	Status  string  `json:"status"`
-	Accrual float32 `json:"accrual,omitempty"`
+	Order   string        `json:"order"`
+	Status  string        `json:"status"`
+	Accrual models.Points `json:"accrual,omitempty"`
 }
 
 type AccrualClient struct {
@@ -57,7 +66,7 @@ func NewAccrualClient(settings *config.AccrualSettings, logger *zap.Logger) *Acc
 	}
 }
 
-func (ac *AccrualClient) GetOrderAccrual(number string) (string, float32, error) {
+func (ac *AccrualClient) GetOrderAccrual(number string) (string, models.Points, error) {
 	const path = "/api/orders/"
 	result, err := url.JoinPath(ac.systemAddress, path, number)
 	if err != nil {
@@ -79,7 +88,7 @@ func (ac *AccrualClient) GetOrderAccrual(number string) (string, float32, error)
 	return parseResponse(ac, response)
 }
 
-func parseResponse(ac *AccrualClient, response *http.Response) (string, float32, error) {
+func parseResponse(ac *AccrualClient, response *http.Response) (string, models.Points, error) {
 	switch response.StatusCode {
 	case http.StatusOK:
 		return decodeResponse(response)
@@ -94,7 +103,7 @@ func parseResponse(ac *AccrualClient, response *http.Response) (string, float32,
 	}
 }
 
-func decodeResponse(response *http.Response) (string, float32, error) {
+func decodeResponse(response *http.Response) (string, models.Points, error) {
 	var res responseOrderAccrual
 
 	dec := json.NewDecoder(response.Body)
@@ -0,0 +1,178 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"go.uber.org/zap"
+)
+
+const (
+	WebhookEventHeader     = "X-Gophermart-Event"
+	WebhookDeliveryHeader  = "X-Gophermart-Delivery"
+	WebhookSignatureHeader = "X-Gophermart-Signature"
+)
+
+// webhookEvent — тело запроса вебхука. ID совпадает с ID доставки и не меняется при повторах,
+// по нему получатель может отбросить дубликаты.
+type webhookEvent struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	ID        int64           `json:"id"`
+}
+
+var ErrWebhookAddress = errors.New("webhook address is not public")
+
+// nonPublicPrefixes — специальные диапазоны, которые не отсекаются методами netip.Addr:
+// общий адрес провайдера, служебные, тестовые и зарезервированные сети, трансляция IPv4 в IPv6.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsPublicAddr сообщает, можно ли отправлять вебхук на адрес: локальные, частные, link-local (в том числе
+// адрес метаданных облака 169.254.169.254) и зарезервированные адреса запрещены.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() ||
+		addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return false
+	}
+
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// publicOnlyControl проверяет адрес уже после разрешения имени, непосредственно перед подключением,
+// поэтому подмена DNS после создания подписки не открывает доступ во внутреннюю сеть.
+func publicOnlyControl(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse dial address: %w", err)
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("failed to parse dial address: %w", err)
+	}
+
+	if !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddress, addr)
+	}
+
+	return nil
+}
+
+type WebhookClient struct {
+	logger *zap.Logger
+	client http.Client
+}
+
+func NewWebhookClient(settings *config.WebhookSettings, logger *zap.Logger) *WebhookClient {
+	dialer := &net.Dialer{Timeout: settings.Timeout}
+	if !settings.AllowPrivate {
+		dialer.Control = publicOnlyControl
+	}
+
+	//nolint:forcetypeassert // Тип транспорта по умолчанию задан стандартной библиотекой
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Прокси не используется: иначе проверялся бы адрес прокси, а не получателя.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookClient{
+		client: http.Client{
+			Transport: transport,
+			Timeout:   settings.Timeout,
+			// Перенаправления не выполняются: подписчик должен указать итоговый адрес.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+	}
+}
+
+// Deliver отправляет событие подписчику и возвращает код ответа. Доставка успешна только при ответе 2xx.
+func (wc *WebhookClient) Deliver(ctx context.Context, d models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(webhookEvent{ID: d.ID, Type: d.EventType, CreatedAt: d.CreatedAt, Data: d.Payload})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to construct request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, d.EventType)
+	request.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	request.Header.Set(WebhookSignatureHeader, SignWebhook(d.Secret, time.Now().Unix(), body))
+
+	response, err := wc.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer closeWebhookBody(wc, response)
+
+	// Тело ответа дочитывается, чтобы соединение вернулось в пул.
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return response.StatusCode, fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// SignWebhook возвращает подпись в формате t=<unix time>,v1=<hex HMAC-SHA256 от "<unix time>.<тело>">.
+// Время входит в подпись, чтобы получатель мог отклонять старые перехваченные запросы.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func closeWebhookBody(wc *WebhookClient, r *http.Response) {
+	err := r.Body.Close()
+
+	if err != nil {
+		wc.logger.Error("failed to close webhook response body", zap.Error(err))
+	}
+}
@@ -0,0 +1,146 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSignWebhook(t *testing.T) {
+	assert.Equal(t,
+		"t=1700000000,v1=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11",
+		SignWebhook("secret", 1700000000, []byte(`{"id":1}`)))
+}
+
+func TestWebhookClientDeliver(t *testing.T) {
+	const secret = "0123456789abcdef"
+
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	var got *http.Request
+	var gotBody []byte
+	status := http.StatusOK
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		if status == http.StatusFound {
+			http.Redirect(w, r, "/elsewhere", status)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	client := NewWebhookClient(&config.WebhookSettings{Timeout: time.Second, AllowPrivate: true}, zap.NewNop())
+	delivery := models.WebhookDelivery{
+		ID:        7,
+		EventType: models.WebhookOrderProcessed,
+		Payload:   json.RawMessage(`{"number":"12345678903","status":"PROCESSED","accrual":5}`),
+		CreatedAt: createdAt,
+		URL:       receiver.URL + "/hook",
+		Secret:    secret,
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		status = http.StatusNoContent
+
+		code, err := client.Deliver(context.Background(), delivery)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		assert.Equal(t, http.MethodPost, got.Method)
+		assert.Equal(t, "/hook", got.URL.Path)
+		assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+		assert.Equal(t, models.WebhookOrderProcessed, got.Header.Get(WebhookEventHeader))
+		assert.Equal(t, "7", got.Header.Get(WebhookDeliveryHeader))
+		assert.JSONEq(t, `{"id":7,"type":"ORDER_PROCESSED","created_at":"2024-05-01T00:00:00Z",`+
+			`"data":{"number":"12345678903","status":"PROCESSED","accrual":5}}`, string(gotBody))
+
+		ts, _, ok := strings.Cut(strings.TrimPrefix(got.Header.Get(WebhookSignatureHeader), "t="), ",")
+		require.True(t, ok)
+		timestamp, err := strconv.ParseInt(ts, 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, SignWebhook(secret, timestamp, gotBody), got.Header.Get(WebhookSignatureHeader))
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		status = http.StatusInternalServerError
+
+		code, err := client.Deliver(context.Background(), delivery)
+		assert.ErrorIs(t, err, ErrUnexpectedStatusCode)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("redirect is not followed", func(t *testing.T) {
+		status = http.StatusFound
+
+		code, err := client.Deliver(context.Background(), delivery)
+		assert.ErrorIs(t, err, ErrUnexpectedStatusCode)
+		assert.Equal(t, http.StatusFound, code)
+	})
+
+	t.Run("receiver is unavailable", func(t *testing.T) {
+		d := delivery
+		d.URL = "http://127.0.0.1:1/hook"
+
+		code, err := client.Deliver(context.Background(), d)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrUnexpectedStatusCode))
+		assert.Zero(t, code)
+	})
+
+	t.Run("private address is refused at dial time", func(t *testing.T) {
+		strict := NewWebhookClient(&config.WebhookSettings{Timeout: time.Second}, zap.NewNop())
+		got = nil
+
+		code, err := strict.Deliver(context.Background(), delivery)
+		assert.ErrorIs(t, err, ErrWebhookAddress)
+		assert.Zero(t, code)
+		assert.Nil(t, got)
+	})
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{addr: "93.184.216.34", public: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{addr: "127.0.0.1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "255.255.255.255"},
+		{addr: "224.0.0.1"},
+		{addr: "::1"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "64:ff9b::a00:1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			assert.Equal(t, test.public, IsPublicAddr(netip.MustParseAddr(test.addr)))
+		})
+	}
+}
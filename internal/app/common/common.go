@@ -2,4 +2,11 @@ package common
 
 type ContextValueKey int
 
-const KeyUserID ContextValueKey = iota
+const (
+	KeyUserID ContextValueKey = iota
+	KeySessionID
+	KeyUserRole
+	KeyRequestID
+	KeyClientIP
+	KeyTokenExpiresAt
+)
@@ -1,22 +1,218 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"regexp"
 	"time"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/caarlos0/env/v11"
 	"go.uber.org/zap/zapcore"
 )
 
+const defaultSecretKey = "1234567890"
+
+// MinSecretKeyLength — рекомендуемая длина секрета HS256, 256 бит по размеру подписи. О более коротких
+// секретах сервис предупреждает при запуске.
+const MinSecretKeyLength = 32
+
+var (
+	ErrDefaultSecretKey   = errors.New("default secret key is allowed only in dev mode")
+	ErrCookieSameSite     = errors.New("cookie SameSite must be one of lax, strict or none")
+	ErrCookieInsecureNone = errors.New("cookie SameSite=None requires secure cookie")
+	ErrLoginMaxLength     = errors.New("login max length must be between 1 and 200")
+	ErrPasswordHashAlgo   = errors.New("password hash algorithm must be argon2id or bcrypt")
+	ErrNotifyDriver       = errors.New("notify driver must be log or smtp")
+	ErrNotifyQueueSize    = errors.New("notify queue size must be positive")
+	ErrOrderNumberCheck   = errors.New("order number validator must be luhn, iso7812 or pattern")
+	ErrOrderNumberPattern = errors.New("order number pattern must be a valid regular expression")
+	ErrOrderEventsPeriod  = errors.New("order events heartbeat, retry delay and session check must be positive")
+	ErrWebhookSettings    = errors.New("webhook periods, attempts and batch size must be positive")
+	ErrInvalidPeriod      = errors.New("background job periods and idempotency lock timeout must be positive")
+)
+
+const (
+	NotifyDriverLog  = "log"
+	NotifyDriverSMTP = "smtp"
+)
+
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+const (
+	OrderNumberLuhn    = "luhn"
+	OrderNumberISO7812 = "iso7812"
+	OrderNumberPattern = "pattern"
+)
+
+// MaxLoginLength совпадает с размером колонки users.login.
+const MaxLoginLength = 200
+
+const (
+	SameSiteLax    = "lax"
+	SameSiteStrict = "strict"
+	SameSiteNone   = "none"
+)
+
 type Settings struct {
 	RunAddr                    string `env:"RUN_ADDRESS" envDefault:"localhost:8080"`
 	DatabaseURI                string `env:"DATABASE_URI" envDefault:"postgresql://localhost:5432/test"`
 	SecretKey                  string `env:"SECRET_KEY" envDefault:"1234567890"`
+	DevMode                    bool   `env:"DEV_MODE" envDefault:"false"`
 	Accrual                    AccrualSettings
+	Auth                       AuthSettings
 	ProcessOrderAccrualPeriod  time.Duration `env:"PROCESS_ORDER_ACCRUAL_PERIOD" envDefault:"10s"`
 	ProcessOrderAccrualWorkers int           `env:"PROCESS_ORDER_ACCRUAL_WORKERS" envDefault:"3"`
 	LogLevel                   zapcore.Level `env:"LOG_LEVEL" envDefault:"ERROR"`
+	Idempotency                IdempotencySettings
+	LoginThrottle              LoginThrottleSettings
+	Credentials                CredentialsSettings
+	PasswordHash               PasswordHashSettings
+	TwoFactor                  TwoFactorSettings
+	PasswordReset              PasswordResetSettings
+	Notify                     NotifySettings
+	UserCache                  UserCacheSettings
+	OrderNumber                OrderNumberSettings
+	OrderEvents                OrderEventsSettings
+	Webhooks                   WebhookSettings
+	CleanupPeriod              time.Duration `env:"CLEANUP_PERIOD" envDefault:"1h"`
+}
+
+type AuthSettings struct {
+	KeysFile        string        `env:"AUTH_KEYS_FILE"`
+	Keys            string        `env:"AUTH_KEYS"`
+	ActiveKeyID     string        `env:"AUTH_ACTIVE_KEY_ID"`
+	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+	Cookie          CookieSettings
+}
+
+type CookieSettings struct {
+	SameSite string `env:"AUTH_COOKIE_SAME_SITE" envDefault:"lax"`
+	Secure   bool   `env:"AUTH_COOKIE_SECURE" envDefault:"false"`
+}
+
+// IdempotencySettings задает срок хранения ключа и срок, на который ключ захватывается выполняемым запросом.
+// Если запрос не завершился за LockTimeout, повтор с тем же ключом выполняется заново.
+type IdempotencySettings struct {
+	KeyTTL      time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	LockTimeout time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" envDefault:"1m"`
+}
+
+// LoginThrottleSettings задает блокировку входа после серии неудачных попыток. Нулевой порог отключает проверку.
+type LoginThrottleSettings struct {
+	FailureWindow    time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	BaseLockout      time.Duration `env:"LOGIN_LOCKOUT_BASE" envDefault:"1m"`
+	MaxLockout       time.Duration `env:"LOGIN_LOCKOUT_MAX" envDefault:"1h"`
+	MaxLoginFailures int           `env:"LOGIN_MAX_FAILURES" envDefault:"5"`
+	MaxIPFailures    int           `env:"LOGIN_MAX_IP_FAILURES" envDefault:"20"`
+}
+
+func (s LoginThrottleSettings) Enabled() bool {
+	return s.MaxLoginFailures > 0 || s.MaxIPFailures > 0
+}
+
+// CredentialsSettings задает требования к логину и паролю при регистрации.
+type CredentialsSettings struct {
+	LoginMinLength     int `env:"LOGIN_MIN_LENGTH" envDefault:"3"`
+	LoginMaxLength     int `env:"LOGIN_MAX_LENGTH" envDefault:"200"`
+	PasswordMinLength  int `env:"PASSWORD_MIN_LENGTH" envDefault:"8"`
+	PasswordMinClasses int `env:"PASSWORD_MIN_CHAR_CLASSES" envDefault:"2"`
+}
+
+// PasswordHashSettings задает алгоритм хеширования новых паролей. Хеши с другими параметрами
+// пересчитываются при следующем успешном входе.
+type PasswordHashSettings struct {
+	Algorithm     string `env:"PASSWORD_HASH_ALGORITHM" envDefault:"argon2id"`
+	BcryptCost    int    `env:"BCRYPT_COST" envDefault:"10"`
+	Argon2Memory  uint32 `env:"ARGON2_MEMORY" envDefault:"65536"`
+	Argon2Time    uint32 `env:"ARGON2_TIME" envDefault:"3"`
+	Argon2Threads uint8  `env:"ARGON2_THREADS" envDefault:"2"`
+}
+
+// TwoFactorSettings задает параметры TOTP. Нулевой WithdrawThreshold отключает запрос кода при списании.
+// После MaxWithdrawFailures неверных кодов при списании ввод кода блокируется на время, которое считается
+// по правилам LoginThrottle; нулевое значение отключает блокировку.
+type TwoFactorSettings struct {
+	Issuer               string        `env:"TOTP_ISSUER" envDefault:"Gophermart"`
+	ChallengeTTL         time.Duration `env:"TOTP_CHALLENGE_TTL" envDefault:"5m"`
+	WithdrawThreshold    models.Points `env:"TOTP_WITHDRAW_THRESHOLD" envDefault:"1000"`
+	MaxChallengeAttempts int           `env:"TOTP_CHALLENGE_ATTEMPTS" envDefault:"5"`
+	MaxWithdrawFailures  int           `env:"TOTP_WITHDRAW_MAX_FAILURES" envDefault:"5"`
+}
+
+// PasswordResetSettings задает срок действия токена сброса пароля и минимальный интервал между письмами.
+// URL — адрес страницы сброса, токен добавляется к нему параметром token.
+type PasswordResetSettings struct {
+	URL             string        `env:"PASSWORD_RESET_URL"`
+	TokenTTL        time.Duration `env:"PASSWORD_RESET_TTL" envDefault:"1h"`
+	RequestInterval time.Duration `env:"PASSWORD_RESET_INTERVAL" envDefault:"1m"`
+}
+
+// NotifySettings выбирает канал доставки уведомлений. Канал log пишет письма в File
+// (или в stderr, если файл не задан) и предназначен для локальной разработки. Уведомления отправляются в фоне
+// через очередь на QueueSize писем.
+type NotifySettings struct {
+	Driver    string `env:"NOTIFY_DRIVER" envDefault:"log"`
+	File      string `env:"NOTIFY_FILE"`
+	SMTP      SMTPSettings
+	QueueSize int `env:"NOTIFY_QUEUE_SIZE" envDefault:"100"`
+}
+
+type SMTPSettings struct {
+	Addr     string        `env:"SMTP_ADDR" envDefault:"localhost:1025"`
+	Username string        `env:"SMTP_USERNAME"`
+	Password string        `env:"SMTP_PASSWORD"`
+	From     string        `env:"SMTP_FROM" envDefault:"gophermart@localhost"`
+	Timeout  time.Duration `env:"SMTP_TIMEOUT" envDefault:"10s"`
+}
+
+// UserCacheSettings ограничивает кеш пользователей, проверяемых при авторизации. Нулевой TTL отключает кеш.
+// Сессии хранятся меньше: их сброс не доходит до других экземпляров сервиса.
+type UserCacheSettings struct {
+	TTL        time.Duration `env:"USER_CACHE_TTL" envDefault:"30s"`
+	SessionTTL time.Duration `env:"SESSION_CACHE_TTL" envDefault:"5s"`
+	Size       int           `env:"USER_CACHE_SIZE" envDefault:"10000"`
+}
+
+// OrderNumberSettings выбирает проверку номеров заказов. Pattern — регулярное выражение, которому должен
+// целиком соответствовать номер при проверке pattern.
+type OrderNumberSettings struct {
+	Validator string `env:"ORDER_NUMBER_VALIDATOR" envDefault:"luhn"`
+	Pattern   string `env:"ORDER_NUMBER_PATTERN"`
+}
+
+// OrderEventsSettings задает поток событий заказов: Heartbeat — интервал пустых сообщений, которые не дают
+// прокси закрыть простаивающее соединение, RetryDelay — пауза перед переподключением к каналу уведомлений БД,
+// SessionCheck — интервал проверки сессии открытого потока.
+type OrderEventsSettings struct {
+	Heartbeat    time.Duration `env:"ORDER_EVENTS_HEARTBEAT" envDefault:"15s"`
+	RetryDelay   time.Duration `env:"ORDER_EVENTS_RETRY_DELAY" envDefault:"5s"`
+	SessionCheck time.Duration `env:"ORDER_EVENTS_SESSION_CHECK" envDefault:"15s"`
+}
+
+// WebhookSettings задает доставку вебхуков. Неудачная попытка повторяется через BaseBackoff, и каждая следующая
+// пауза вдвое больше предыдущей, но не больше MaxBackoff. После MaxAttempts попыток доставка считается
+// неудачной. Успешные доставки удаляются из журнала через Retention. Адреса в локальных и частных сетях
+// запрещены, пока не включен AllowPrivate (например, для локальной разработки).
+type WebhookSettings struct {
+	DeliveryPeriod time.Duration `env:"WEBHOOK_DELIVERY_PERIOD" envDefault:"5s"`
+	Timeout        time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"5s"`
+	BaseBackoff    time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"30s"`
+	MaxBackoff     time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"1h"`
+	Retention      time.Duration `env:"WEBHOOK_RETENTION" envDefault:"720h"`
+	MaxAttempts    int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	BatchSize      int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"20"`
+	AllowPrivate   bool          `env:"WEBHOOK_ALLOW_PRIVATE" envDefault:"false"`
+}
+
+func (s WebhookSettings) valid() bool {
+	return s.DeliveryPeriod > 0 && s.Timeout > 0 && s.BaseBackoff > 0 && s.MaxBackoff > 0 && s.Retention > 0 &&
+		s.MaxAttempts > 0 && s.BatchSize > 0
 }
 
 type AccrualSettings struct {
@@ -31,6 +227,10 @@ func Setup() (*Settings, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &s, nil
 }
 
@@ -46,6 +246,7 @@ func (s *Settings) parseFlags() error {
 	flag.DurationVar(&s.Accrual.RequestTimeout, "t", s.Accrual.RequestTimeout, "request timeout for accrual")
 	flag.StringVar(&s.DatabaseURI, "d", s.DatabaseURI, "database URI")
 	flag.StringVar(&s.SecretKey, "s", s.SecretKey, "secret key for generate auth token")
+	flag.BoolVar(&s.DevMode, "dev", s.DevMode, "enable dev mode")
 	flag.DurationVar(&s.ProcessOrderAccrualPeriod, "p", s.ProcessOrderAccrualPeriod, "process order accrual period")
 	flag.IntVar(&s.ProcessOrderAccrualWorkers, "w", s.ProcessOrderAccrualWorkers, "process order accrual workers")
 	flag.Func("l", `level for logger (default "ERROR")`, func(v string) error {
@@ -63,3 +264,79 @@ func (s *Settings) parseFlags() error {
 
 	return nil
 }
+
+// CheckSecretKey отклоняет секрет HS256 по умолчанию вне режима разработки. Ключи из AUTH_KEYS и AUTH_KEYS_FILE
+// проверяются при загрузке набора ключей.
+func (s *Settings) CheckSecretKey(secret string) error {
+	if s.DevMode {
+		return nil
+	}
+
+	if secret == defaultSecretKey {
+		return ErrDefaultSecretKey
+	}
+
+	return nil
+}
+
+func (s *Settings) validate() error {
+	if s.Auth.KeysFile == "" && s.Auth.Keys == "" {
+		if err := s.CheckSecretKey(s.SecretKey); err != nil {
+			return err
+		}
+	}
+
+	if s.Credentials.LoginMaxLength < 1 || s.Credentials.LoginMaxLength > MaxLoginLength {
+		return ErrLoginMaxLength
+	}
+
+	switch s.PasswordHash.Algorithm {
+	case PasswordHashArgon2id, PasswordHashBcrypt:
+	default:
+		return ErrPasswordHashAlgo
+	}
+
+	switch s.OrderNumber.Validator {
+	case OrderNumberLuhn, OrderNumberISO7812:
+	case OrderNumberPattern:
+		if _, err := regexp.Compile(s.OrderNumber.Pattern); err != nil || s.OrderNumber.Pattern == "" {
+			return ErrOrderNumberPattern
+		}
+	default:
+		return ErrOrderNumberCheck
+	}
+
+	if s.ProcessOrderAccrualPeriod <= 0 || s.CleanupPeriod <= 0 || s.Idempotency.LockTimeout <= 0 {
+		return ErrInvalidPeriod
+	}
+
+	if s.OrderEvents.Heartbeat <= 0 || s.OrderEvents.RetryDelay <= 0 || s.OrderEvents.SessionCheck <= 0 {
+		return ErrOrderEventsPeriod
+	}
+
+	if !s.Webhooks.valid() {
+		return ErrWebhookSettings
+	}
+
+	switch s.Notify.Driver {
+	case NotifyDriverLog, NotifyDriverSMTP:
+	default:
+		return ErrNotifyDriver
+	}
+
+	if s.Notify.QueueSize <= 0 {
+		return ErrNotifyQueueSize
+	}
+
+	switch s.Auth.Cookie.SameSite {
+	case SameSiteLax, SameSiteStrict:
+	case SameSiteNone:
+		if !s.Auth.Cookie.Secure {
+			return ErrCookieInsecureNone
+		}
+	default:
+		return ErrCookieSameSite
+	}
+
+	return nil
+}
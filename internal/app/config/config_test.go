@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePeriods(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		modify  func(s *Settings)
+		name    string
+	}{
+		{name: "defaults", modify: func(*Settings) {}},
+		{
+			name:    "zero accrual period",
+			modify:  func(s *Settings) { s.ProcessOrderAccrualPeriod = 0 },
+			wantErr: ErrInvalidPeriod,
+		},
+		{
+			name:    "negative cleanup period",
+			modify:  func(s *Settings) { s.CleanupPeriod = -time.Minute },
+			wantErr: ErrInvalidPeriod,
+		},
+		{
+			name:    "zero idempotency lock timeout",
+			modify:  func(s *Settings) { s.Idempotency.LockTimeout = 0 },
+			wantErr: ErrInvalidPeriod,
+		},
+		{
+			name:    "zero webhook delivery period",
+			modify:  func(s *Settings) { s.Webhooks.DeliveryPeriod = 0 },
+			wantErr: ErrWebhookSettings,
+		},
+		{
+			name:    "zero order events session check",
+			modify:  func(s *Settings) { s.OrderEvents.SessionCheck = 0 },
+			wantErr: ErrOrderEventsPeriod,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Settings{}
+			require.NoError(t, env.ParseWithOptions(&s, env.Options{Environment: map[string]string{}}))
+			s.DevMode = true
+			test.modify(&s)
+
+			err := s.validate()
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
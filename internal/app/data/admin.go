@@ -0,0 +1,205 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// FindUsersByLogin ищет действующих пользователей, логин которых начинается с переданной строки.
+func (s *DBStorage) FindUsersByLogin(ctx context.Context, login string, limit int) ([]models.User, error) {
+	const query = `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE login LIKE $1 AND deleted_at IS NULL
+		ORDER BY login ASC
+		LIMIT $2
+	`
+
+	users := []models.User{}
+
+	rows, err := s.pool.Query(ctx, query, likeEscaper.Replace(login)+"%", limit)
+	if err != nil {
+		return []models.User{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return []models.User{}, err
+		}
+
+		users = append(users, u)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return []models.User{}, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	return users, nil
+}
+
+// AdjustBalance начисляет (положительная сумма) или списывает (отрицательная) баллы пользователя.
+// Запись журнала баллов и запись аудита с ID администратора из контекста сохраняются в одной транзакции.
+func (s *DBStorage) AdjustBalance(ctx context.Context,
+	userID int, amount models.Points, reason string) (models.BalanceAdjustment, error) {
+	const getBalanceQuery = `
+		SELECT b.current
+		FROM balance b
+		JOIN users u ON u.id = b.user_id
+		WHERE b.user_id = $1 AND u.deleted_at IS NULL
+		FOR UPDATE OF b
+	`
+	const addAdjustmentQuery = `
+		INSERT INTO balance_adjustments (admin_id, user_id, ledger_id, amount, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	adminID, _ := ctx.Value(common.KeyUserID).(int)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return models.BalanceAdjustment{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	var current models.Points
+	if err := tx.QueryRow(ctx, getBalanceQuery, userID).Scan(&current); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BalanceAdjustment{}, fmt.Errorf("%w with ID: %d", ErrUserNotFound, userID)
+		}
+		return models.BalanceAdjustment{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	if current+amount < 0 {
+		return models.BalanceAdjustment{}, ErrUserInsufficientFunds
+	}
+
+	entry := models.LedgerEntry{
+		UserID: userID,
+		Kind:   models.LedgerKindAdjustment,
+		Amount: amount,
+		Reason: reason,
+	}
+
+	if err := appendLedgerEntry(ctx, tx, &entry, 0); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.CheckViolation {
+			return models.BalanceAdjustment{}, ErrUserInsufficientFunds
+		}
+		return models.BalanceAdjustment{}, fmt.Errorf("failed to append adjustment to ledger: %w", err)
+	}
+
+	adj := models.BalanceAdjustment{
+		UserID:       userID,
+		AdminID:      adminID,
+		LedgerID:     entry.ID,
+		Amount:       amount,
+		BalanceAfter: entry.BalanceAfter,
+		Reason:       reason,
+	}
+
+	row := tx.QueryRow(ctx, addAdjustmentQuery, adminID, userID, entry.ID, amount, reason)
+	if err := row.Scan(&adj.ID, &adj.CreatedAt); err != nil {
+		return models.BalanceAdjustment{}, fmt.Errorf("failed to add balance adjustment: %w", err)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return models.BalanceAdjustment{}, fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return adj, nil
+}
+
+// OverrideOrder устанавливает заказу статус и начисление, переданные администратором, и приводит баланс
+// пользователя в соответствие с новым статусом. Изменение заказа, запись журнала баллов и запись аудита
+// с ID администратора из контекста сохраняются в одной транзакции.
+func (s *DBStorage) OverrideOrder(ctx context.Context, o models.OrderOverride) (models.OrderOverride, error) {
+	const getOrderQuery = `SELECT user_id, status, accrual, uploaded_at FROM orders WHERE number = $1 FOR UPDATE`
+	const updateOrderQuery = `UPDATE orders SET (status, accrual) = ($2, $3) WHERE number = $1`
+	const addOverrideQuery = `
+		INSERT INTO order_overrides (admin_id, user_id, order_number, action, old_status, new_status,
+			old_accrual, new_accrual, balance_delta, ledger_id, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10::bigint, 0), $11)
+		RETURNING id, created_at
+	`
+
+	o.AdminID, _ = ctx.Value(common.KeyUserID).(int)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return models.OrderOverride{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	var uploadedAt time.Time
+
+	row := tx.QueryRow(ctx, getOrderQuery, o.OrderNumber)
+	if err := row.Scan(&o.UserID, &o.OldStatus, &o.OldAccrual, &uploadedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OrderOverride{}, fmt.Errorf("%w with number: %s", ErrOrderNotFound, o.OrderNumber)
+		}
+		return models.OrderOverride{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	if _, err := tx.Exec(ctx, updateOrderQuery, o.OrderNumber, o.NewStatus, o.NewAccrual); err != nil {
+		return models.OrderOverride{}, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	if o.OldStatus != o.NewStatus || o.OldAccrual != o.NewAccrual {
+		if err := addOrderStatusEvent(ctx, tx, o.OrderNumber, o.NewStatus, o.NewAccrual); err != nil {
+			return models.OrderOverride{}, err
+		}
+
+		if err := notifyOrderUpdate(ctx, tx, o.UserID, o.OrderNumber, o.NewStatus, o.NewAccrual); err != nil {
+			return models.OrderOverride{}, err
+		}
+	}
+
+	if o.NewStatus == "PROCESSED" && o.OldStatus != "PROCESSED" {
+		order := models.Order{Number: o.OrderNumber, Status: o.NewStatus, Accrual: o.NewAccrual, UploadedAt: uploadedAt}
+		if err := enqueueWebhookEvent(ctx, tx, o.UserID, models.WebhookOrderProcessed, order); err != nil {
+			return models.OrderOverride{}, err
+		}
+	}
+
+	entry, err := settleOrderAccrual(ctx, tx,
+		o.UserID, o.OrderNumber, orderCredit(o.NewStatus, o.NewAccrual), o.Reason)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.CheckViolation {
+			return models.OrderOverride{}, ErrUserInsufficientFunds
+		}
+		return models.OrderOverride{}, fmt.Errorf("failed to settle order accrual: %w", err)
+	}
+
+	o.LedgerID = entry.ID
+	o.BalanceDelta = entry.Amount
+
+	row = tx.QueryRow(ctx, addOverrideQuery, o.AdminID, o.UserID, o.OrderNumber, o.Action, o.OldStatus,
+		o.NewStatus, o.OldAccrual, o.NewAccrual, o.BalanceDelta, o.LedgerID, o.Reason)
+	if err := row.Scan(&o.ID, &o.CreatedAt); err != nil {
+		return models.OrderOverride{}, fmt.Errorf("failed to add order override: %w", err)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return models.OrderOverride{}, fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return o, nil
+}
@@ -0,0 +1,144 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustBalance(t *testing.T) {
+	s := newTestStorage(t)
+
+	userCtx := newTestUser(t, s, models.Points(10000))
+	userID, ok := userCtx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	adminCtx := newTestUser(t, s, 0)
+	adminID, ok := adminCtx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	adj, err := s.AdjustBalance(adminCtx, userID, 500, "goodwill")
+	require.NoError(t, err)
+	assert.Equal(t, adminID, adj.AdminID)
+	assert.Equal(t, models.Points(10500), adj.BalanceAfter)
+
+	_, err = s.AdjustBalance(adminCtx, userID, -20000, "correction")
+	assert.ErrorIs(t, err, ErrUserInsufficientFunds)
+
+	_, err = s.AdjustBalance(adminCtx, userID, -10500, "correction")
+	require.NoError(t, err)
+
+	balance, err := s.GetBalance(userCtx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Balance{}, balance)
+
+	entries, err := s.GetLedgerEntries(userCtx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, models.LedgerKindAdjustment, entries[1].Kind)
+	assert.Equal(t, "goodwill", entries[1].Reason)
+	assert.Equal(t, adj.LedgerID, entries[1].ID)
+
+	require.NoError(t, s.AnonymizeUser(userCtx))
+
+	_, err = s.AdjustBalance(adminCtx, userID, 500, "goodwill")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestFindUsersByLogin(t *testing.T) {
+	s := newTestStorage(t)
+
+	ctx := context.Background()
+	prefix := fmt.Sprintf("find_%d", time.Now().UnixNano())
+
+	for _, login := range []string{prefix + "_a", prefix + "_b", prefix + "xa"} {
+		_, err := s.AddUser(ctx, login, "", []byte("hash"))
+		require.NoError(t, err)
+	}
+
+	users, err := s.FindUsersByLogin(ctx, prefix+"_", 10)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, prefix+"_a", users[0].Login)
+	assert.Equal(t, prefix+"_b", users[1].Login)
+
+	users, err = s.FindUsersByLogin(ctx, prefix, 1)
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+}
+
+func TestOverrideOrder(t *testing.T) {
+	s := newTestStorage(t)
+
+	userCtx := newTestUser(t, s, models.Points(10000))
+	adminCtx := newTestUser(t, s, 0)
+
+	orders, err := s.GetOrdersByUserID(userCtx, models.OrderQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	number := orders[0].Number
+
+	assertBalance := func(t *testing.T, want models.Points) {
+		t.Helper()
+
+		balance, err := s.GetBalance(userCtx)
+		require.NoError(t, err)
+		assert.Equal(t, want, balance.Current)
+	}
+
+	o, err := s.OverrideOrder(adminCtx, models.OrderOverride{
+		OrderNumber: number, Action: models.OrderActionSetStatus, NewStatus: "INVALID", Reason: "fraud",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "PROCESSED", o.OldStatus)
+	assert.Equal(t, models.Points(-10000), o.BalanceDelta)
+	assertBalance(t, 0)
+
+	o, err = s.OverrideOrder(adminCtx, models.OrderOverride{
+		OrderNumber: number, Action: models.OrderActionReset, NewStatus: "NEW", Reason: "recheck",
+	})
+	require.NoError(t, err)
+	assert.Zero(t, o.LedgerID)
+
+	updated, err := s.UpdateOrder(context.Background(), number, "PROCESSED", 8000)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assertBalance(t, 8000)
+
+	updated, err = s.UpdateOrder(context.Background(), number, "PROCESSED", 1)
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assertBalance(t, 8000)
+
+	_, err = s.OverrideOrder(adminCtx, models.OrderOverride{
+		OrderNumber: number, Action: models.OrderActionSetStatus, NewStatus: "PROCESSED", NewAccrual: 9000,
+		Reason: "partner confirmed",
+	})
+	require.NoError(t, err)
+	assertBalance(t, 9000)
+
+	require.NoError(t, s.AddWithdraw(userCtx, fmt.Sprintf("w-%s", number), 9000, 0))
+
+	_, err = s.OverrideOrder(adminCtx, models.OrderOverride{
+		OrderNumber: number, Action: models.OrderActionReset, NewStatus: "NEW", Reason: "recheck",
+	})
+	assert.ErrorIs(t, err, ErrUserInsufficientFunds)
+
+	entries, err := s.GetLedgerEntries(userCtx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	assert.Equal(t, models.LedgerKindReversal, entries[1].Kind)
+	assert.Equal(t, entries[0].ID, entries[1].ReversesID)
+	assert.Equal(t, models.LedgerKindAdjustment, entries[2].Kind)
+
+	_, err = s.OverrideOrder(adminCtx, models.OrderOverride{
+		OrderNumber: "missing", Action: models.OrderActionReset, NewStatus: "NEW", Reason: "recheck",
+	})
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
@@ -0,0 +1,62 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+func (s *DBStorage) AddAuditEvent(ctx context.Context, e models.AuditEvent) error {
+	const query = `
+		INSERT INTO audit_events (actor_id, user_id, action, target_type, target_id, request_id, ip, before, after)
+		VALUES (NULLIF($1::int, 0), NULLIF($2::int, 0), $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+	`
+
+	_, err := s.pool.Exec(ctx, query, e.ActorID, e.UserID, e.Action, e.TargetType, e.TargetID,
+		e.RequestID, e.IP, e.Before, e.After)
+	if err != nil {
+		return fmt.Errorf("failed to add audit event: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditEvents возвращает события за период [From, To), которые выполнил пользователь или которые его затронули.
+// Нулевой UserID выбирает события всех пользователей.
+func (s *DBStorage) GetAuditEvents(ctx context.Context, q models.AuditQuery) ([]models.AuditEvent, error) {
+	const query = `
+		SELECT id, COALESCE(actor_id, 0), COALESCE(user_id, 0), action, target_type, target_id,
+			COALESCE(request_id, ''), COALESCE(ip, ''), before, after, created_at
+		FROM audit_events
+		WHERE ($1::int = 0 OR user_id = $1 OR actor_id = $1) AND created_at >= $2 AND created_at < $3 AND id > $4
+		ORDER BY id ASC
+		LIMIT $5
+	`
+
+	events := []models.AuditEvent{}
+
+	rows, err := s.pool.Query(ctx, query, q.UserID, q.From, q.To, q.AfterID, q.Limit)
+	if err != nil {
+		return []models.AuditEvent{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.AuditEvent
+		err = rows.Scan(&e.ID, &e.ActorID, &e.UserID, &e.Action, &e.TargetType, &e.TargetID,
+			&e.RequestID, &e.IP, &e.Before, &e.After, &e.CreatedAt)
+		if err != nil {
+			return []models.AuditEvent{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		events = append(events, e)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return []models.AuditEvent{}, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	return events, nil
+}
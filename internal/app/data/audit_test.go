@@ -0,0 +1,59 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEvents(t *testing.T) {
+	s := newTestStorage(t)
+
+	userCtx := newTestUser(t, s, 0)
+	userID, ok := userCtx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	start := time.Now().Add(-time.Minute)
+
+	require.NoError(t, s.AddAuditEvent(context.Background(), models.AuditEvent{
+		Action:     models.AuditLoginFailed,
+		TargetType: models.AuditTargetUser,
+		UserID:     userID,
+		After:      map[string]any{"reason": "wrong_password"},
+	}))
+	require.NoError(t, s.AddAuditEvent(context.Background(), models.AuditEvent{
+		ActorID:    userID,
+		UserID:     userID,
+		Action:     models.AuditPasswordChanged,
+		TargetType: models.AuditTargetUser,
+		RequestID:  "req-1",
+		IP:         "192.0.2.1",
+	}))
+
+	q := models.AuditQuery{UserID: userID, From: start, To: time.Now().Add(time.Minute), Limit: 10}
+
+	events, err := s.GetAuditEvents(context.Background(), q)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Zero(t, events[0].ActorID)
+	assert.Equal(t, map[string]any{"reason": "wrong_password"}, events[0].After)
+	assert.Equal(t, "req-1", events[1].RequestID)
+	assert.Nil(t, events[1].Before)
+
+	q.AfterID = events[0].ID
+	events, err = s.GetAuditEvents(context.Background(), q)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.AuditPasswordChanged, events[0].Action)
+
+	q.AfterID = 0
+	q.To = start
+	events, err = s.GetAuditEvents(context.Background(), q)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
@@ -5,13 +5,18 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/common"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -24,6 +29,9 @@ type DBStorage struct {
 var (
 	ErrUserNotFound          = errors.New("user not found")
 	ErrUserInsufficientFunds = errors.New("user insufficient funds")
+	ErrAdminExists           = errors.New("admin already exists")
+	ErrOrderNotFound         = errors.New("order not found")
+	ErrWebhookNotFound       = errors.New("webhook not found")
 )
 
 const failedScanStr = "failed to scan a response row: %w"
@@ -47,43 +55,39 @@ func NewDBStorage(ctx context.Context, logger *zap.Logger, dbURI string) (*DBSto
 }
 
 func (s *DBStorage) GetUserByID(ctx context.Context, userID int) (models.User, error) {
-	const query = `SELECT id, login, password FROM users WHERE id = $1 LIMIT 1`
+	const query = `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL LIMIT 1`
 
-	row := s.pool.QueryRow(ctx, query, userID)
-
-	var u models.User
-	err := row.Scan(&u.ID, &u.Login, &u.Password)
+	u, err := scanUser(s.pool.QueryRow(ctx, query, userID))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrUserNotFound) {
 			return models.User{}, fmt.Errorf("%w with ID: %d", ErrUserNotFound, userID)
 		}
 
-		return models.User{}, fmt.Errorf(failedScanStr, err)
+		return models.User{}, err
 	}
 
 	return u, nil
 }
 
 func (s *DBStorage) GetUserByLogin(ctx context.Context, userLogin string) (models.User, error) {
-	const query = `SELECT id, login, password FROM users WHERE login = $1 LIMIT 1`
-
-	row := s.pool.QueryRow(ctx, query, userLogin)
+	const query = `SELECT ` + userColumns + ` FROM users WHERE login = $1 AND deleted_at IS NULL LIMIT 1`
 
-	var u models.User
-	err := row.Scan(&u.ID, &u.Login, &u.Password)
+	u, err := scanUser(s.pool.QueryRow(ctx, query, userLogin))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrUserNotFound) {
 			return models.User{}, fmt.Errorf("%w with ID: %s", ErrUserNotFound, userLogin)
 		}
 
-		return models.User{}, fmt.Errorf(failedScanStr, err)
+		return models.User{}, err
 	}
 
 	return u, nil
 }
 
-func (s *DBStorage) AddUser(ctx context.Context, userLogin string, userPassword []byte) (models.User, error) {
-	const addUserQuery = `INSERT INTO users (login, password) VALUES ($1, $2) RETURNING id, login, password`
+func (s *DBStorage) AddUser(ctx context.Context,
+	userLogin string, userEmail string, userPassword []byte) (models.User, error) {
+	const addUserQuery = `INSERT INTO users (login, email, password) VALUES ($1, NULLIF($2, ''), $3) RETURNING ` +
+		userColumns
 	const addBalanceQuery = `INSERT INTO balance (user_id) VALUES ($1)`
 
 	tx, err := s.pool.Begin(ctx)
@@ -92,10 +96,9 @@ func (s *DBStorage) AddUser(ctx context.Context, userLogin string, userPassword
 	}
 	defer rollbackTx(ctx, tx, s.logger)
 
-	row := tx.QueryRow(ctx, addUserQuery, userLogin, userPassword)
-	var u models.User
-	if err := row.Scan(&u.ID, &u.Login, &u.Password); err != nil {
-		return models.User{}, fmt.Errorf(failedScanStr, err)
+	u, err := scanUser(tx.QueryRow(ctx, addUserQuery, userLogin, userEmail, userPassword))
+	if err != nil {
+		return models.User{}, err
 	}
 
 	if _, err := tx.Exec(ctx, addBalanceQuery, u.ID); err != nil {
@@ -110,17 +113,45 @@ func (s *DBStorage) AddUser(ctx context.Context, userLogin string, userPassword
 	return u, nil
 }
 
-func (s *DBStorage) GetOrdersByUserID(ctx context.Context) ([]models.Order, error) {
-	const query = `
-		SELECT number, status, accrual, uploaded_at, user_id
-		FROM orders
-		WHERE user_id = $1
-		ORDER BY uploaded_at ASC
-	`
+// GetOrdersByUserID возвращает страницу заказов текущего пользователя. Пустые границы периода и статус
+// не ограничивают выборку.
+func (s *DBStorage) GetOrdersByUserID(ctx context.Context, q models.OrderQuery) ([]models.Order, error) {
+	const (
+		ascQuery = `
+			SELECT number, status, accrual, uploaded_at, user_id
+			FROM orders
+			WHERE user_id = $1 AND ($2 = '' OR status::text = $2)
+				AND ($3::timestamptz IS NULL OR uploaded_at >= $3) AND ($4::timestamptz IS NULL OR uploaded_at < $4)
+				AND ($5::timestamptz IS NULL OR (uploaded_at, number) > ($5, $6))
+			ORDER BY uploaded_at ASC, number ASC
+			LIMIT $7
+		`
+		descQuery = `
+			SELECT number, status, accrual, uploaded_at, user_id
+			FROM orders
+			WHERE user_id = $1 AND ($2 = '' OR status::text = $2)
+				AND ($3::timestamptz IS NULL OR uploaded_at >= $3) AND ($4::timestamptz IS NULL OR uploaded_at < $4)
+				AND ($5::timestamptz IS NULL OR (uploaded_at, number) < ($5, $6))
+			ORDER BY uploaded_at DESC, number DESC
+			LIMIT $7
+		`
+	)
+
+	query := ascQuery
+	if q.Desc {
+		query = descQuery
+	}
+
+	// LIMIT NULL возвращает все строки.
+	var limit any
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
 
 	orders := []models.Order{}
 
-	rows, err := s.pool.Query(ctx, query, ctx.Value(common.KeyUserID))
+	rows, err := s.pool.Query(ctx, query, ctx.Value(common.KeyUserID), q.Status,
+		nullTime(q.From), nullTime(q.To), nullTime(q.AfterUploadedAt), q.AfterNumber, limit)
 	if err != nil {
 		return []models.Order{}, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -138,12 +169,21 @@ func (s *DBStorage) GetOrdersByUserID(ctx context.Context) ([]models.Order, erro
 
 	rowsErr := rows.Err()
 	if rowsErr != nil {
-		return []models.Order{}, fmt.Errorf("failed to read query: %w", err)
+		return []models.Order{}, fmt.Errorf("failed to read query: %w", rowsErr)
 	}
 
 	return orders, nil
 }
 
+// nullTime передает нулевое время в запрос как NULL.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
 func (s *DBStorage) GetOrdersByStatus(ctx context.Context, statuses ...string) ([]models.Order, error) {
 	const query = `SELECT number, status, accrual, uploaded_at, user_id FROM orders WHERE status = any($1)`
 
@@ -179,6 +219,9 @@ func (s *DBStorage) AddOrder(ctx context.Context, number string) (models.Order,
 			INSERT INTO orders (number, user_id) VALUES ($1, $2)
 			ON CONFLICT (number) DO NOTHING
 			RETURNING *
+		), new_event AS (
+			INSERT INTO order_status_events (order_number, status, created_at)
+			SELECT number, status, uploaded_at FROM new_order
 		)
 		SELECT number, status, accrual, uploaded_at, user_id, true as is_new FROM new_order
 		UNION
@@ -197,34 +240,154 @@ func (s *DBStorage) AddOrder(ctx context.Context, number string) (models.Order,
 	return o, isNewOrder, nil
 }
 
-func (s *DBStorage) UpdateOrder(ctx context.Context, number string, status string, accrual float32) error {
-	const updateQuery = `UPDATE orders SET (status, accrual) = ($2, $3) WHERE number = $1 RETURNING user_id`
-	const updateBalanceQuery = `UPDATE balance SET current = current + $1 WHERE user_id = $2`
+// AddOrders добавляет текущему пользователю заказы одной транзакцией и возвращает все переданные заказы
+// с их владельцами. Номера, которые уже загружены, не меняются.
+func (s *DBStorage) AddOrders(ctx context.Context, numbers []string) ([]models.BatchOrder, error) {
+	const selectQuery = `SELECT number, status, accrual, uploaded_at, user_id FROM orders WHERE number = any($1)`
 
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	created, err := addNewOrders(ctx, tx, numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Отдельный запрос видит и заказы, которые параллельно загрузили другие транзакции.
+	rows, err := tx.Query(ctx, selectQuery, numbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]models.BatchOrder, 0, len(numbers))
+
+	for rows.Next() {
+		var o models.BatchOrder
+		if err := rows.Scan(&o.Number, &o.Status, &o.Accrual, &o.UploadedAt, &o.UserID); err != nil {
+			return nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		o.Created = created[o.Number]
+		orders = append(orders, o)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return nil, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return orders, nil
+}
+
+// addNewOrders вставляет незагруженные заказы и возвращает их номера. Номера вставляются по возрастанию и без
+// повторов: параллельные пачки с пересекающимися номерами блокируют строки в одном порядке и не взаимоблокируются.
+func addNewOrders(ctx context.Context, tx pgx.Tx, numbers []string) (map[string]bool, error) {
+	const query = `
+		WITH new_orders AS (
+			INSERT INTO orders (number, user_id) SELECT unnest($1::varchar[]), $2
+			ON CONFLICT (number) DO NOTHING
+			RETURNING number, status, uploaded_at
+		), new_events AS (
+			INSERT INTO order_status_events (order_number, status, created_at)
+			SELECT number, status, uploaded_at FROM new_orders
+		)
+		SELECT number FROM new_orders
+	`
+
+	sorted := slices.Clone(numbers)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	rows, err := tx.Query(ctx, query, sorted, ctx.Value(common.KeyUserID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add orders: %w", err)
+	}
+	defer rows.Close()
+
+	created := make(map[string]bool, len(numbers))
+
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		created[number] = true
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return nil, fmt.Errorf("failed to read added orders: %w", rowsErr)
+	}
+
+	return created, nil
+}
+
+// UpdateOrder сохраняет результат проверки заказа в системе начислений. Обновляются только заказы в статусах
+// NEW и PROCESSING: если администратор уже установил итоговый статус вручную, результат проверки отбрасывается
+// и возвращается false. В историю заказа попадает только смена статуса.
+func (s *DBStorage) UpdateOrder(ctx context.Context,
+	number string, status string, accrual models.Points) (bool, error) {
+	const updateQuery = `
+		UPDATE orders o SET (status, accrual) = ($2, $3)
+		FROM (SELECT number, status FROM orders WHERE number = $1 FOR UPDATE) old
+		WHERE o.number = old.number AND old.status IN ('NEW', 'PROCESSING')
+		RETURNING o.user_id, old.status, o.uploaded_at
+	`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer rollbackTx(ctx, tx, s.logger)
 
 	row := tx.QueryRow(ctx, updateQuery, number, status, accrual)
 	var userID int
-	if err := row.Scan(&userID); err != nil {
-		return fmt.Errorf("failed to update order: %w", err)
+	var oldStatus string
+	var uploadedAt time.Time
+	if err := row.Scan(&userID, &oldStatus, &uploadedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update order: %w", err)
 	}
 
-	if status == "PROCESSED" {
-		if _, err := tx.Exec(ctx, updateBalanceQuery, accrual, userID); err != nil {
-			return fmt.Errorf("failed to update balance: %w", err)
+	if oldStatus != status {
+		if err := addOrderStatusEvent(ctx, tx, number, status, accrual); err != nil {
+			return false, err
+		}
+
+		if err := notifyOrderUpdate(ctx, tx, userID, number, status, accrual); err != nil {
+			return false, err
+		}
+
+		if status == "PROCESSED" {
+			order := models.Order{Number: number, Status: status, Accrual: accrual, UploadedAt: uploadedAt}
+			if err := enqueueWebhookEvent(ctx, tx, userID, models.WebhookOrderProcessed, order); err != nil {
+				return false, err
+			}
 		}
 	}
 
+	if _, err := settleOrderAccrual(ctx, tx, userID, number, orderCredit(status, accrual), ""); err != nil {
+		return false, fmt.Errorf("failed to append accrual to ledger: %w", err)
+	}
+
 	cErr := tx.Commit(ctx)
 	if cErr != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return false, fmt.Errorf("failed to commit transaction: %w", cErr)
 	}
 
-	return nil
+	return true, nil
 }
 
 func (s *DBStorage) GetWithdrawals(ctx context.Context) ([]models.Withdraw, error) {
@@ -261,16 +424,15 @@ func (s *DBStorage) GetWithdrawals(ctx context.Context) ([]models.Withdraw, erro
 	return withdrawals, nil
 }
 
-func (s *DBStorage) AddWithdraw(ctx context.Context, orderNumber string, sum float32) error {
-	const getBalanceQuery = `SELECT current FROM balance WHERE user_id = $1 LIMIT 1`
-	const addQuery = `
-		INSERT INTO withdrawals (order_number, sum, user_id) VALUES ($1, $2, $3) 
-		RETURNING order_number, sum, processed_at
-	`
-	const updateBalanceQuery = `
-		UPDATE balance SET (current, withdrawn) = (current - $1, withdrawn + $1) 
-		WHERE user_id = $2
-	`
+// AddWithdraw списывает баллы. Ненулевой totpStep — интервал кода второго фактора: он расходуется в той же
+// транзакции, поэтому при отказе в списании код остается неиспользованным. Принятый код сбрасывает счетчик
+// неверных кодов.
+func (s *DBStorage) AddWithdraw(ctx context.Context, orderNumber string, sum models.Points, totpStep int64) error {
+	const getBalanceQuery = `SELECT current FROM balance WHERE user_id = $1 LIMIT 1 FOR UPDATE`
+	const addQuery = `INSERT INTO withdrawals (order_number, sum, user_id) VALUES ($1, $2, $3) RETURNING processed_at`
+	const resetTOTPFailuresQuery = `DELETE FROM login_throttles WHERE scope = 'TOTP' AND subject = $1`
+
+	userID, _ := ctx.Value(common.KeyUserID).(int)
 
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -278,9 +440,19 @@ func (s *DBStorage) AddWithdraw(ctx context.Context, orderNumber string, sum flo
 	}
 	defer rollbackTx(ctx, tx, s.logger)
 
-	row := tx.QueryRow(ctx, getBalanceQuery, ctx.Value(common.KeyUserID))
+	if totpStep != 0 {
+		if err := useTOTPStep(ctx, tx, userID, totpStep); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, resetTOTPFailuresQuery, strconv.Itoa(userID)); err != nil {
+			return fmt.Errorf("failed to reset TOTP failures: %w", err)
+		}
+	}
+
+	row := tx.QueryRow(ctx, getBalanceQuery, userID)
 
-	var current float32
+	var current models.Points
 	if err := row.Scan(&current); err != nil {
 		return fmt.Errorf(failedScanStr, err)
 	}
@@ -289,17 +461,33 @@ func (s *DBStorage) AddWithdraw(ctx context.Context, orderNumber string, sum flo
 		return ErrUserInsufficientFunds
 	}
 
-	if _, err := tx.Exec(ctx, addQuery, orderNumber, sum, ctx.Value(common.KeyUserID)); err != nil {
+	w := models.Withdraw{OrderNumber: orderNumber, Sum: sum}
+	if err := tx.QueryRow(ctx, addQuery, orderNumber, sum, userID).Scan(&w.ProcessedAt); err != nil {
 		return fmt.Errorf("failed to add withdrawn: %w", err)
 	}
 
-	if _, err := tx.Exec(ctx, updateBalanceQuery, sum, ctx.Value(common.KeyUserID)); err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+	entry := models.LedgerEntry{
+		UserID:      userID,
+		Kind:        models.LedgerKindWithdrawal,
+		Amount:      -sum,
+		OrderNumber: orderNumber,
+	}
+
+	if err := appendLedgerEntry(ctx, tx, &entry, sum); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.CheckViolation {
+			return ErrUserInsufficientFunds
+		}
+		return fmt.Errorf("failed to append withdrawal to ledger: %w", err)
+	}
+
+	if err := enqueueWebhookEvent(ctx, tx, userID, models.WebhookPointsWithdrawn, w); err != nil {
+		return err
 	}
 
 	cErr := tx.Commit(ctx)
 	if cErr != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", cErr)
 	}
 
 	return nil
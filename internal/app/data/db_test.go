@@ -0,0 +1,187 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestStorage(t *testing.T) *DBStorage {
+	t.Helper()
+
+	dbURI := os.Getenv("TEST_DATABASE_URI")
+	if dbURI == "" {
+		t.Skip("TEST_DATABASE_URI is not set")
+	}
+
+	s, err := NewDBStorage(context.Background(), zap.NewNop(), dbURI)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+
+	return s
+}
+
+func newTestUser(t *testing.T, s *DBStorage, balance models.Points) context.Context {
+	t.Helper()
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+
+	u, err := s.AddUser(ctx, fmt.Sprintf("test-user-%d", suffix), "", []byte("hash"))
+	require.NoError(t, err)
+
+	userCtx := context.WithValue(ctx, common.KeyUserID, u.ID)
+
+	orderNumber := strconv.FormatInt(suffix, 10)
+	_, _, err = s.AddOrder(userCtx, orderNumber)
+	require.NoError(t, err)
+
+	_, err = s.UpdateOrder(ctx, orderNumber, "PROCESSED", balance)
+	require.NoError(t, err)
+
+	return userCtx
+}
+
+func TestConcurrentAddWithdraw(t *testing.T) {
+	s := newTestStorage(t)
+
+	const (
+		workers = 50
+		balance = models.Points(10000)
+		sum     = models.Points(1000)
+	)
+
+	ctx := newTestUser(t, s, balance)
+	prefix := time.Now().UnixNano()
+
+	var (
+		wg           sync.WaitGroup
+		succeeded    atomic.Int32
+		insufficient atomic.Int32
+	)
+
+	start := make(chan struct{})
+	errs := make(chan error, workers)
+
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			err := s.AddWithdraw(ctx, fmt.Sprintf("%d%02d", prefix, i), sum, 0)
+			switch {
+			case err == nil:
+				succeeded.Add(1)
+			case errors.Is(err, ErrUserInsufficientFunds):
+				insufficient.Add(1)
+			default:
+				errs <- err
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(balance/sum), succeeded.Load())
+	assert.Equal(t, int32(workers)-int32(balance/sum), insufficient.Load())
+
+	b, err := s.GetBalance(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Balance{Current: 0, Withdrawn: balance}, b)
+
+	entries, err := s.GetLedgerEntries(ctx, 0, workers+1)
+	require.NoError(t, err)
+
+	var total models.Points
+	for _, e := range entries {
+		total += e.Amount
+		assert.Equal(t, total, e.BalanceAfter)
+		assert.GreaterOrEqual(t, e.BalanceAfter, models.Points(0))
+	}
+	assert.Equal(t, b.Current, total)
+}
+
+func TestGetOrdersByUserIDPagination(t *testing.T) {
+	s := newTestStorage(t)
+
+	ctx := newTestUser(t, s, models.Points(100))
+	suffix := time.Now().UnixNano()
+
+	for i := 1; i <= 3; i++ {
+		_, _, err := s.AddOrder(ctx, fmt.Sprintf("%d%d", suffix, i))
+		require.NoError(t, err)
+	}
+
+	all, err := s.GetOrdersByUserID(ctx, models.OrderQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+
+	unlimited, err := s.GetOrdersByUserID(ctx, models.OrderQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, all, unlimited)
+
+	page, err := s.GetOrdersByUserID(ctx, models.OrderQuery{
+		AfterUploadedAt: all[1].UploadedAt, AfterNumber: all[1].Number, Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, all[2:], page)
+
+	page, err = s.GetOrdersByUserID(ctx, models.OrderQuery{Limit: 2, Desc: true})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Order{all[3], all[2]}, page)
+
+	page, err = s.GetOrdersByUserID(ctx, models.OrderQuery{Status: "PROCESSED", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, all[0].Number, page[0].Number)
+
+	page, err = s.GetOrdersByUserID(ctx, models.OrderQuery{To: all[0].UploadedAt, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func TestAddWithdrawUsesTOTPStep(t *testing.T) {
+	s := newTestStorage(t)
+
+	ctx := newTestUser(t, s, models.Points(1000))
+	userID, ok := ctx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	require.NoError(t, s.SaveTOTPSetup(ctx, userID, "JBSWY3DPEHPK3PXP", nil))
+	require.NoError(t, s.ConfirmTOTP(ctx, userID, 100))
+
+	_, err := s.AddLoginFailure(ctx, models.LoginScopeTOTP, strconv.Itoa(userID), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	// Отказ в списании не расходует код.
+	assert.ErrorIs(t, s.AddWithdraw(ctx, "2377225624", 5000, 101), ErrUserInsufficientFunds)
+	require.NoError(t, s.AddWithdraw(ctx, "2377225624", 500, 101))
+	assert.ErrorIs(t, s.AddWithdraw(ctx, "12345678903", 100, 101), ErrTOTPStepUsed)
+
+	// Принятый код сбрасывает счетчик неверных кодов.
+	failures, err := s.AddLoginFailure(ctx, models.LoginScopeTOTP, strconv.Itoa(userID), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+}
@@ -0,0 +1,92 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrIdempotencyKeyBusy = errors.New("idempotency key is being processed")
+
+// ReserveIdempotencyKey захватывает ключ на время lease. Ключ, захват которого истек без сохраненного ответа,
+// например после паники или остановки экземпляра, может захватить повтор того же запроса.
+func (s *DBStorage) ReserveIdempotencyKey(ctx context.Context,
+	key string, fingerprint string, ttl time.Duration, lease time.Duration) (models.IdempotencyKey, bool, error) {
+	const query = `
+		WITH new_key AS (
+			INSERT INTO idempotency_keys (user_id, key, fingerprint, expires_at, locked_until)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, key) DO UPDATE
+			SET (fingerprint, status_code, content_type, body, created_at, expires_at, locked_until) =
+				(EXCLUDED.fingerprint, 0, '', NULL, now(), EXCLUDED.expires_at, EXCLUDED.locked_until)
+			WHERE idempotency_keys.expires_at < now()
+				OR (idempotency_keys.status_code = 0 AND idempotency_keys.locked_until < now()
+					AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+			RETURNING *
+		)
+		SELECT user_id, key, fingerprint, status_code, content_type, body, created_at, expires_at, true AS is_new
+		FROM new_key
+		UNION ALL
+		SELECT user_id, key, fingerprint, status_code, content_type, body, created_at, expires_at, false AS is_new
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND NOT EXISTS (SELECT 1 FROM new_key)
+	`
+
+	now := time.Now()
+	row := s.pool.QueryRow(ctx, query, ctx.Value(common.KeyUserID), key, fingerprint, now.Add(ttl), now.Add(lease))
+
+	var k models.IdempotencyKey
+	var isNew bool
+
+	err := row.Scan(&k.UserID, &k.Key, &k.Fingerprint, &k.StatusCode, &k.ContentType, &k.Body,
+		&k.CreatedAt, &k.ExpiresAt, &isNew)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return k, false, ErrIdempotencyKeyBusy
+		}
+
+		return k, false, fmt.Errorf(failedScanStr, err)
+	}
+
+	return k, isNew, nil
+}
+
+func (s *DBStorage) SaveIdempotencyResponse(ctx context.Context,
+	key string, statusCode int, contentType string, body []byte) error {
+	const query = `
+		UPDATE idempotency_keys SET (status_code, content_type, body, locked_until) = ($3, $4, $5, NULL)
+		WHERE user_id = $1 AND key = $2
+	`
+
+	if _, err := s.pool.Exec(ctx, query, ctx.Value(common.KeyUserID), key, statusCode, contentType, body); err != nil {
+		return fmt.Errorf("failed to save idempotency response: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	const query = `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`
+
+	if _, err := s.pool.Exec(ctx, query, ctx.Value(common.KeyUserID), key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	const query = `DELETE FROM idempotency_keys WHERE expires_at < now()`
+
+	tag, err := s.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveIdempotencyKeyLease(t *testing.T) {
+	s := newTestStorage(t)
+
+	suffix := time.Now().UnixNano()
+	u, err := s.AddUser(context.Background(), fmt.Sprintf("test-user-%d", suffix), "", []byte("hash"))
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, u.ID)
+
+	_, isNew, err := s.ReserveIdempotencyKey(ctx, "active", "fp", time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.True(t, isNew)
+
+	// Захват еще действует: повтор видит незавершенный запрос.
+	stored, isNew, err := s.ReserveIdempotencyKey(ctx, "active", "fp", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+	assert.Equal(t, 0, stored.StatusCode)
+
+	_, isNew, err = s.ReserveIdempotencyKey(ctx, "stale", "fp", time.Hour, -time.Second)
+	require.NoError(t, err)
+	require.True(t, isNew)
+
+	// Запрос с другим телом не перехватывает истекший захват.
+	stored, isNew, err = s.ReserveIdempotencyKey(ctx, "stale", "other", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+	assert.Equal(t, "fp", stored.Fingerprint)
+
+	_, isNew, err = s.ReserveIdempotencyKey(ctx, "stale", "fp", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+
+	// Сохраненный ответ не перехватывается и после истечения захвата.
+	_, isNew, err = s.ReserveIdempotencyKey(ctx, "done", "fp", time.Hour, -time.Second)
+	require.NoError(t, err)
+	require.True(t, isNew)
+	require.NoError(t, s.SaveIdempotencyResponse(ctx, "done", 202, "", nil))
+
+	stored, isNew, err = s.ReserveIdempotencyKey(ctx, "done", "fp", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+	assert.Equal(t, 202, stored.StatusCode)
+}
@@ -0,0 +1,161 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrLedgerMismatch = errors.New("ledger does not match balance")
+
+func (s *DBStorage) GetLedgerEntries(ctx context.Context, afterID int64, limit int) ([]models.LedgerEntry, error) {
+	const query = `
+		SELECT id, kind, amount, balance_after, COALESCE(order_number, ''), COALESCE(reason, ''),
+			COALESCE(reverses_id, 0), created_at, user_id
+		FROM ledger
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id ASC
+		LIMIT $3
+	`
+
+	entries := []models.LedgerEntry{}
+
+	rows, err := s.pool.Query(ctx, query, ctx.Value(common.KeyUserID), afterID, limit)
+	if err != nil {
+		return []models.LedgerEntry{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.LedgerEntry
+		err = rows.Scan(&e.ID, &e.Kind, &e.Amount, &e.BalanceAfter, &e.OrderNumber, &e.Reason,
+			&e.ReversesID, &e.CreatedAt, &e.UserID)
+		if err != nil {
+			return []models.LedgerEntry{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return []models.LedgerEntry{}, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	return entries, nil
+}
+
+// appendLedgerEntry применяет запись к балансу и сохраняет её в журнал в рамках переданной транзакции.
+// Баланс сверяется с последней записью журнала, расхождение откатывает транзакцию.
+func appendLedgerEntry(ctx context.Context, tx pgx.Tx, e *models.LedgerEntry, withdrawn models.Points) error {
+	const updateBalanceQuery = `
+		UPDATE balance SET (current, withdrawn) = (current + $1, withdrawn + $2)
+		WHERE user_id = $3
+		RETURNING current
+	`
+	const lastEntryQuery = `SELECT balance_after FROM ledger WHERE user_id = $1 ORDER BY id DESC LIMIT 1`
+	const addEntryQuery = `
+		INSERT INTO ledger (user_id, kind, amount, balance_after, order_number, reason, reverses_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7::bigint, 0))
+		RETURNING id, created_at
+	`
+
+	var current models.Points
+	if err := tx.QueryRow(ctx, updateBalanceQuery, e.Amount, withdrawn, e.UserID).Scan(&current); err != nil {
+		return fmt.Errorf("failed to update balance: %w", err)
+	}
+
+	var previous models.Points
+	if err := tx.QueryRow(ctx, lastEntryQuery, e.UserID).Scan(&previous); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf(failedScanStr, err)
+		}
+	}
+
+	if previous+e.Amount != current {
+		return fmt.Errorf("%w for user %d: expected %s, got %s", ErrLedgerMismatch, e.UserID, previous+e.Amount, current)
+	}
+
+	e.BalanceAfter = current
+
+	row := tx.QueryRow(ctx, addEntryQuery,
+		e.UserID, e.Kind, e.Amount, e.BalanceAfter, e.OrderNumber, e.Reason, e.ReversesID)
+	if err := row.Scan(&e.ID, &e.CreatedAt); err != nil {
+		return fmt.Errorf("failed to add ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+// orderCredit возвращает сумму, которая должна быть начислена пользователю за заказ в указанном статусе.
+func orderCredit(status string, accrual models.Points) models.Points {
+	if status == "PROCESSED" {
+		return accrual
+	}
+
+	return 0
+}
+
+// settleOrderAccrual приводит сумму записей журнала по заказу к ожидаемой и возвращает добавленную запись
+// (с нулевым ID, если баланс менять не нужно). Первое начисление записывается как ACCRUAL, полная отмена
+// начисления — как REVERSAL, остальные исправления — как ADJUSTMENT с номером заказа.
+func settleOrderAccrual(ctx context.Context, tx pgx.Tx,
+	userID int, number string, expected models.Points, reason string) (models.LedgerEntry, error) {
+	const creditedQuery = `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM ledger
+		WHERE user_id = $1 AND order_number = $2 AND kind <> 'WITHDRAWAL'
+	`
+	const accrualQuery = `
+		SELECT a.id, a.amount, EXISTS (SELECT 1 FROM ledger r WHERE r.reverses_id = a.id)
+		FROM ledger a
+		WHERE a.order_number = $1 AND a.kind = 'ACCRUAL'
+	`
+
+	var credited models.Points
+	if err := tx.QueryRow(ctx, creditedQuery, userID, number).Scan(&credited); err != nil {
+		return models.LedgerEntry{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	delta := expected - credited
+	if delta == 0 {
+		return models.LedgerEntry{}, nil
+	}
+
+	var (
+		accrualID     int64
+		accrualAmount models.Points
+		reversed      bool
+	)
+
+	err := tx.QueryRow(ctx, accrualQuery, number).Scan(&accrualID, &accrualAmount, &reversed)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.LedgerEntry{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	entry := models.LedgerEntry{
+		UserID:      userID,
+		Kind:        models.LedgerKindAdjustment,
+		Amount:      delta,
+		OrderNumber: number,
+		Reason:      reason,
+	}
+
+	switch {
+	case accrualID == 0 && delta > 0:
+		entry.Kind = models.LedgerKindAccrual
+	case accrualID != 0 && !reversed && expected == 0 && credited == accrualAmount:
+		entry.Kind = models.LedgerKindReversal
+		entry.ReversesID = accrualID
+	}
+
+	if err := appendLedgerEntry(ctx, tx, &entry, 0); err != nil {
+		return models.LedgerEntry{}, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,131 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetLoginLockedUntil возвращает время окончания блокировки входа по логину или IP. Нулевое время — блокировки нет.
+func (s *DBStorage) GetLoginLockedUntil(ctx context.Context, login string, ip string) (time.Time, error) {
+	const query = `
+		SELECT max(locked_until) FROM login_throttles
+		WHERE (scope = 'LOGIN' AND subject = $1) OR (scope = 'IP' AND subject = $2)
+	`
+
+	var lockedUntil *time.Time
+
+	if err := s.pool.QueryRow(ctx, query, login, ip).Scan(&lockedUntil); err != nil {
+		return time.Time{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	if lockedUntil == nil {
+		return time.Time{}, nil
+	}
+
+	return *lockedUntil, nil
+}
+
+// GetThrottleLockedUntil возвращает время окончания блокировки по одному субъекту. Нулевое время — блокировки нет.
+func (s *DBStorage) GetThrottleLockedUntil(ctx context.Context, scope string, subject string) (time.Time, error) {
+	const query = `SELECT locked_until FROM login_throttles WHERE scope = $1 AND subject = $2`
+
+	var lockedUntil *time.Time
+
+	if err := s.pool.QueryRow(ctx, query, scope, subject).Scan(&lockedUntil); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	if lockedUntil == nil {
+		return time.Time{}, nil
+	}
+
+	return *lockedUntil, nil
+}
+
+// AddLoginFailure увеличивает счетчик неудачных попыток и возвращает его новое значение.
+// Счетчик начинается заново, если последняя попытка и блокировка закончились раньше windowStart.
+func (s *DBStorage) AddLoginFailure(ctx context.Context,
+	scope string, subject string, windowStart time.Time) (int, error) {
+	const query = `
+		INSERT INTO login_throttles (scope, subject, failures)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (scope, subject) DO UPDATE
+		SET (failures, updated_at) = (
+			CASE
+				WHEN GREATEST(login_throttles.updated_at, login_throttles.locked_until) < $3 THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			now()
+		)
+		RETURNING failures
+	`
+
+	var failures int
+
+	if err := s.pool.QueryRow(ctx, query, scope, subject, windowStart).Scan(&failures); err != nil {
+		return 0, fmt.Errorf(failedScanStr, err)
+	}
+
+	return failures, nil
+}
+
+// LockLogin блокирует вход до lockedUntil и сохраняет запись о блокировке для аудита.
+func (s *DBStorage) LockLogin(ctx context.Context,
+	scope string, subject string, failures int, lockedUntil time.Time) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	const lockQuery = `UPDATE login_throttles SET locked_until = $3 WHERE scope = $1 AND subject = $2`
+
+	if _, err := tx.Exec(ctx, lockQuery, scope, subject, lockedUntil); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+
+	const auditQuery = `
+		INSERT INTO login_lockouts (scope, subject, failures, locked_until)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := tx.Exec(ctx, auditQuery, scope, subject, failures, lockedUntil); err != nil {
+		return fmt.Errorf("failed to insert login lockout: %w", err)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ResetLoginFailures(ctx context.Context, login string) error {
+	const query = `DELETE FROM login_throttles WHERE scope = 'LOGIN' AND subject = $1`
+
+	if _, err := s.pool.Exec(ctx, query, login); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredLoginThrottles удаляет счетчики, которые уже не влияют на блокировку.
+func (s *DBStorage) DeleteExpiredLoginThrottles(ctx context.Context, windowStart time.Time) (int64, error) {
+	const query = `DELETE FROM login_throttles WHERE GREATEST(updated_at, locked_until) < $1`
+
+	tag, err := s.pool.Exec(ctx, query, windowStart)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired login throttles: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
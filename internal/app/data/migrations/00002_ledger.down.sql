@@ -0,0 +1,11 @@
+BEGIN TRANSACTION;
+
+DROP TRIGGER ledger_immutable_trigger ON ledger;
+DROP FUNCTION ledger_immutable;
+DROP INDEX ledger_reverses_id_index;
+DROP INDEX ledger_accrual_order_index;
+DROP INDEX ledger_user_id_index;
+DROP TABLE ledger;
+DROP TYPE ledger_kind;
+
+COMMIT;
@@ -0,0 +1,48 @@
+BEGIN TRANSACTION;
+
+CREATE TYPE ledger_kind AS ENUM ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'REVERSAL');
+CREATE TABLE ledger(
+	id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+	user_id INT REFERENCES users(id) NOT NULL,
+  kind ledger_kind NOT NULL,
+  amount NUMERIC(10,2) NOT NULL,
+  balance_after NUMERIC(10,2) NOT NULL,
+  order_number VARCHAR(200),
+  reason TEXT,
+  reverses_id BIGINT REFERENCES ledger(id),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX ledger_user_id_index ON ledger(user_id, id);
+CREATE UNIQUE INDEX ledger_accrual_order_index ON ledger(order_number) WHERE kind = 'ACCRUAL';
+CREATE UNIQUE INDEX ledger_reverses_id_index ON ledger(reverses_id);
+
+CREATE FUNCTION ledger_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'ledger entries are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_immutable_trigger
+	BEFORE UPDATE OR DELETE ON ledger
+	FOR EACH ROW EXECUTE FUNCTION ledger_immutable();
+
+INSERT INTO ledger (user_id, kind, amount, balance_after, order_number, created_at)
+SELECT user_id, kind, amount, SUM(amount) OVER (
+		PARTITION BY user_id ORDER BY created_at, kind, order_number ROWS UNBOUNDED PRECEDING
+	), order_number, created_at
+FROM (
+	SELECT user_id, 'ACCRUAL'::ledger_kind AS kind, accrual AS amount, number AS order_number, uploaded_at AS created_at
+	FROM orders WHERE status = 'PROCESSED' AND accrual > 0
+	UNION ALL
+	SELECT user_id, 'WITHDRAWAL'::ledger_kind, -sum, order_number, processed_at
+	FROM withdrawals
+) AS history
+ORDER BY created_at, kind, order_number;
+
+INSERT INTO ledger (user_id, kind, amount, balance_after, reason)
+SELECT b.user_id, 'ADJUSTMENT', b.current - COALESCE(l.total, 0), b.current, 'opening balance reconciliation'
+FROM balance b
+LEFT JOIN (SELECT user_id, SUM(amount) AS total FROM ledger GROUP BY user_id) l ON l.user_id = b.user_id
+WHERE b.current <> COALESCE(l.total, 0);
+
+COMMIT;
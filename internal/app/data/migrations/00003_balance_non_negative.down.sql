@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE balance DROP CONSTRAINT balance_current_non_negative;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE balance ADD CONSTRAINT balance_current_non_negative CHECK (current >= 0);
+
+COMMIT;
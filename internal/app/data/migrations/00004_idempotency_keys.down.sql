@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+DROP INDEX idempotency_keys_expires_at_index;
+DROP TABLE idempotency_keys;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE idempotency_keys(
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  key VARCHAR(255) NOT NULL,
+  fingerprint VARCHAR(64) NOT NULL,
+  status_code INT DEFAULT 0 NOT NULL,
+  content_type VARCHAR(255) DEFAULT '' NOT NULL,
+  body BYTEA,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  PRIMARY KEY (user_id, key)
+);
+CREATE INDEX idempotency_keys_expires_at_index ON idempotency_keys(expires_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+DROP INDEX sessions_expires_at_index;
+DROP INDEX sessions_user_id_index;
+DROP INDEX sessions_refresh_token_hash_index;
+DROP TABLE sessions;
+
+COMMIT;
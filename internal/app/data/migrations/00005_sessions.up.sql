@@ -0,0 +1,15 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE sessions(
+	id VARCHAR(64) PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  refresh_token_hash BYTEA NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  revoked_at TIMESTAMP WITH TIME ZONE
+);
+CREATE UNIQUE INDEX sessions_refresh_token_hash_index ON sessions(refresh_token_hash);
+CREATE INDEX sessions_user_id_index ON sessions(user_id);
+CREATE INDEX sessions_expires_at_index ON sessions(expires_at);
+
+COMMIT;
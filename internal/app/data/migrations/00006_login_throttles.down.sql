@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+DROP INDEX login_lockouts_subject_index;
+DROP TABLE login_lockouts;
+DROP INDEX login_throttles_updated_at_index;
+DROP TABLE login_throttles;
+DROP TYPE login_throttle_scope;
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN TRANSACTION;
+
+CREATE TYPE login_throttle_scope AS ENUM ('LOGIN', 'IP');
+
+CREATE TABLE login_throttles(
+  scope login_throttle_scope NOT NULL,
+  subject VARCHAR(200) NOT NULL,
+  failures INT DEFAULT 0 NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  locked_until TIMESTAMP WITH TIME ZONE,
+  PRIMARY KEY (scope, subject)
+);
+CREATE INDEX login_throttles_updated_at_index ON login_throttles(updated_at);
+
+CREATE TABLE login_lockouts(
+  id BIGSERIAL PRIMARY KEY,
+  scope login_throttle_scope NOT NULL,
+  subject VARCHAR(200) NOT NULL,
+  failures INT NOT NULL,
+  locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX login_lockouts_subject_index ON login_lockouts(scope, subject);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE withdrawals
+  DROP CONSTRAINT withdrawals_user_id_fkey,
+  ADD CONSTRAINT withdrawals_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE orders
+  DROP CONSTRAINT orders_user_id_fkey,
+  ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE balance
+  DROP CONSTRAINT balance_user_id_fkey,
+  ADD CONSTRAINT balance_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE users
+  DROP COLUMN deleted_at,
+  DROP COLUMN password_changed_at,
+  DROP COLUMN created_at;
+
+COMMIT;
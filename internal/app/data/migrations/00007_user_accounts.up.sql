@@ -0,0 +1,20 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users
+  ADD COLUMN created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  ADD COLUMN password_changed_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- История начислений и списаний должна переживать удаление пользователя,
+-- поэтому вместо каскадного удаления пользователь обезличивается.
+ALTER TABLE balance
+  DROP CONSTRAINT balance_user_id_fkey,
+  ADD CONSTRAINT balance_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE orders
+  DROP CONSTRAINT orders_user_id_fkey,
+  ADD CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+ALTER TABLE withdrawals
+  DROP CONSTRAINT withdrawals_user_id_fkey,
+  ADD CONSTRAINT withdrawals_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+COMMIT;
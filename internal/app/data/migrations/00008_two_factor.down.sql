@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+DROP INDEX login_challenges_expires_at_index;
+DROP TABLE login_challenges;
+DROP INDEX recovery_codes_user_id_code_hash_index;
+DROP TABLE recovery_codes;
+DROP TABLE user_totp;
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE user_totp(
+  user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  secret VARCHAR(64) NOT NULL,
+  last_used_step BIGINT DEFAULT 0 NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  confirmed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE recovery_codes(
+  id BIGSERIAL PRIMARY KEY,
+  user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  code_hash BYTEA NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE
+);
+CREATE UNIQUE INDEX recovery_codes_user_id_code_hash_index ON recovery_codes(user_id, code_hash);
+
+CREATE TABLE login_challenges(
+  token_hash BYTEA PRIMARY KEY,
+  user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  attempts INT DEFAULT 0 NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  completed_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX login_challenges_expires_at_index ON login_challenges(expires_at);
+
+COMMIT;
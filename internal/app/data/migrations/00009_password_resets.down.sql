@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+DROP INDEX password_reset_tokens_expires_at_index;
+DROP INDEX password_reset_tokens_user_id_index;
+DROP TABLE password_reset_tokens;
+
+ALTER TABLE users DROP COLUMN email;
+
+COMMIT;
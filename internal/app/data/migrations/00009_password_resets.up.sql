@@ -0,0 +1,15 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users ADD COLUMN email VARCHAR(254);
+
+CREATE TABLE password_reset_tokens(
+  token_hash BYTEA PRIMARY KEY,
+  user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX password_reset_tokens_user_id_index ON password_reset_tokens(user_id);
+CREATE INDEX password_reset_tokens_expires_at_index ON password_reset_tokens(expires_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users DROP COLUMN role;
+DROP TYPE user_role;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+CREATE TYPE user_role AS ENUM ('USER', 'ADMIN');
+ALTER TABLE users ADD COLUMN role user_role DEFAULT 'USER' NOT NULL;
+
+COMMIT;
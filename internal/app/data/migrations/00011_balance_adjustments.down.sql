@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+DROP INDEX balance_adjustments_admin_id_index;
+DROP INDEX balance_adjustments_user_id_index;
+DROP TABLE balance_adjustments;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE balance_adjustments(
+  id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  admin_id INT REFERENCES users(id) NOT NULL,
+  user_id INT REFERENCES users(id) NOT NULL,
+  ledger_id BIGINT REFERENCES ledger(id) NOT NULL,
+  amount NUMERIC(10,2) NOT NULL,
+  reason TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX balance_adjustments_user_id_index ON balance_adjustments(user_id, id);
+CREATE INDEX balance_adjustments_admin_id_index ON balance_adjustments(admin_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+DROP INDEX order_overrides_admin_id_index;
+DROP INDEX order_overrides_order_number_index;
+DROP TABLE order_overrides;
+DROP TYPE order_override_action;
+
+COMMIT;
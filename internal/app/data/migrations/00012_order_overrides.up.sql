@@ -0,0 +1,22 @@
+BEGIN TRANSACTION;
+
+CREATE TYPE order_override_action AS ENUM ('RECHECK', 'RESET', 'SET_STATUS');
+CREATE TABLE order_overrides(
+  id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  admin_id INT REFERENCES users(id) NOT NULL,
+  user_id INT REFERENCES users(id) NOT NULL,
+  order_number VARCHAR(200) NOT NULL,
+  action order_override_action NOT NULL,
+  old_status order_status NOT NULL,
+  new_status order_status NOT NULL,
+  old_accrual NUMERIC(10,2) NOT NULL,
+  new_accrual NUMERIC(10,2) NOT NULL,
+  balance_delta NUMERIC(10,2) NOT NULL,
+  ledger_id BIGINT REFERENCES ledger(id),
+  reason TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX order_overrides_order_number_index ON order_overrides(order_number, id);
+CREATE INDEX order_overrides_admin_id_index ON order_overrides(admin_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+DROP INDEX audit_events_created_at_index;
+DROP INDEX audit_events_actor_id_index;
+DROP INDEX audit_events_user_id_index;
+DROP TABLE audit_events;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE audit_events(
+  id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  actor_id INT,
+  user_id INT,
+  action VARCHAR(64) NOT NULL,
+  target_type VARCHAR(32) NOT NULL,
+  target_id VARCHAR(200) NOT NULL,
+  request_id VARCHAR(64),
+  ip VARCHAR(64),
+  before JSONB,
+  after JSONB,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX audit_events_user_id_index ON audit_events(user_id, created_at);
+CREATE INDEX audit_events_actor_id_index ON audit_events(actor_id, created_at);
+CREATE INDEX audit_events_created_at_index ON audit_events(created_at);
+
+COMMIT;
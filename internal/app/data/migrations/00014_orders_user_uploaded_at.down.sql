@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+DROP INDEX orders_user_id_uploaded_at_index;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+CREATE INDEX orders_user_id_uploaded_at_index ON orders(user_id, uploaded_at, number);
+
+COMMIT;
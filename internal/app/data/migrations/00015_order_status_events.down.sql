@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+DROP INDEX order_status_events_order_number_index;
+DROP TABLE order_status_events;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE order_status_events(
+  id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  order_number VARCHAR(200) REFERENCES orders(number) ON DELETE CASCADE NOT NULL,
+  status order_status NOT NULL,
+  accrual NUMERIC(10,2) DEFAULT 0 NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX order_status_events_order_number_index ON order_status_events(order_number, id);
+
+INSERT INTO order_status_events (order_number, status, created_at)
+SELECT number, 'NEW', uploaded_at FROM orders;
+
+INSERT INTO order_status_events (order_number, status, accrual)
+SELECT number, status, accrual FROM orders WHERE status <> 'NEW';
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN TRANSACTION;
+
+DROP INDEX webhook_deliveries_pending_index;
+DROP INDEX webhook_deliveries_webhook_id_index;
+DROP TABLE webhook_deliveries;
+DROP TYPE webhook_delivery_status;
+DROP INDEX webhooks_user_id_index;
+DROP TABLE webhooks;
+
+COMMIT;
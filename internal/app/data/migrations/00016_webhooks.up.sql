@@ -0,0 +1,31 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE webhooks(
+  id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  user_id INT REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  event_types TEXT[] NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX webhooks_user_id_index ON webhooks(user_id);
+
+CREATE TYPE webhook_delivery_status AS ENUM ('PENDING', 'DELIVERED', 'FAILED');
+CREATE TABLE webhook_deliveries(
+  id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  webhook_id BIGINT REFERENCES webhooks(id) ON DELETE CASCADE NOT NULL,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status webhook_delivery_status DEFAULT 'PENDING' NOT NULL,
+  attempts INT DEFAULT 0 NOT NULL,
+  next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+  last_attempt_at TIMESTAMP WITH TIME ZONE,
+  response_status INT,
+  last_error TEXT,
+  delivered_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+CREATE INDEX webhook_deliveries_webhook_id_index ON webhook_deliveries(webhook_id, id);
+CREATE INDEX webhook_deliveries_pending_index ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+DELETE FROM login_lockouts WHERE scope = 'TOTP';
+DELETE FROM login_throttles WHERE scope = 'TOTP';
+
+ALTER TYPE login_throttle_scope RENAME TO login_throttle_scope_old;
+CREATE TYPE login_throttle_scope AS ENUM ('LOGIN', 'IP');
+ALTER TABLE login_throttles ALTER COLUMN scope TYPE login_throttle_scope USING scope::text::login_throttle_scope;
+ALTER TABLE login_lockouts ALTER COLUMN scope TYPE login_throttle_scope USING scope::text::login_throttle_scope;
+DROP TYPE login_throttle_scope_old;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+ALTER TYPE login_throttle_scope ADD VALUE 'TOTP';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE idempotency_keys DROP COLUMN locked_until;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
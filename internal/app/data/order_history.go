@@ -0,0 +1,77 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetOrderByNumber возвращает заказ текущего пользователя. Чужой заказ не отличается от несуществующего.
+func (s *DBStorage) GetOrderByNumber(ctx context.Context, number string) (models.Order, error) {
+	const query = `
+		SELECT number, status, accrual, uploaded_at, user_id
+		FROM orders
+		WHERE number = $1 AND user_id = $2
+	`
+
+	var o models.Order
+
+	row := s.pool.QueryRow(ctx, query, number, ctx.Value(common.KeyUserID))
+	if err := row.Scan(&o.Number, &o.Status, &o.Accrual, &o.UploadedAt, &o.UserID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Order{}, fmt.Errorf("%w with number: %s", ErrOrderNotFound, number)
+		}
+		return models.Order{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	return o, nil
+}
+
+// GetOrderStatusEvents возвращает историю статусов заказа в порядке изменения.
+func (s *DBStorage) GetOrderStatusEvents(ctx context.Context, number string) ([]models.OrderStatusEvent, error) {
+	const query = `
+		SELECT status, accrual, created_at
+		FROM order_status_events
+		WHERE order_number = $1
+		ORDER BY id ASC
+	`
+
+	events := []models.OrderStatusEvent{}
+
+	rows, err := s.pool.Query(ctx, query, number)
+	if err != nil {
+		return []models.OrderStatusEvent{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.OrderStatusEvent
+		if err := rows.Scan(&e.Status, &e.Accrual, &e.CreatedAt); err != nil {
+			return []models.OrderStatusEvent{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		events = append(events, e)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return []models.OrderStatusEvent{}, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	return events, nil
+}
+
+// addOrderStatusEvent дописывает изменение статуса в историю заказа в рамках транзакции изменения.
+func addOrderStatusEvent(ctx context.Context, tx pgx.Tx, number string, status string, accrual models.Points) error {
+	const query = `INSERT INTO order_status_events (order_number, status, accrual) VALUES ($1, $2, $3)`
+
+	if _, err := tx.Exec(ctx, query, number, status, accrual); err != nil {
+		return fmt.Errorf("failed to add order status event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderStatusEvents(t *testing.T) {
+	s := newTestStorage(t)
+
+	userCtx := newTestUser(t, s, 0)
+	adminCtx := newTestUser(t, s, 0)
+
+	orders, err := s.GetOrdersByUserID(userCtx, models.OrderQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	number := orders[0].Number
+
+	_, err = s.OverrideOrder(adminCtx, models.OrderOverride{
+		OrderNumber: number, Action: models.OrderActionReset, NewStatus: "NEW", Reason: "recheck",
+	})
+	require.NoError(t, err)
+
+	for _, status := range []string{"PROCESSING", "PROCESSING"} {
+		_, err = s.UpdateOrder(context.Background(), number, status, 0)
+		require.NoError(t, err)
+	}
+
+	_, err = s.UpdateOrder(context.Background(), number, "PROCESSED", 700)
+	require.NoError(t, err)
+
+	order, err := s.GetOrderByNumber(userCtx, number)
+	require.NoError(t, err)
+	assert.Equal(t, models.Points(700), order.Accrual)
+
+	events, err := s.GetOrderStatusEvents(userCtx, number)
+	require.NoError(t, err)
+
+	statuses := make([]string, 0, len(events))
+	for _, e := range events {
+		statuses = append(statuses, e.Status)
+	}
+	assert.Equal(t, []string{"NEW", "PROCESSED", "NEW", "PROCESSING", "PROCESSED"}, statuses)
+	assert.Equal(t, models.Points(700), events[len(events)-1].Accrual)
+
+	_, err = s.GetOrderByNumber(adminCtx, number)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	_, err = s.GetOrderByNumber(context.WithValue(context.Background(), common.KeyUserID, 0), "missing")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func TestAddOrders(t *testing.T) {
+	s := newTestStorage(t)
+
+	userCtx := newTestUser(t, s, 0)
+	otherCtx := newTestUser(t, s, 0)
+
+	taken, err := s.GetOrdersByUserID(otherCtx, models.OrderQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, taken, 1)
+
+	own, err := s.GetOrdersByUserID(userCtx, models.OrderQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, own, 1)
+
+	fresh := fmt.Sprintf("%d", time.Now().UnixNano())
+
+	orders, err := s.AddOrders(userCtx, []string{fresh, own[0].Number, taken[0].Number})
+	require.NoError(t, err)
+	require.Len(t, orders, 3)
+
+	byNumber := make(map[string]models.BatchOrder, len(orders))
+	for _, o := range orders {
+		byNumber[o.Number] = o
+	}
+
+	userID, ok := userCtx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	assert.True(t, byNumber[fresh].Created)
+	assert.Equal(t, userID, byNumber[fresh].UserID)
+	assert.False(t, byNumber[own[0].Number].Created)
+	assert.False(t, byNumber[taken[0].Number].Created)
+	assert.NotEqual(t, userID, byNumber[taken[0].Number].UserID)
+
+	events, err := s.GetOrderStatusEvents(userCtx, fresh)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "NEW", events[0].Status)
+}
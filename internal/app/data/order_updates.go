@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// orderUpdatesChannel — канал LISTEN/NOTIFY, через который экземпляры сервиса узнают о сменах статусов заказов.
+const orderUpdatesChannel = "order_updates"
+
+// orderUpdateMessage — содержимое уведомления. Поле UserID перекрывает скрытое от JSON поле модели.
+type orderUpdateMessage struct {
+	models.OrderUpdate
+	UserID int `json:"user_id"`
+}
+
+// ListenOrderUpdates подписывается на смены статусов заказов на отдельном соединении и передает их handle,
+// пока не будет отменен контекст или не оборвется соединение. Уведомления, отправленные без подписки,
+// теряются, поэтому после ошибки вызывающий должен подписаться заново.
+func (s *DBStorage) ListenOrderUpdates(ctx context.Context, handle func(models.OrderUpdate)) error {
+	pc, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	// Соединение с LISTEN нельзя возвращать в пул, поэтому оно забирается из пула и закрывается.
+	conn := pc.Hijack()
+	defer func() {
+		if err := conn.Close(context.WithoutCancel(ctx)); err != nil {
+			s.logger.Error("failed to close listen connection", zap.Error(err))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+orderUpdatesChannel); err != nil {
+		return fmt.Errorf("failed to listen order updates: %w", err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+
+		var msg orderUpdateMessage
+		if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil {
+			s.logger.Error("failed to decode order update", zap.String("payload", n.Payload), zap.Error(err))
+			continue
+		}
+
+		msg.OrderUpdate.UserID = msg.UserID
+		handle(msg.OrderUpdate)
+	}
+}
+
+// notifyOrderUpdate отправляет уведомление о смене статуса заказа. Уведомление доставляется слушателям
+// только после фиксации транзакции, а время смены совпадает со временем записи в истории заказа.
+func notifyOrderUpdate(ctx context.Context,
+	tx pgx.Tx, userID int, number string, status string, accrual models.Points) error {
+	const query = `
+		SELECT pg_notify($1, json_build_object(
+			'user_id', $2::int,
+			'number', $3::text,
+			'status', $4::text,
+			'accrual', $5::numeric,
+			'updated_at', now()
+		)::text)
+	`
+
+	if _, err := tx.Exec(ctx, query, orderUpdatesChannel, userID, number, status, accrual); err != nil {
+		return fmt.Errorf("failed to notify order update: %w", err)
+	}
+
+	return nil
+}
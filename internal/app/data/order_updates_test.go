@@ -0,0 +1,69 @@
+package data
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenOrderUpdates(t *testing.T) {
+	s := newTestStorage(t)
+
+	userCtx := newTestUser(t, s, 0)
+	userID, ok := userCtx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan models.OrderUpdate, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.ListenOrderUpdates(ctx, func(u models.OrderUpdate) {
+			if u.UserID == userID {
+				updates <- u
+			}
+		})
+	}()
+
+	// Уведомления до LISTEN не доставляются, поэтому сначала дожидаемся подписки.
+	require.Eventually(t, func() bool {
+		var n int
+		err := s.pool.QueryRow(context.Background(),
+			`SELECT count(*) FROM pg_stat_activity WHERE query = 'LISTEN ' || $1::text`, orderUpdatesChannel).Scan(&n)
+		return err == nil && n > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	number := strconv.FormatInt(time.Now().UnixNano(), 10)
+	_, _, err := s.AddOrder(userCtx, number)
+	require.NoError(t, err)
+
+	for _, status := range []string{"PROCESSING", "PROCESSING", "PROCESSED"} {
+		_, err = s.UpdateOrder(context.Background(), number, status, 700)
+		require.NoError(t, err)
+	}
+
+	statuses := []string{}
+	for range 2 {
+		select {
+		case u := <-updates:
+			assert.Equal(t, number, u.Number)
+			assert.False(t, u.UpdatedAt.IsZero())
+			statuses = append(statuses, u.Status)
+			if u.Status == "PROCESSED" {
+				assert.Equal(t, models.Points(700), u.Accrual)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for order update")
+		}
+	}
+	assert.Equal(t, []string{"PROCESSING", "PROCESSED"}, statuses)
+
+	cancel()
+	assert.Error(t, <-done)
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
+
+// AddPasswordResetToken сохраняет токен сброса пароля и отменяет прежние неиспользованные токены пользователя.
+// Если после since пользователю уже выдавался токен, новый не создается и возвращается false.
+func (s *DBStorage) AddPasswordResetToken(ctx context.Context,
+	token models.PasswordResetToken, since time.Time) (bool, error) {
+	const addQuery = `
+		INSERT INTO password_reset_tokens (token_hash, user_id, expires_at)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (SELECT 1 FROM password_reset_tokens WHERE user_id = $2 AND created_at > $4)
+	`
+	const cancelQuery = `
+		UPDATE password_reset_tokens SET used_at = now()
+		WHERE user_id = $1 AND token_hash <> $2 AND used_at IS NULL
+	`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	tag, err := tx.Exec(ctx, addQuery, token.TokenHash, token.UserID, token.ExpiresAt, since)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert password reset token: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, cancelQuery, token.UserID, token.TokenHash); err != nil {
+		return false, fmt.Errorf("failed to cancel password reset tokens: %w", err)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return true, nil
+}
+
+// GetPasswordResetUser возвращает владельца действующего токена сброса пароля.
+func (s *DBStorage) GetPasswordResetUser(ctx context.Context, tokenHash []byte) (models.User, error) {
+	const query = `
+		SELECT ` + userColumns + ` FROM users
+		WHERE deleted_at IS NULL AND id = (
+			SELECT user_id FROM password_reset_tokens
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		)
+	`
+
+	u, err := scanUser(s.pool.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return models.User{}, ErrPasswordResetTokenNotFound
+		}
+
+		return models.User{}, err
+	}
+
+	return u, nil
+}
+
+// ResetPassword погашает токен сброса, меняет пароль его владельца и отзывает все сессии пользователя.
+// Возвращает идентификатор пользователя, которому принадлежал токен.
+func (s *DBStorage) ResetPassword(ctx context.Context, tokenHash []byte, password []byte) (int, error) {
+	const useTokenQuery = `
+		UPDATE password_reset_tokens SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id
+	`
+	const updateQuery = `
+		UPDATE users SET (password, password_changed_at) = ($2, now())
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	const revokeQuery = `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	var userID int
+
+	if err := tx.QueryRow(ctx, useTokenQuery, tokenHash).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrPasswordResetTokenNotFound
+		}
+
+		return 0, fmt.Errorf(failedScanStr, err)
+	}
+
+	tag, err := tx.Exec(ctx, updateQuery, userID, password)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return 0, ErrPasswordResetTokenNotFound
+	}
+
+	if _, err := tx.Exec(ctx, revokeQuery, userID); err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return userID, nil
+}
+
+func (s *DBStorage) DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error) {
+	const query = `DELETE FROM password_reset_tokens WHERE expires_at < now()`
+
+	tag, err := s.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired password reset tokens: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,56 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPasswordToken(t *testing.T) {
+	s := newTestStorage(t)
+
+	ctx := newTestUser(t, s, 0)
+	userID, ok := ctx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	now := time.Now()
+	first := models.PasswordResetToken{TokenHash: []byte("first-" + now.String()), UserID: userID}
+	first.ExpiresAt = now.Add(time.Hour)
+	second := models.PasswordResetToken{TokenHash: []byte("second-" + now.String()), UserID: userID}
+	second.ExpiresAt = now.Add(time.Hour)
+
+	created, err := s.AddPasswordResetToken(ctx, first, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = s.AddPasswordResetToken(ctx, second, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.False(t, created, "token requested too often")
+
+	created, err = s.AddPasswordResetToken(ctx, second, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	_, err = s.GetPasswordResetUser(ctx, first.TokenHash)
+	assert.ErrorIs(t, err, ErrPasswordResetTokenNotFound, "previous token is cancelled")
+
+	user, err := s.GetPasswordResetUser(ctx, second.TokenHash)
+	require.NoError(t, err)
+	assert.Equal(t, userID, user.ID)
+
+	resetUserID, err := s.ResetPassword(ctx, second.TokenHash, []byte("new-hash"))
+	require.NoError(t, err)
+	assert.Equal(t, userID, resetUserID)
+
+	_, err = s.ResetPassword(ctx, second.TokenHash, []byte("other-hash"))
+	assert.ErrorIs(t, err, ErrPasswordResetTokenNotFound)
+
+	user, err = s.GetCurrentUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new-hash"), user.Password)
+	assert.NotNil(t, user.PasswordChangedAt)
+}
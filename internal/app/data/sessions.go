@@ -0,0 +1,85 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+const sessionColumns = `id, user_id, refresh_token_hash, created_at, expires_at, revoked_at IS NOT NULL`
+
+func (s *DBStorage) AddSession(ctx context.Context, session models.Session) error {
+	const query = `
+		INSERT INTO sessions (id, user_id, refresh_token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := s.pool.Exec(ctx, query, session.ID, session.UserID, session.RefreshTokenHash, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) GetSession(ctx context.Context, sessionID string) (models.Session, error) {
+	const query = `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1 LIMIT 1`
+
+	return scanSession(s.pool.QueryRow(ctx, query, sessionID))
+}
+
+// RotateSession заменяет refresh токен активной сессии. Старый токен после этого становится недействительным.
+func (s *DBStorage) RotateSession(ctx context.Context,
+	oldHash []byte, newHash []byte, expiresAt time.Time) (models.Session, error) {
+	const query = `
+		UPDATE sessions SET (refresh_token_hash, expires_at) = ($2, $3)
+		WHERE refresh_token_hash = $1 AND revoked_at IS NULL AND expires_at > now()
+		RETURNING ` + sessionColumns
+
+	return scanSession(s.pool.QueryRow(ctx, query, oldHash, newHash, expiresAt))
+}
+
+func (s *DBStorage) RevokeSession(ctx context.Context) error {
+	const query = `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	_, err := s.pool.Exec(ctx, query, ctx.Value(common.KeySessionID), ctx.Value(common.KeyUserID))
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	const query = `DELETE FROM sessions WHERE expires_at < now()`
+
+	tag, err := s.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func scanSession(row pgx.Row) (models.Session, error) {
+	var session models.Session
+
+	err := row.Scan(&session.ID, &session.UserID, &session.RefreshTokenHash,
+		&session.CreatedAt, &session.ExpiresAt, &session.Revoked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Session{}, ErrSessionNotFound
+		}
+
+		return models.Session{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,212 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrTOTPNotFound           = errors.New("TOTP is not set up")
+	ErrTOTPAlreadyEnabled     = errors.New("TOTP is already enabled")
+	ErrTOTPStepUsed           = errors.New("TOTP code has already been used")
+	ErrRecoveryCodeNotFound   = errors.New("recovery code not found")
+	ErrLoginChallengeNotFound = errors.New("login challenge not found")
+)
+
+func (s *DBStorage) GetTOTP(ctx context.Context, userID int) (models.TOTP, error) {
+	const query = `
+		SELECT user_id, secret, last_used_step, confirmed_at FROM user_totp WHERE user_id = $1 LIMIT 1
+	`
+
+	var t models.TOTP
+
+	err := s.pool.QueryRow(ctx, query, userID).Scan(&t.UserID, &t.Secret, &t.LastUsedStep, &t.ConfirmedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TOTP{}, ErrTOTPNotFound
+		}
+
+		return models.TOTP{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	return t, nil
+}
+
+// SaveTOTPSetup сохраняет новый неподтвержденный секрет и заменяет коды восстановления.
+// Если TOTP уже подтвержден, возвращает ErrTOTPAlreadyEnabled.
+func (s *DBStorage) SaveTOTPSetup(ctx context.Context, userID int, secret string, recoveryHashes [][]byte) error {
+	const setupQuery = `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET (secret, last_used_step, created_at) = (EXCLUDED.secret, 0, now())
+		WHERE user_totp.confirmed_at IS NULL
+	`
+	const deleteCodesQuery = `DELETE FROM recovery_codes WHERE user_id = $1`
+	const addCodeQuery = `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	tag, err := tx.Exec(ctx, setupQuery, userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrTOTPAlreadyEnabled
+	}
+
+	if _, err := tx.Exec(ctx, deleteCodesQuery, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range recoveryHashes {
+		if _, err := tx.Exec(ctx, addCodeQuery, userID, hash); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ConfirmTOTP(ctx context.Context, userID int, step int64) error {
+	const query = `
+		UPDATE user_totp SET (confirmed_at, last_used_step) = (now(), $2)
+		WHERE user_id = $1 AND confirmed_at IS NULL AND last_used_step < $2
+	`
+
+	tag, err := s.pool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to confirm TOTP: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrTOTPNotFound
+	}
+
+	return nil
+}
+
+// UseTOTPStep запоминает интервал принятого кода, чтобы один и тот же код нельзя было использовать дважды.
+func (s *DBStorage) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	return useTOTPStep(ctx, s.pool, userID, step)
+}
+
+// execer — пул соединений или транзакция.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func useTOTPStep(ctx context.Context, db execer, userID int, step int64) error {
+	const query = `
+		UPDATE user_totp SET last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NOT NULL AND last_used_step < $2
+	`
+
+	tag, err := db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to use TOTP step: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrTOTPStepUsed
+	}
+
+	return nil
+}
+
+func (s *DBStorage) UseRecoveryCode(ctx context.Context, userID int, codeHash []byte) error {
+	const query = `
+		UPDATE recovery_codes SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	tag, err := s.pool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+func (s *DBStorage) AddLoginChallenge(ctx context.Context, challenge models.LoginChallenge) error {
+	const query = `INSERT INTO login_challenges (token_hash, user_id, expires_at) VALUES ($1, $2, $3)`
+
+	_, err := s.pool.Exec(ctx, query, challenge.TokenHash, challenge.UserID, challenge.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert login challenge: %w", err)
+	}
+
+	return nil
+}
+
+// UseLoginChallengeAttempt учитывает попытку ввода кода. Просроченные, завершенные и исчерпавшие
+// maxAttempts попыток запросы не находятся.
+func (s *DBStorage) UseLoginChallengeAttempt(ctx context.Context,
+	tokenHash []byte, maxAttempts int) (models.LoginChallenge, error) {
+	const query = `
+		UPDATE login_challenges SET attempts = attempts + 1
+		WHERE token_hash = $1 AND completed_at IS NULL AND expires_at > now() AND attempts < $2
+		RETURNING token_hash, user_id, attempts, expires_at
+	`
+
+	var c models.LoginChallenge
+
+	err := s.pool.QueryRow(ctx, query, tokenHash, maxAttempts).Scan(&c.TokenHash, &c.UserID, &c.Attempts, &c.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.LoginChallenge{}, ErrLoginChallengeNotFound
+		}
+
+		return models.LoginChallenge{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	return c, nil
+}
+
+func (s *DBStorage) CompleteLoginChallenge(ctx context.Context, tokenHash []byte) error {
+	const query = `
+		UPDATE login_challenges SET completed_at = now()
+		WHERE token_hash = $1 AND completed_at IS NULL AND expires_at > now()
+	`
+
+	tag, err := s.pool.Exec(ctx, query, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to complete login challenge: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrLoginChallengeNotFound
+	}
+
+	return nil
+}
+
+func (s *DBStorage) DeleteExpiredLoginChallenges(ctx context.Context) (int64, error) {
+	const query = `DELETE FROM login_challenges WHERE expires_at < now()`
+
+	tag, err := s.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired login challenges: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,205 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const userColumns = `id, login, COALESCE(email, ''), role, password, created_at, password_changed_at`
+
+func (s *DBStorage) GetCurrentUser(ctx context.Context) (models.User, error) {
+	const query = `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL LIMIT 1`
+
+	return scanUser(s.pool.QueryRow(ctx, query, ctx.Value(common.KeyUserID)))
+}
+
+// UpdatePassword меняет пароль текущего пользователя и отзывает все его сессии, кроме текущей.
+func (s *DBStorage) UpdatePassword(ctx context.Context, password []byte) error {
+	const updateQuery = `
+		UPDATE users SET (password, password_changed_at) = ($2, now())
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	const revokeQuery = `
+		UPDATE sessions SET revoked_at = now()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`
+
+	userID := ctx.Value(common.KeyUserID)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	tag, err := tx.Exec(ctx, updateQuery, userID, password)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	if _, err := tx.Exec(ctx, revokeQuery, userID, ctx.Value(common.KeySessionID)); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return nil
+}
+
+// RehashPassword заменяет хеш пароля, если он не изменился с момента проверки.
+func (s *DBStorage) RehashPassword(ctx context.Context, userID int, oldHash []byte, newHash []byte) error {
+	const query = `UPDATE users SET password = $3 WHERE id = $1 AND password = $2 AND deleted_at IS NULL`
+
+	if _, err := s.pool.Exec(ctx, query, userID, oldHash, newHash); err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
+	}
+
+	return nil
+}
+
+// AnonymizeUser удаляет учетные данные текущего пользователя, включая почту, второй фактор и подписки
+// на вебхуки вместе с журналом доставок, и отзывает все его сессии. Заказы, списания и записи журнала баллов остаются, чтобы не нарушать целостность журнала.
+func (s *DBStorage) AnonymizeUser(ctx context.Context) error {
+	// В логине используются символы, недопустимые при регистрации, поэтому он не займет чужой логин.
+	const anonymizeQuery = `
+		UPDATE users SET (login, email, password, deleted_at) = ('#deleted:' || id, NULL, '', now())
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	const revokeQuery = `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+	const idempotencyQuery = `DELETE FROM idempotency_keys WHERE user_id = $1`
+	const totpQuery = `DELETE FROM user_totp WHERE user_id = $1`
+	const recoveryCodesQuery = `DELETE FROM recovery_codes WHERE user_id = $1`
+	const resetTokensQuery = `DELETE FROM password_reset_tokens WHERE user_id = $1`
+	// Доставки удаляются каскадно вместе с подписками.
+	const webhooksQuery = `DELETE FROM webhooks WHERE user_id = $1`
+
+	userID := ctx.Value(common.KeyUserID)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	tag, err := tx.Exec(ctx, anonymizeQuery, userID)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	if _, err := tx.Exec(ctx, revokeQuery, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, idempotencyQuery, userID); err != nil {
+		return fmt.Errorf("failed to delete idempotency keys: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, totpQuery, userID); err != nil {
+		return fmt.Errorf("failed to delete TOTP: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, recoveryCodesQuery, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, resetTokensQuery, userID); err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, webhooksQuery, userID); err != nil {
+		return fmt.Errorf("failed to delete webhooks: %w", err)
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return nil
+}
+
+func scanUser(row pgx.Row) (models.User, error) {
+	var u models.User
+
+	err := row.Scan(&u.ID, &u.Login, &u.Email, &u.Role, &u.Password, &u.CreatedAt, &u.PasswordChangedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, ErrUserNotFound
+		}
+
+		return models.User{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	return u, nil
+}
+
+// HasAdmin сообщает, есть ли среди действующих пользователей администратор.
+func (s *DBStorage) HasAdmin(ctx context.Context) (bool, error) {
+	const query = `SELECT EXISTS (SELECT 1 FROM users WHERE role = 'ADMIN' AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := s.pool.QueryRow(ctx, query).Scan(&exists); err != nil {
+		return false, fmt.Errorf(failedScanStr, err)
+	}
+
+	return exists, nil
+}
+
+// PromoteFirstAdmin назначает пользователя администратором, только если администраторов еще нет.
+// Блокировка не дает двум одновременным запускам назначить двух администраторов.
+func (s *DBStorage) PromoteFirstAdmin(ctx context.Context, userID int) error {
+	const lockQuery = `SELECT pg_advisory_xact_lock(hashtext('promote_first_admin'))`
+	const existsQuery = `SELECT EXISTS (SELECT 1 FROM users WHERE role = 'ADMIN' AND deleted_at IS NULL)`
+	const promoteQuery = `UPDATE users SET role = 'ADMIN' WHERE id = $1 AND deleted_at IS NULL`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer rollbackTx(ctx, tx, s.logger)
+
+	if _, err := tx.Exec(ctx, lockQuery); err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	var exists bool
+	if err := tx.QueryRow(ctx, existsQuery).Scan(&exists); err != nil {
+		return fmt.Errorf(failedScanStr, err)
+	}
+
+	if exists {
+		return ErrAdminExists
+	}
+
+	tag, err := tx.Exec(ctx, promoteQuery, userID)
+	if err != nil {
+		return fmt.Errorf("failed to promote user: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	cErr := tx.Commit(ctx)
+	if cErr != nil {
+		return fmt.Errorf("failed to commit transaction: %w", cErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymizeUserKeepsHistory(t *testing.T) {
+	s := newTestStorage(t)
+
+	ctx := newTestUser(t, s, models.Points(10000))
+	userID, ok := ctx.Value(common.KeyUserID).(int)
+	require.True(t, ok)
+
+	user, err := s.GetCurrentUser(ctx)
+	require.NoError(t, err)
+
+	w, err := s.AddWebhook(ctx, models.Webhook{
+		URL:        "https://partner.example/hooks",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{models.WebhookPointsWithdrawn},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.AddWithdraw(ctx, "2377225624", 500, 0))
+
+	require.NoError(t, s.AnonymizeUser(ctx))
+
+	webhooks, err := s.GetWebhooks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+
+	var deliveries int
+	require.NoError(t, s.pool.QueryRow(ctx,
+		`SELECT count(*) FROM webhook_deliveries WHERE webhook_id = $1`, w.ID).Scan(&deliveries))
+	assert.Zero(t, deliveries)
+
+	_, err = s.GetUserByID(ctx, userID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	_, err = s.GetUserByLogin(ctx, user.Login)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	orders, err := s.GetOrdersByUserID(ctx, models.OrderQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	entries, err := s.GetLedgerEntries(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	assert.ErrorIs(t, s.AnonymizeUser(ctx), ErrUserNotFound)
+}
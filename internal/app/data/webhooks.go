@@ -0,0 +1,265 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const webhookDeliveryColumns = `
+	d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_attempt_at,
+	COALESCE(d.response_status, 0), COALESCE(d.last_error, ''), d.delivered_at, d.created_at
+`
+
+func (s *DBStorage) AddWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error) {
+	const query = `
+		INSERT INTO webhooks (user_id, url, secret, event_types) VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	w.UserID, _ = ctx.Value(common.KeyUserID).(int)
+
+	row := s.pool.QueryRow(ctx, query, w.UserID, w.URL, w.Secret, w.EventTypes)
+	if err := row.Scan(&w.ID, &w.CreatedAt); err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	return w, nil
+}
+
+func (s *DBStorage) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	const query = `
+		SELECT id, url, event_types, created_at, user_id
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY id ASC
+	`
+
+	webhooks := []models.Webhook{}
+
+	rows, err := s.pool.Query(ctx, query, ctx.Value(common.KeyUserID))
+	if err != nil {
+		return []models.Webhook{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w models.Webhook
+		err = rows.Scan(&w.ID, &w.URL, &w.EventTypes, &w.CreatedAt, &w.UserID)
+		if err != nil {
+			return []models.Webhook{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		webhooks = append(webhooks, w)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return []models.Webhook{}, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook возвращает подписку текущего пользователя. Чужая подписка не отличается от несуществующей.
+func (s *DBStorage) GetWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	const query = `SELECT id, url, event_types, created_at, user_id FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	var w models.Webhook
+
+	row := s.pool.QueryRow(ctx, query, id, ctx.Value(common.KeyUserID))
+	if err := row.Scan(&w.ID, &w.URL, &w.EventTypes, &w.CreatedAt, &w.UserID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Webhook{}, fmt.Errorf("%w with ID: %d", ErrWebhookNotFound, id)
+		}
+		return models.Webhook{}, fmt.Errorf(failedScanStr, err)
+	}
+
+	return w, nil
+}
+
+// DeleteWebhook удаляет подписку текущего пользователя вместе с журналом ее доставок.
+func (s *DBStorage) DeleteWebhook(ctx context.Context, id int64) error {
+	const query = `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	tag, err := s.pool.Exec(ctx, query, id, ctx.Value(common.KeyUserID))
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w with ID: %d", ErrWebhookNotFound, id)
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries возвращает журнал доставок подписки текущего пользователя по возрастанию ID.
+func (s *DBStorage) GetWebhookDeliveries(ctx context.Context,
+	webhookID int64, afterID int64, limit int) ([]models.WebhookDelivery, error) {
+	const query = `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.user_id = $2 AND d.id > $3
+		ORDER BY d.id ASC
+		LIMIT $4
+	`
+
+	deliveries := []models.WebhookDelivery{}
+
+	rows, err := s.pool.Query(ctx, query, webhookID, ctx.Value(common.KeyUserID), afterID, limit)
+	if err != nil {
+		return []models.WebhookDelivery{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err = rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastAttemptAt, &d.ResponseStatus, &d.LastError, &d.DeliveredAt, &d.CreatedAt)
+		if err != nil {
+			return []models.WebhookDelivery{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return []models.WebhookDelivery{}, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayWebhookDeliveries возвращает неудачные доставки подписки текущего пользователя в очередь с обнуленным
+// счетчиком попыток. Результат последней попытки остается в журнале до следующей.
+func (s *DBStorage) ReplayWebhookDeliveries(ctx context.Context, webhookID int64) (int64, error) {
+	const query = `
+		UPDATE webhook_deliveries d SET (status, attempts, next_attempt_at) = ('PENDING', 0, now())
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.webhook_id = $1 AND w.user_id = $2 AND d.status = 'FAILED'
+	`
+
+	tag, err := s.pool.Exec(ctx, query, webhookID, ctx.Value(common.KeyUserID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay webhook deliveries: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ClaimWebhookDeliveries выбирает доставки, время попытки которых наступило, и откладывает их до leaseUntil,
+// чтобы другие экземпляры сервиса не отправили их повторно. Если экземпляр не сохранит результат попытки,
+// доставка повторится после leaseUntil.
+func (s *DBStorage) ClaimWebhookDeliveries(ctx context.Context,
+	limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error) {
+	const query = `
+		UPDATE webhook_deliveries d SET next_attempt_at = $2
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `, w.url, w.secret
+	`
+
+	deliveries := []models.WebhookDelivery{}
+
+	rows, err := s.pool.Query(ctx, query, limit, leaseUntil)
+	if err != nil {
+		return []models.WebhookDelivery{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err = rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastAttemptAt, &d.ResponseStatus, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.URL, &d.Secret)
+		if err != nil {
+			return []models.WebhookDelivery{}, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return []models.WebhookDelivery{}, fmt.Errorf("failed to read query: %w", rowsErr)
+	}
+
+	return deliveries, nil
+}
+
+// CompleteWebhookDelivery сохраняет результат попытки: доставка завершается успехом, откладывается
+// до следующей попытки или, если попытки закончились, считается неудачной.
+func (s *DBStorage) CompleteWebhookDelivery(ctx context.Context, a models.WebhookAttempt) error {
+	const query = `
+		UPDATE webhook_deliveries SET
+			attempts = attempts + 1,
+			last_attempt_at = now(),
+			response_status = NULLIF($2::int, 0),
+			last_error = NULLIF($3, ''),
+			status = CASE
+				WHEN $4 THEN 'DELIVERED'
+				WHEN $5::timestamptz IS NULL THEN 'FAILED'
+				ELSE 'PENDING'
+			END::webhook_delivery_status,
+			next_attempt_at = COALESCE($5, next_attempt_at),
+			delivered_at = CASE WHEN $4 THEN now() END
+		WHERE id = $1
+	`
+
+	var nextAttemptAt *time.Time
+	if !a.Delivered && !a.NextAttemptAt.IsZero() {
+		nextAttemptAt = &a.NextAttemptAt
+	}
+
+	_, err := s.pool.Exec(ctx, query, a.DeliveryID, a.ResponseStatus, a.Error, a.Delivered, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) DeleteExpiredWebhookDeliveries(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	const query = `DELETE FROM webhook_deliveries WHERE status = 'DELIVERED' AND delivered_at < $1`
+
+	tag, err := s.pool.Exec(ctx, query, deliveredBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired webhook deliveries: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// enqueueWebhookEvent ставит событие в очередь доставки всем подпискам пользователя на этот тип событий
+// в рамках транзакции, которая его породила, поэтому событие не теряется и не отправляется при откате.
+func enqueueWebhookEvent(ctx context.Context, tx pgx.Tx, userID int, eventType string, payload any) error {
+	const query = `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT id, $2, $3 FROM webhooks WHERE user_id = $1 AND $2 = ANY(event_types)
+	`
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, userID, eventType, body); err != nil {
+		return fmt.Errorf("failed to enqueue webhook event: %w", err)
+	}
+
+	return nil
+}
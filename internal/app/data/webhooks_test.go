@@ -0,0 +1,109 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	s := newTestStorage(t)
+
+	userCtx := newTestUser(t, s, 1000)
+	otherCtx := newTestUser(t, s, 0)
+
+	w, err := s.AddWebhook(userCtx, models.Webhook{
+		URL:        "https://partner.example/hooks",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{models.WebhookPointsWithdrawn},
+	})
+	require.NoError(t, err)
+
+	_, err = s.GetWebhook(otherCtx, w.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+
+	// Подписка только на списания: начисление по заказу не ставится в очередь.
+	number := strconv.FormatInt(time.Now().UnixNano(), 10)
+	_, _, err = s.AddOrder(userCtx, number)
+	require.NoError(t, err)
+	_, err = s.UpdateOrder(context.Background(), number, "PROCESSED", 100)
+	require.NoError(t, err)
+
+	require.NoError(t, s.AddWithdraw(userCtx, "2377225624", 500, 0))
+
+	deliveries, err := s.GetWebhookDeliveries(userCtx, w.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.WebhookPointsWithdrawn, deliveries[0].EventType)
+	assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+
+	var withdraw models.Withdraw
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &withdraw))
+	assert.Equal(t, "2377225624", withdraw.OrderNumber)
+	assert.Equal(t, models.Points(500), withdraw.Sum)
+	assert.True(t, withdraw.ProcessedAt.Equal(deliveries[0].CreatedAt))
+
+	other, err := s.GetWebhookDeliveries(otherCtx, w.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	claim := func() models.WebhookDelivery {
+		t.Helper()
+
+		claimed, err := s.ClaimWebhookDeliveries(context.Background(), 1000, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+
+		for _, d := range claimed {
+			if d.ID == deliveries[0].ID {
+				return d
+			}
+		}
+
+		t.Fatal("delivery has not been claimed")
+		return models.WebhookDelivery{}
+	}
+
+	d := claim()
+	assert.Equal(t, w.URL, d.URL)
+	assert.Equal(t, "0123456789abcdef", d.Secret)
+
+	require.NoError(t, s.CompleteWebhookDelivery(context.Background(), models.WebhookAttempt{
+		DeliveryID: d.ID, ResponseStatus: 503, Error: "unexpected status code: 503",
+	}))
+
+	deliveries, err = s.GetWebhookDeliveries(userCtx, w.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, 503, deliveries[0].ResponseStatus)
+
+	replayed, err := s.ReplayWebhookDeliveries(otherCtx, w.ID)
+	require.NoError(t, err)
+	assert.Zero(t, replayed)
+
+	replayed, err = s.ReplayWebhookDeliveries(userCtx, w.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), replayed)
+
+	d = claim()
+	assert.Zero(t, d.Attempts)
+
+	require.NoError(t, s.CompleteWebhookDelivery(context.Background(), models.WebhookAttempt{
+		DeliveryID: d.ID, ResponseStatus: 200, Delivered: true,
+	}))
+
+	deliveries, err = s.GetWebhookDeliveries(userCtx, w.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryDelivered, deliveries[0].Status)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+	assert.Empty(t, deliveries[0].LastError)
+
+	assert.ErrorIs(t, s.DeleteWebhook(otherCtx, w.ID), ErrWebhookNotFound)
+	require.NoError(t, s.DeleteWebhook(userCtx, w.ID))
+}
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer — сколько событий может ждать отправки клиенту, прежде чем подписка будет закрыта.
+const subscriberBuffer = 16
+
+type Listener interface {
+	ListenOrderUpdates(ctx context.Context, handle func(models.OrderUpdate)) error
+}
+
+// Broker получает смены статусов заказов из БД по одному соединению на экземпляр сервиса и раздает их
+// подпискам пользователей. Поэтому подписчик получает события независимо от того, какой экземпляр
+// обработал заказ.
+type Broker struct {
+	store       Listener
+	logger      *zap.Logger
+	subscribers map[int]map[chan models.OrderUpdate]struct{}
+	retryDelay  time.Duration
+	mu          sync.Mutex
+	closed      bool
+}
+
+func NewBroker(store Listener, logger *zap.Logger, retryDelay time.Duration) *Broker {
+	return &Broker{
+		store:       store,
+		logger:      logger,
+		subscribers: make(map[int]map[chan models.OrderUpdate]struct{}),
+		retryDelay:  retryDelay,
+	}
+}
+
+func (b *Broker) Start(ctx context.Context) {
+	go b.listen(ctx)
+}
+
+// Subscribe возвращает канал смен статусов заказов пользователя. Канал закрывается, когда отменен ctx,
+// подписчик не успевает читать события, прервалось получение уведомлений из БД или брокер остановлен.
+// Во всех случаях, кроме отмены ctx, клиенту стоит переподключиться и перечитать заказы.
+func (b *Broker) Subscribe(ctx context.Context, userID int) <-chan models.OrderUpdate {
+	ch := make(chan models.OrderUpdate, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch
+	}
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan models.OrderUpdate]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+
+	context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.unsubscribe(userID, ch)
+	})
+
+	return ch
+}
+
+// Close закрывает все подписки и больше не принимает новых, чтобы открытые потоки не задерживали остановку сервера.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.disconnectAll()
+}
+
+func (b *Broker) listen(ctx context.Context) {
+	for {
+		err := b.store.ListenOrderUpdates(ctx, b.publish)
+		if ctx.Err() != nil {
+			return
+		}
+
+		b.logger.Error("failed to listen order updates", zap.Error(err))
+
+		// Пока нет подписки, уведомления теряются, поэтому подписчики отключаются, чтобы перечитать заказы.
+		b.mu.Lock()
+		b.disconnectAll()
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.retryDelay):
+		}
+	}
+}
+
+func (b *Broker) publish(u models.OrderUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[u.UserID] {
+		select {
+		case ch <- u:
+		default:
+			b.logger.Info("order updates subscriber is too slow", zap.Int("user_id", u.UserID))
+			b.unsubscribe(u.UserID, ch)
+		}
+	}
+}
+
+// unsubscribe закрывает канал, если он еще подписан. Вызывается под мьютексом.
+func (b *Broker) unsubscribe(userID int, ch chan models.OrderUpdate) {
+	if _, ok := b.subscribers[userID][ch]; !ok {
+		return
+	}
+
+	delete(b.subscribers[userID], ch)
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+
+	close(ch)
+}
+
+// disconnectAll закрывает все подписки. Вызывается под мьютексом.
+func (b *Broker) disconnectAll() {
+	for userID, chans := range b.subscribers {
+		for ch := range chans {
+			close(ch)
+		}
+		delete(b.subscribers, userID)
+	}
+}
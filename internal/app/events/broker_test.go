@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const waitTimeout = time.Second
+
+// fakeListener выдает брокеру соединения из conns. Закрытие канала соединения имитирует его обрыв.
+type fakeListener struct {
+	conns chan chan models.OrderUpdate
+}
+
+func newFakeListener() *fakeListener {
+	return &fakeListener{conns: make(chan chan models.OrderUpdate)}
+}
+
+func (f *fakeListener) ListenOrderUpdates(ctx context.Context, handle func(models.OrderUpdate)) error {
+	var conn chan models.OrderUpdate
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case conn = <-f.conns:
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case u, ok := <-conn:
+			if !ok {
+				return errors.New("connection lost")
+			}
+			handle(u)
+		}
+	}
+}
+
+// connect передает брокеру новое соединение и ждет, пока брокер начнет его слушать.
+func (f *fakeListener) connect(t *testing.T) chan models.OrderUpdate {
+	t.Helper()
+
+	conn := make(chan models.OrderUpdate)
+
+	select {
+	case f.conns <- conn:
+	case <-time.After(waitTimeout):
+		t.Fatal("broker has not connected")
+	}
+
+	return conn
+}
+
+func receive(t *testing.T, ch <-chan models.OrderUpdate) (models.OrderUpdate, bool) {
+	t.Helper()
+
+	select {
+	case u, ok := <-ch:
+		return u, ok
+	case <-time.After(waitTimeout):
+		t.Fatal("timed out waiting for order update")
+		return models.OrderUpdate{}, false
+	}
+}
+
+func TestBrokerPublishesToUserSubscribers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := newFakeListener()
+	b := NewBroker(store, zap.NewNop(), time.Millisecond)
+	b.Start(ctx)
+	conn := store.connect(t)
+
+	first := b.Subscribe(ctx, 1)
+	second := b.Subscribe(ctx, 1)
+	other := b.Subscribe(ctx, 2)
+
+	u := models.OrderUpdate{Number: "12345678903", Status: "PROCESSED", Accrual: 500, UserID: 1}
+	conn <- u
+
+	res, ok := receive(t, first)
+	require.True(t, ok)
+	assert.Equal(t, u, res)
+
+	res, ok = receive(t, second)
+	require.True(t, ok)
+	assert.Equal(t, u, res)
+
+	assert.Empty(t, other)
+}
+
+func TestBrokerUnsubscribesOnContextDone(t *testing.T) {
+	b := NewBroker(newFakeListener(), zap.NewNop(), time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := b.Subscribe(ctx, 1)
+	cancel()
+
+	_, ok := receive(t, ch)
+	assert.False(t, ok)
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(newFakeListener(), zap.NewNop(), time.Millisecond)
+	ch := b.Subscribe(context.Background(), 1)
+
+	for range subscriberBuffer + 1 {
+		b.publish(models.OrderUpdate{Number: "12345678903", UserID: 1})
+	}
+
+	for range subscriberBuffer {
+		_, ok := receive(t, ch)
+		require.True(t, ok)
+	}
+
+	_, ok := receive(t, ch)
+	assert.False(t, ok)
+}
+
+func TestBrokerReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := newFakeListener()
+	b := NewBroker(store, zap.NewNop(), time.Millisecond)
+	b.Start(ctx)
+	conn := store.connect(t)
+
+	ch := b.Subscribe(ctx, 1)
+
+	// Пока соединение было разорвано, события могли потеряться, поэтому подписчики отключаются.
+	close(conn)
+
+	_, ok := receive(t, ch)
+	assert.False(t, ok)
+
+	conn = store.connect(t)
+	ch = b.Subscribe(ctx, 1)
+
+	u := models.OrderUpdate{Number: "12345678903", Status: "PROCESSED", UserID: 1}
+	conn <- u
+
+	res, ok := receive(t, ch)
+	require.True(t, ok)
+	assert.Equal(t, u, res)
+}
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker(newFakeListener(), zap.NewNop(), time.Millisecond)
+	ch := b.Subscribe(context.Background(), 1)
+
+	b.Close()
+
+	_, ok := receive(t, ch)
+	assert.False(t, ok)
+
+	_, ok = receive(t, b.Subscribe(context.Background(), 1))
+	assert.False(t, ok)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) FindUsers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := models.UserSearchRequest{Login: r.URL.Query().Get("login")}
+
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+
+		users, err := h.services.FindUsers(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrPaginationValidation) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to find users", zap.Error(err))
+			return
+		}
+
+		if len(users) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(users); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) GetUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := parseUserID(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.services.GetUserDetails(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, services.ErrUserNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to get user details", zap.Error(err))
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) AdjustBalance() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := parseUserID(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var req models.BalanceAdjustmentRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		res, err := h.services.AdjustBalance(r.Context(), userID, req)
+		if err != nil {
+			if errors.Is(err, services.ErrAdjustmentAmountValidation) ||
+				errors.Is(err, services.ErrReasonValidation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+
+			if errors.Is(err, services.ErrUserNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if errors.Is(err, services.ErrInsufficientFunds) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to adjust balance", zap.Error(err))
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func parseUserID(r *http.Request) (int, error) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse user ID: %w", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) RecheckOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.OrderOverrideRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		res, err := h.services.RecheckOrder(r.Context(), chi.URLParam(r, "number"), req)
+		h.writeOrderOverride(w, res, err, "failed to recheck order")
+	}
+}
+
+func (h *Handlers) ResetOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.OrderOverrideRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		res, err := h.services.ResetOrder(r.Context(), chi.URLParam(r, "number"), req)
+		h.writeOrderOverride(w, res, err, "failed to reset order")
+	}
+}
+
+func (h *Handlers) SetOrderStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.OrderStatusRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		res, err := h.services.SetOrderStatus(r.Context(), chi.URLParam(r, "number"), req)
+		h.writeOrderOverride(w, res, err, "failed to set order status")
+	}
+}
+
+func (h *Handlers) writeOrderOverride(w http.ResponseWriter, res models.OrderOverride, err error, errMsg string) {
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReasonValidation), errors.Is(err, services.ErrOrderStatusValidation):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case errors.Is(err, services.ErrOrderNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, services.ErrInsufficientFunds), errors.Is(err, services.ErrAccrualOrderUnregistered):
+			w.WriteHeader(http.StatusConflict)
+		case errors.Is(err, services.ErrAccrualUnavailable):
+			w.WriteHeader(http.StatusBadGateway)
+			h.logger.Error(errMsg, zap.Error(err))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(errMsg, zap.Error(err))
+		}
+		return
+	}
+
+	w.Header().Set(ContentTypeHeader, JSONContentType)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.logger.Error(encRespErrStr, zap.Error(err))
+		return
+	}
+}
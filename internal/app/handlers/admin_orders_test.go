@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSetOrderStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	requestBody := `{"status":"PROCESSED","accrual":7.5,"reason":"manual check"}`
+	requestObject := models.OrderStatusRequest{Status: "PROCESSED", Accrual: 750, Reason: "manual check"}
+	override := models.OrderOverride{
+		ID:           1,
+		OrderNumber:  "12345678903",
+		Action:       models.OrderActionSetStatus,
+		OldStatus:    "INVALID",
+		NewStatus:    "PROCESSED",
+		NewAccrual:   750,
+		BalanceDelta: 750,
+		LedgerID:     9,
+		Reason:       "manual check",
+		UserID:       2,
+		AdminID:      1,
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		err  error
+		name string
+		want want
+	}{
+		{
+			name: "set order status success",
+			want: want{
+				code: http.StatusOK,
+				body: `{"created_at":"0001-01-01T00:00:00Z","order":"12345678903","action":"SET_STATUS",` +
+					`"old_status":"INVALID","new_status":"PROCESSED","reason":"manual check","id":1,"ledger_id":9,` +
+					`"old_accrual":0,"new_accrual":7.5,"balance_delta":7.5,"user_id":2,"admin_id":1}` + "\n",
+			},
+		},
+		{
+			name: "set order status failed with ErrOrderStatusValidation",
+			err:  services.ErrOrderStatusValidation,
+			want: want{code: http.StatusUnprocessableEntity},
+		},
+		{
+			name: "set order status failed with ErrReasonValidation",
+			err:  services.ErrReasonValidation,
+			want: want{code: http.StatusUnprocessableEntity},
+		},
+		{
+			name: "set order status failed with ErrOrderNotFound",
+			err:  services.ErrOrderNotFound,
+			want: want{code: http.StatusNotFound},
+		},
+		{
+			name: "set order status failed with ErrInsufficientFunds",
+			err:  services.ErrInsufficientFunds,
+			want: want{code: http.StatusConflict},
+		},
+		{
+			name: "set order status failed with some error",
+			err:  errors.New("some error"),
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to set order status",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().SetOrderStatus(gomock.Any(), "12345678903", requestObject).Times(1).Return(override, test.err)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.err)).Times(test.want.errorLogTimes)
+
+			request := withOrderNumberParam(httptest.NewRequest(http.MethodPost,
+				"/api/admin/orders/12345678903/status", strings.NewReader(requestBody)), "12345678903")
+			w := httptest.NewRecorder()
+			handlers.SetOrderStatus()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(body))
+		})
+	}
+}
+
+func TestRecheckOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	requestObject := models.OrderOverrideRequest{Reason: "stuck order"}
+	errAccrual := errors.New("accrual system is unavailable: some server error")
+
+	type want struct {
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		err  error
+		name string
+		want want
+	}{
+		{
+			name: "recheck success",
+			want: want{code: http.StatusOK},
+		},
+		{
+			name: "order is not registered in accrual system",
+			err:  services.ErrAccrualOrderUnregistered,
+			want: want{code: http.StatusConflict},
+		},
+		{
+			name: "accrual system is unavailable",
+			err:  errors.Join(services.ErrAccrualUnavailable, errAccrual),
+			want: want{
+				code:          http.StatusBadGateway,
+				errorLogTimes: 1,
+				log:           "failed to recheck order",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().
+				RecheckOrder(gomock.Any(), "12345678903", requestObject).
+				Times(1).
+				Return(models.OrderOverride{}, test.err)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.err)).Times(test.want.errorLogTimes)
+
+			request := withOrderNumberParam(httptest.NewRequest(http.MethodPost,
+				"/api/admin/orders/12345678903/recheck", strings.NewReader(`{"reason":"stuck order"}`)), "12345678903")
+			w := httptest.NewRecorder()
+			handlers.RecheckOrder()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+		})
+	}
+}
+
+func TestResetOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	t.Run("reset success", func(t *testing.T) {
+		_ = s.EXPECT().
+			ResetOrder(gomock.Any(), "12345678903", models.OrderOverrideRequest{Reason: "wrongly invalid"}).
+			Times(1).
+			Return(models.OrderOverride{NewStatus: "NEW"}, nil)
+
+		request := withOrderNumberParam(httptest.NewRequest(http.MethodPost,
+			"/api/admin/orders/12345678903/reset", strings.NewReader(`{"reason":"wrongly invalid"}`)), "12345678903")
+		w := httptest.NewRecorder()
+		handlers.ResetOrder()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("failed to read request body", func(t *testing.T) {
+		_ = s.EXPECT().ResetOrder(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		_ = l.EXPECT().Error("failed to read request body", gomock.Any()).Times(1)
+
+		request := withOrderNumberParam(httptest.NewRequest(http.MethodPost,
+			"/api/admin/orders/12345678903/reset", strings.NewReader(`{"reason":`)), "12345678903")
+		w := httptest.NewRecorder()
+		handlers.ResetOrder()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func withOrderNumberParam(r *http.Request, number string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("number", number)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
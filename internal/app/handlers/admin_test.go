@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFindUsers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+
+	type serviceResponse struct {
+		err   error
+		res   []models.UserProfile
+		times int
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		serviceResponse serviceResponse
+		req             models.UserSearchRequest
+		want            want
+	}{
+		{
+			name:  "users found",
+			query: "?login=ali&limit=10",
+			req:   models.UserSearchRequest{Login: "ali", Limit: 10},
+			serviceResponse: serviceResponse{
+				res:   []models.UserProfile{{ID: 1, Login: "alice", Role: models.RoleUser}},
+				times: 1,
+			},
+			want: want{
+				code: http.StatusOK,
+				body: `[{"created_at":"0001-01-01T00:00:00Z","login":"alice","role":"USER","id":1}]` + "\n",
+			},
+		},
+		{
+			name:            "no users",
+			query:           "?login=nobody",
+			req:             models.UserSearchRequest{Login: "nobody"},
+			serviceResponse: serviceResponse{res: []models.UserProfile{}, times: 1},
+			want:            want{code: http.StatusNoContent},
+		},
+		{
+			name:  "invalid limit",
+			query: "?limit=abc",
+			want:  want{code: http.StatusBadRequest},
+		},
+		{
+			name:            "limit out of range",
+			query:           "?limit=1000",
+			req:             models.UserSearchRequest{Limit: 1000},
+			serviceResponse: serviceResponse{err: services.ErrPaginationValidation, times: 1},
+			want:            want{code: http.StatusBadRequest},
+		},
+		{
+			name:            "find users failed",
+			query:           "?login=ali",
+			req:             models.UserSearchRequest{Login: "ali"},
+			serviceResponse: serviceResponse{err: errSome, times: 1},
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to find users",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().
+				FindUsers(gomock.Any(), test.req).
+				Times(test.serviceResponse.times).
+				Return(test.serviceResponse.res, test.serviceResponse.err)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceResponse.err)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodGet, "/api/admin/users"+test.query, http.NoBody)
+			w := httptest.NewRecorder()
+			handlers.FindUsers()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(body))
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+	details := models.AdminUserDetails{
+		User:        models.UserProfile{ID: 2, Login: "bob", Role: models.RoleUser},
+		Balance:     models.Balance{Current: 50050, Withdrawn: 4200},
+		Orders:      []models.Order{},
+		Withdrawals: []models.Withdraw{},
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		err          error
+		name         string
+		id           string
+		serviceTimes int
+		want         want
+	}{
+		{
+			name:         "get user success",
+			id:           "2",
+			serviceTimes: 1,
+			want: want{
+				code: http.StatusOK,
+				body: `{"user":{"created_at":"0001-01-01T00:00:00Z","login":"bob","role":"USER","id":2},` +
+					`"orders":[],"withdrawals":[],"balance":{"current":500.5,"withdrawn":42}}` + "\n",
+			},
+		},
+		{
+			name: "invalid user ID",
+			id:   "abc",
+			want: want{code: http.StatusBadRequest},
+		},
+		{
+			name:         "user not found",
+			id:           "2",
+			err:          services.ErrUserNotFound,
+			serviceTimes: 1,
+			want:         want{code: http.StatusNotFound},
+		},
+		{
+			name:         "get user failed",
+			id:           "2",
+			err:          errSome,
+			serviceTimes: 1,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to get user details",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().GetUserDetails(gomock.Any(), 2).Times(test.serviceTimes).Return(details, test.err)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.err)).Times(test.want.errorLogTimes)
+
+			request := withUserIDParam(httptest.NewRequest(http.MethodGet, "/api/admin/users/"+test.id, http.NoBody), test.id)
+			w := httptest.NewRecorder()
+			handlers.GetUser()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(body))
+		})
+	}
+}
+
+func TestAdjustBalance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	requestBody := `{"amount":-10.5,"reason":"duplicate accrual"}`
+	requestObject := models.BalanceAdjustmentRequest{Amount: -1050, Reason: "duplicate accrual"}
+	adjustment := models.BalanceAdjustment{
+		ID: 1, LedgerID: 7, UserID: 2, AdminID: 1, Amount: -1050, BalanceAfter: 8950, Reason: "duplicate accrual",
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		err  error
+		name string
+		want want
+	}{
+		{
+			name: "adjust balance success",
+			want: want{
+				code: http.StatusOK,
+				body: `{"created_at":"0001-01-01T00:00:00Z","reason":"duplicate accrual","id":1,"ledger_id":7,` +
+					`"amount":-10.5,"balance_after":89.5,"user_id":2,"admin_id":1}` + "\n",
+			},
+		},
+		{
+			name: "adjust balance failed with ErrReasonValidation",
+			err:  services.ErrReasonValidation,
+			want: want{code: http.StatusUnprocessableEntity},
+		},
+		{
+			name: "adjust balance failed with ErrAdjustmentAmountValidation",
+			err:  services.ErrAdjustmentAmountValidation,
+			want: want{code: http.StatusUnprocessableEntity},
+		},
+		{
+			name: "adjust balance failed with ErrUserNotFound",
+			err:  services.ErrUserNotFound,
+			want: want{code: http.StatusNotFound},
+		},
+		{
+			name: "adjust balance failed with ErrInsufficientFunds",
+			err:  services.ErrInsufficientFunds,
+			want: want{code: http.StatusConflict},
+		},
+		{
+			name: "adjust balance failed with some error",
+			err:  errors.New("some error"),
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to adjust balance",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().AdjustBalance(gomock.Any(), 2, requestObject).Times(1).Return(adjustment, test.err)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.err)).Times(test.want.errorLogTimes)
+
+			request := withUserIDParam(httptest.NewRequest(http.MethodPost,
+				"/api/admin/users/2/adjustments", strings.NewReader(requestBody)), "2")
+			w := httptest.NewRecorder()
+			handlers.AdjustBalance()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(body))
+		})
+	}
+}
+
+func TestFailedReadBodyAdjustBalance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	_ = s.EXPECT().AdjustBalance(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	_ = l.EXPECT().Error("failed to read request body", gomock.Any()).Times(1)
+
+	request := withUserIDParam(httptest.NewRequest(http.MethodPost,
+		"/api/admin/users/2/adjustments", strings.NewReader(`{"amount":10.555,"reason":"x"}`)), "2")
+	w := httptest.NewRecorder()
+	handlers.AdjustBalance()(w, request)
+
+	res := w.Result()
+	defer closeBody(t, res)
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func withUserIDParam(r *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) GetAuditEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseAuditQuery(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		events, err := h.services.GetAuditEvents(r.Context(), q)
+		if err != nil {
+			if errors.Is(err, services.ErrPaginationValidation) || errors.Is(err, services.ErrAuditRangeValidation) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to get audit events", zap.Error(err))
+			return
+		}
+
+		if len(events) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(events); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func parseAuditQuery(r *http.Request) (models.AuditQuery, error) {
+	req, err := parseBalanceHistoryRequest(r)
+	if err != nil {
+		return models.AuditQuery{}, err
+	}
+
+	q := models.AuditQuery{Limit: req.Limit, AfterID: req.AfterID}
+	query := r.URL.Query()
+
+	if v := query.Get("user_id"); v != "" {
+		userID, err := strconv.Atoi(v)
+		if err != nil {
+			return q, fmt.Errorf("failed to parse user ID: %w", err)
+		}
+		q.UserID = userID
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return q, fmt.Errorf("failed to parse from: %w", err)
+		}
+		q.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return q, fmt.Errorf("failed to parse to: %w", err)
+		}
+		q.To = to
+	}
+
+	return q, nil
+}
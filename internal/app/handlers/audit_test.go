@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetAuditEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	type serviceResponse struct {
+		err   error
+		res   []models.AuditEvent
+		times int
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		serviceResponse serviceResponse
+		req             models.AuditQuery
+		want            want
+	}{
+		{
+			name:  "events found",
+			query: "?user_id=2&from=2024-05-01T00:00:00Z&to=2024-05-02T00:00:00Z&after=10&limit=5",
+			req:   models.AuditQuery{UserID: 2, From: from, To: to, AfterID: 10, Limit: 5},
+			serviceResponse: serviceResponse{
+				res: []models.AuditEvent{{
+					ID:         11,
+					ActorID:    1,
+					UserID:     2,
+					Action:     models.AuditPasswordChanged,
+					TargetType: models.AuditTargetUser,
+					TargetID:   "2",
+					RequestID:  "req-1",
+					IP:         "192.0.2.1",
+					CreatedAt:  from,
+				}},
+				times: 1,
+			},
+			want: want{
+				code: http.StatusOK,
+				body: `[{"created_at":"2024-05-01T00:00:00Z","action":"PASSWORD_CHANGED","target_type":"USER",` +
+					`"target_id":"2","request_id":"req-1","ip":"192.0.2.1","id":11,"actor_id":1,"user_id":2}]` + "\n",
+			},
+		},
+		{
+			name:            "no events",
+			serviceResponse: serviceResponse{res: []models.AuditEvent{}, times: 1},
+			want:            want{code: http.StatusNoContent},
+		},
+		{
+			name:  "invalid user ID",
+			query: "?user_id=abc",
+			want:  want{code: http.StatusBadRequest},
+		},
+		{
+			name:  "invalid time",
+			query: "?from=yesterday",
+			want:  want{code: http.StatusBadRequest},
+		},
+		{
+			name:            "invalid range",
+			query:           "?from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z",
+			req:             models.AuditQuery{From: to, To: from},
+			serviceResponse: serviceResponse{err: services.ErrAuditRangeValidation, times: 1},
+			want:            want{code: http.StatusBadRequest},
+		},
+		{
+			name:            "get events failed",
+			serviceResponse: serviceResponse{err: errSome, times: 1},
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to get audit events",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().
+				GetAuditEvents(gomock.Any(), test.req).
+				Times(test.serviceResponse.times).
+				Return(test.serviceResponse.res, test.serviceResponse.err)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceResponse.err)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodGet, "/api/admin/audit"+test.query, http.NoBody)
+			w := httptest.NewRecorder()
+			handlers.GetAuditEvents()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(body))
+		})
+	}
+}
@@ -2,8 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
 	"go.uber.org/zap"
 )
 
@@ -27,3 +32,63 @@ func (h *Handlers) GetBalance() http.HandlerFunc {
 		}
 	}
 }
+
+func (h *Handlers) GetBalanceHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseBalanceHistoryRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		entries, err := h.services.GetBalanceHistory(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrPaginationValidation) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to get balance history", zap.Error(err))
+			return
+		}
+
+		if len(entries) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(entries); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func parseBalanceHistoryRequest(r *http.Request) (models.BalanceHistoryRequest, error) {
+	var req models.BalanceHistoryRequest
+	query := r.URL.Query()
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse limit: %w", err)
+		}
+		req.Limit = limit
+	}
+
+	if v := query.Get("after"); v != "" {
+		afterID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse cursor: %w", err)
+		}
+		req.AfterID = afterID
+	}
+
+	return req, nil
+}
@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,7 +25,7 @@ func TestGetBalance(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	errSome := errors.New("some error")
 
@@ -47,8 +51,8 @@ func TestGetBalance(t *testing.T) {
 			name: "get balance success",
 			serviceResponse: serviceResponse{
 				res: models.Balance{
-					Current:   100.22,
-					Withdrawn: 100.22,
+					Current:   10022,
+					Withdrawn: 10022,
 				},
 				err: nil,
 			},
@@ -97,3 +101,133 @@ func TestGetBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBalanceHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+	createdAt := time.Now()
+
+	type serviceResponse struct {
+		res   []models.LedgerEntry
+		err   error
+		times int
+	}
+
+	type want struct {
+		code          int
+		contentType   string
+		body          string
+		errorLogTimes int
+		log           string
+	}
+
+	tests := []struct {
+		name            string
+		target          string
+		req             models.BalanceHistoryRequest
+		serviceResponse serviceResponse
+		want            want
+	}{
+		{
+			name:   "get balance history success",
+			target: "/api/user/balance/history?limit=10&after=5",
+			req:    models.BalanceHistoryRequest{AfterID: 5, Limit: 10},
+			serviceResponse: serviceResponse{
+				res: []models.LedgerEntry{
+					{
+						ID:           6,
+						Kind:         models.LedgerKindWithdrawal,
+						Amount:       -10022,
+						BalanceAfter: 39978,
+						OrderNumber:  "12345678",
+						CreatedAt:    createdAt,
+					},
+				},
+				times: 1,
+			},
+			want: want{
+				code:        http.StatusOK,
+				contentType: JSONContentType,
+				body: fmt.Sprintf(
+					"[{\"created_at\":%q,\"kind\":\"WITHDRAWAL\",\"order\":\"12345678\","+
+						"\"id\":6,\"amount\":-100.22,\"balance_after\":399.78}]\n",
+					createdAt.Format(time.RFC3339Nano)),
+			},
+		},
+		{
+			name:   "get empty balance history",
+			target: "/api/user/balance/history",
+			req:    models.BalanceHistoryRequest{},
+			serviceResponse: serviceResponse{
+				res:   []models.LedgerEntry{},
+				times: 1,
+			},
+			want: want{
+				code: http.StatusNoContent,
+			},
+		},
+		{
+			name:   "invalid pagination params",
+			target: "/api/user/balance/history?limit=1000",
+			req:    models.BalanceHistoryRequest{Limit: 1000},
+			serviceResponse: serviceResponse{
+				err:   services.ErrPaginationValidation,
+				times: 1,
+			},
+			want: want{
+				code: http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "malformed pagination params",
+			target: "/api/user/balance/history?after=abc",
+			want: want{
+				code: http.StatusBadRequest,
+			},
+		},
+		{
+			name:   "get balance history failed",
+			target: "/api/user/balance/history",
+			req:    models.BalanceHistoryRequest{},
+			serviceResponse: serviceResponse{
+				err:   errSome,
+				times: 1,
+			},
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to get balance history",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().
+				GetBalanceHistory(gomock.Any(), test.req).
+				Times(test.serviceResponse.times).
+				Return(test.serviceResponse.res, test.serviceResponse.err)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(errSome)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodGet, test.target, http.NoBody)
+			w := httptest.NewRecorder()
+			handlers.GetBalanceHistory()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+			assert.Equal(t, test.want.contentType, res.Header.Get(ContentTypeHeader))
+
+			resBody, err := io.ReadAll(res.Body)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(resBody))
+		})
+	}
+}
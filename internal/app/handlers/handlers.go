@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"go.uber.org/zap/zapcore"
 )
@@ -12,16 +13,54 @@ var (
 	readReqErrStr     = "failed to read request body"
 	ContentTypeHeader = "Content-Type"
 	JSONContentType   = "application/json"
+	EventStreamType   = "text/event-stream"
+	RetryAfterHeader  = "Retry-After"
+	LinkHeader        = "Link"
+	NextCursorHeader  = "X-Next-Cursor"
+	AuthTokenCookie   = "AUTH_TOKEN"
+	AuthCookiePath    = "/"
+	RefreshCookie     = "REFRESH_TOKEN"
+	RefreshCookiePath = "/api/user/token"
 )
 
+// MaxOrderBatchBodySize ограничивает тело пакетной загрузки: тысяча номеров с разметкой заметно меньше.
+const MaxOrderBatchBodySize = 1 << 20
+
 type Servicer interface {
 	RegisterUser(ctx context.Context, req models.RegisterUserRequest) (models.RegisterUserResponse, error)
 	LoginUser(ctx context.Context, req models.LoginUserRequest) (models.LoginUserResponse, error)
+	CompleteTwoFactorLogin(ctx context.Context, req models.TwoFactorLoginRequest) (models.LoginUserResponse, error)
+	SetupTwoFactor(ctx context.Context) (models.TwoFactorSetupResponse, error)
+	ConfirmTwoFactor(ctx context.Context, req models.TwoFactorCodeRequest) error
+	GetCurrentUser(ctx context.Context) (models.UserProfile, error)
+	ChangePassword(ctx context.Context, req models.ChangePasswordRequest) error
+	DeleteUser(ctx context.Context) error
+	RequestPasswordReset(ctx context.Context, req models.PasswordResetRequest) error
+	ResetPassword(ctx context.Context, req models.ResetPasswordRequest) error
+	RefreshToken(ctx context.Context, refreshToken string) (models.RefreshTokenResponse, error)
+	Logout(ctx context.Context) error
+	GetJWKS() models.JWKSet
 	AddOrder(ctx context.Context, number string) error
-	GetOrders(ctx context.Context) ([]models.Order, error)
+	AddOrders(ctx context.Context, numbers []string) ([]models.OrderUploadResult, error)
+	GetOrders(ctx context.Context, req models.OrdersRequest) (models.OrdersPage, error)
+	GetOrder(ctx context.Context, number string) (models.OrderDetails, error)
+	SubscribeOrderUpdates(ctx context.Context) (<-chan models.OrderUpdate, error)
 	GetWithdrawals(ctx context.Context) ([]models.Withdraw, error)
 	AddWithdraw(ctx context.Context, req models.AddWithdrawRequest) error
 	GetBalance(ctx context.Context) (models.Balance, error)
+	GetBalanceHistory(ctx context.Context, req models.BalanceHistoryRequest) ([]models.LedgerEntry, error)
+	FindUsers(ctx context.Context, req models.UserSearchRequest) ([]models.UserProfile, error)
+	GetUserDetails(ctx context.Context, userID int) (models.AdminUserDetails, error)
+	AdjustBalance(ctx context.Context, userID int, req models.BalanceAdjustmentRequest) (models.BalanceAdjustment, error)
+	RecheckOrder(ctx context.Context, number string, req models.OrderOverrideRequest) (models.OrderOverride, error)
+	ResetOrder(ctx context.Context, number string, req models.OrderOverrideRequest) (models.OrderOverride, error)
+	SetOrderStatus(ctx context.Context, number string, req models.OrderStatusRequest) (models.OrderOverride, error)
+	GetAuditEvents(ctx context.Context, q models.AuditQuery) ([]models.AuditEvent, error)
+	CreateWebhook(ctx context.Context, req models.WebhookRequest) (models.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	GetWebhookDeliveries(ctx context.Context, req models.WebhookDeliveriesRequest) ([]models.WebhookDelivery, error)
+	ReplayWebhookDeliveries(ctx context.Context, id int64) (models.WebhookReplayResponse, error)
 	Ping(ctx context.Context) error
 }
 
@@ -32,11 +71,13 @@ type Logger interface {
 type Handlers struct {
 	services Servicer
 	logger   Logger
+	settings *config.Settings
 }
 
-func NewHandlers(services Servicer, logger Logger) *Handlers {
+func NewHandlers(services Servicer, logger Logger, settings *config.Settings) *Handlers {
 	return &Handlers{
 		services: services,
 		logger:   logger,
+		settings: settings,
 	}
 }
@@ -50,6 +50,21 @@ func (mr *MockServicerMockRecorder) AddOrder(ctx, number interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockServicer)(nil).AddOrder), ctx, number)
 }
 
+// AddOrders mocks base method.
+func (m *MockServicer) AddOrders(ctx context.Context, numbers []string) ([]models.OrderUploadResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrders", ctx, numbers)
+	ret0, _ := ret[0].([]models.OrderUploadResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOrders indicates an expected call of AddOrders.
+func (mr *MockServicerMockRecorder) AddOrders(ctx, numbers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrders", reflect.TypeOf((*MockServicer)(nil).AddOrders), ctx, numbers)
+}
+
 // AddWithdraw mocks base method.
 func (m *MockServicer) AddWithdraw(ctx context.Context, req models.AddWithdrawRequest) error {
 	m.ctrl.T.Helper()
@@ -64,6 +79,137 @@ func (mr *MockServicerMockRecorder) AddWithdraw(ctx, req interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithdraw", reflect.TypeOf((*MockServicer)(nil).AddWithdraw), ctx, req)
 }
 
+// AdjustBalance mocks base method.
+func (m *MockServicer) AdjustBalance(ctx context.Context, userID int, req models.BalanceAdjustmentRequest) (models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, userID, req)
+	ret0, _ := ret[0].(models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockServicerMockRecorder) AdjustBalance(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockServicer)(nil).AdjustBalance), ctx, userID, req)
+}
+
+// ChangePassword mocks base method.
+func (m *MockServicer) ChangePassword(ctx context.Context, req models.ChangePasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockServicerMockRecorder) ChangePassword(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockServicer)(nil).ChangePassword), ctx, req)
+}
+
+// CompleteTwoFactorLogin mocks base method.
+func (m *MockServicer) CompleteTwoFactorLogin(ctx context.Context, req models.TwoFactorLoginRequest) (models.LoginUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactorLogin", ctx, req)
+	ret0, _ := ret[0].(models.LoginUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTwoFactorLogin indicates an expected call of CompleteTwoFactorLogin.
+func (mr *MockServicerMockRecorder) CompleteTwoFactorLogin(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactorLogin", reflect.TypeOf((*MockServicer)(nil).CompleteTwoFactorLogin), ctx, req)
+}
+
+// ConfirmTwoFactor mocks base method.
+func (m *MockServicer) ConfirmTwoFactor(ctx context.Context, req models.TwoFactorCodeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTwoFactor", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTwoFactor indicates an expected call of ConfirmTwoFactor.
+func (mr *MockServicerMockRecorder) ConfirmTwoFactor(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTwoFactor", reflect.TypeOf((*MockServicer)(nil).ConfirmTwoFactor), ctx, req)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockServicer) CreateWebhook(ctx context.Context, req models.WebhookRequest) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, req)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockServicerMockRecorder) CreateWebhook(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockServicer)(nil).CreateWebhook), ctx, req)
+}
+
+// DeleteUser mocks base method.
+func (m *MockServicer) DeleteUser(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockServicerMockRecorder) DeleteUser(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockServicer)(nil).DeleteUser), ctx)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockServicer) DeleteWebhook(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockServicerMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockServicer)(nil).DeleteWebhook), ctx, id)
+}
+
+// FindUsers mocks base method.
+func (m *MockServicer) FindUsers(ctx context.Context, req models.UserSearchRequest) ([]models.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsers", ctx, req)
+	ret0, _ := ret[0].([]models.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUsers indicates an expected call of FindUsers.
+func (mr *MockServicerMockRecorder) FindUsers(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsers", reflect.TypeOf((*MockServicer)(nil).FindUsers), ctx, req)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockServicer) GetAuditEvents(ctx context.Context, q models.AuditQuery) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, q)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockServicerMockRecorder) GetAuditEvents(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockServicer)(nil).GetAuditEvents), ctx, q)
+}
+
 // GetBalance mocks base method.
 func (m *MockServicer) GetBalance(ctx context.Context) (models.Balance, error) {
 	m.ctrl.T.Helper()
@@ -79,19 +225,123 @@ func (mr *MockServicerMockRecorder) GetBalance(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockServicer)(nil).GetBalance), ctx)
 }
 
+// GetBalanceHistory mocks base method.
+func (m *MockServicer) GetBalanceHistory(ctx context.Context, req models.BalanceHistoryRequest) ([]models.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceHistory", ctx, req)
+	ret0, _ := ret[0].([]models.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceHistory indicates an expected call of GetBalanceHistory.
+func (mr *MockServicerMockRecorder) GetBalanceHistory(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceHistory", reflect.TypeOf((*MockServicer)(nil).GetBalanceHistory), ctx, req)
+}
+
+// GetCurrentUser mocks base method.
+func (m *MockServicer) GetCurrentUser(ctx context.Context) (models.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentUser", ctx)
+	ret0, _ := ret[0].(models.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentUser indicates an expected call of GetCurrentUser.
+func (mr *MockServicerMockRecorder) GetCurrentUser(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentUser", reflect.TypeOf((*MockServicer)(nil).GetCurrentUser), ctx)
+}
+
+// GetJWKS mocks base method.
+func (m *MockServicer) GetJWKS() models.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS")
+	ret0, _ := ret[0].(models.JWKSet)
+	return ret0
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockServicerMockRecorder) GetJWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockServicer)(nil).GetJWKS))
+}
+
+// GetOrder mocks base method.
+func (m *MockServicer) GetOrder(ctx context.Context, number string) (models.OrderDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, number)
+	ret0, _ := ret[0].(models.OrderDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockServicerMockRecorder) GetOrder(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockServicer)(nil).GetOrder), ctx, number)
+}
+
 // GetOrders mocks base method.
-func (m *MockServicer) GetOrders(ctx context.Context) ([]models.Order, error) {
+func (m *MockServicer) GetOrders(ctx context.Context, req models.OrdersRequest) (models.OrdersPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrders", ctx)
-	ret0, _ := ret[0].([]models.Order)
+	ret := m.ctrl.Call(m, "GetOrders", ctx, req)
+	ret0, _ := ret[0].(models.OrdersPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrders indicates an expected call of GetOrders.
-func (mr *MockServicerMockRecorder) GetOrders(ctx interface{}) *gomock.Call {
+func (mr *MockServicerMockRecorder) GetOrders(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockServicer)(nil).GetOrders), ctx, req)
+}
+
+// GetUserDetails mocks base method.
+func (m *MockServicer) GetUserDetails(ctx context.Context, userID int) (models.AdminUserDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDetails", ctx, userID)
+	ret0, _ := ret[0].(models.AdminUserDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDetails indicates an expected call of GetUserDetails.
+func (mr *MockServicerMockRecorder) GetUserDetails(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDetails", reflect.TypeOf((*MockServicer)(nil).GetUserDetails), ctx, userID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockServicer) GetWebhookDeliveries(ctx context.Context, req models.WebhookDeliveriesRequest) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, req)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockServicerMockRecorder) GetWebhookDeliveries(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockServicer)(nil).GetWebhookDeliveries), ctx, req)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockServicer) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockServicerMockRecorder) GetWebhooks(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockServicer)(nil).GetOrders), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockServicer)(nil).GetWebhooks), ctx)
 }
 
 // GetWithdrawals mocks base method.
@@ -124,6 +374,20 @@ func (mr *MockServicerMockRecorder) LoginUser(ctx, req interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockServicer)(nil).LoginUser), ctx, req)
 }
 
+// Logout mocks base method.
+func (m *MockServicer) Logout(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockServicerMockRecorder) Logout(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockServicer)(nil).Logout), ctx)
+}
+
 // Ping mocks base method.
 func (m *MockServicer) Ping(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -138,6 +402,36 @@ func (mr *MockServicerMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockServicer)(nil).Ping), ctx)
 }
 
+// RecheckOrder mocks base method.
+func (m *MockServicer) RecheckOrder(ctx context.Context, number string, req models.OrderOverrideRequest) (models.OrderOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecheckOrder", ctx, number, req)
+	ret0, _ := ret[0].(models.OrderOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecheckOrder indicates an expected call of RecheckOrder.
+func (mr *MockServicerMockRecorder) RecheckOrder(ctx, number, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecheckOrder", reflect.TypeOf((*MockServicer)(nil).RecheckOrder), ctx, number, req)
+}
+
+// RefreshToken mocks base method.
+func (m *MockServicer) RefreshToken(ctx context.Context, refreshToken string) (models.RefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(models.RefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockServicerMockRecorder) RefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockServicer)(nil).RefreshToken), ctx, refreshToken)
+}
+
 // RegisterUser mocks base method.
 func (m *MockServicer) RegisterUser(ctx context.Context, req models.RegisterUserRequest) (models.RegisterUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -153,6 +447,109 @@ func (mr *MockServicerMockRecorder) RegisterUser(ctx, req interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockServicer)(nil).RegisterUser), ctx, req)
 }
 
+// ReplayWebhookDeliveries mocks base method.
+func (m *MockServicer) ReplayWebhookDeliveries(ctx context.Context, id int64) (models.WebhookReplayResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayWebhookDeliveries", ctx, id)
+	ret0, _ := ret[0].(models.WebhookReplayResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayWebhookDeliveries indicates an expected call of ReplayWebhookDeliveries.
+func (mr *MockServicerMockRecorder) ReplayWebhookDeliveries(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayWebhookDeliveries", reflect.TypeOf((*MockServicer)(nil).ReplayWebhookDeliveries), ctx, id)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockServicer) RequestPasswordReset(ctx context.Context, req models.PasswordResetRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockServicerMockRecorder) RequestPasswordReset(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockServicer)(nil).RequestPasswordReset), ctx, req)
+}
+
+// ResetOrder mocks base method.
+func (m *MockServicer) ResetOrder(ctx context.Context, number string, req models.OrderOverrideRequest) (models.OrderOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetOrder", ctx, number, req)
+	ret0, _ := ret[0].(models.OrderOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetOrder indicates an expected call of ResetOrder.
+func (mr *MockServicerMockRecorder) ResetOrder(ctx, number, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetOrder", reflect.TypeOf((*MockServicer)(nil).ResetOrder), ctx, number, req)
+}
+
+// ResetPassword mocks base method.
+func (m *MockServicer) ResetPassword(ctx context.Context, req models.ResetPasswordRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockServicerMockRecorder) ResetPassword(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockServicer)(nil).ResetPassword), ctx, req)
+}
+
+// SetOrderStatus mocks base method.
+func (m *MockServicer) SetOrderStatus(ctx context.Context, number string, req models.OrderStatusRequest) (models.OrderOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrderStatus", ctx, number, req)
+	ret0, _ := ret[0].(models.OrderOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrderStatus indicates an expected call of SetOrderStatus.
+func (mr *MockServicerMockRecorder) SetOrderStatus(ctx, number, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrderStatus", reflect.TypeOf((*MockServicer)(nil).SetOrderStatus), ctx, number, req)
+}
+
+// SetupTwoFactor mocks base method.
+func (m *MockServicer) SetupTwoFactor(ctx context.Context) (models.TwoFactorSetupResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetupTwoFactor", ctx)
+	ret0, _ := ret[0].(models.TwoFactorSetupResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetupTwoFactor indicates an expected call of SetupTwoFactor.
+func (mr *MockServicerMockRecorder) SetupTwoFactor(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupTwoFactor", reflect.TypeOf((*MockServicer)(nil).SetupTwoFactor), ctx)
+}
+
+// SubscribeOrderUpdates mocks base method.
+func (m *MockServicer) SubscribeOrderUpdates(ctx context.Context) (<-chan models.OrderUpdate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeOrderUpdates", ctx)
+	ret0, _ := ret[0].(<-chan models.OrderUpdate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeOrderUpdates indicates an expected call of SubscribeOrderUpdates.
+func (mr *MockServicerMockRecorder) SubscribeOrderUpdates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeOrderUpdates", reflect.TypeOf((*MockServicer)(nil).SubscribeOrderUpdates), ctx)
+}
+
 // MockLogger is a mock of Logger interface.
 type MockLogger struct {
 	ctrl     *gomock.Controller
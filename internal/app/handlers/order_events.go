@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// GetOrderEvents передает смены статусов заказов пользователя потоком Server-Sent Events. Каждое событие
+// order содержит заказ в формате списка заказов. Поток завершается, если подписка закрыта на стороне
+// сервера, в том числе по истечении токена или отзыву сессии, тогда клиент переподключается и перечитывает заказы.
+func (h *Handlers) GetOrderEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		updates, err := h.services.SubscribeOrderUpdates(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to subscribe order updates", zap.Error(err))
+			return
+		}
+
+		rc := http.NewResponseController(w)
+
+		w.Header().Set(ContentTypeHeader, EventStreamType)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if err := rc.Flush(); err != nil {
+			h.logger.Error("failed to flush event stream", zap.Error(err))
+			return
+		}
+
+		heartbeat := time.NewTicker(h.settings.OrderEvents.Heartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case u, ok := <-updates:
+				if !ok {
+					return
+				}
+
+				data, err := json.Marshal(u)
+				if err != nil {
+					h.logger.Error(encRespErrStr, zap.Error(err))
+					return
+				}
+
+				if _, err := fmt.Fprintf(w, "event: order\ndata: %s\n\n", data); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
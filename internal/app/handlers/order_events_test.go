@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetOrderEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{OrderEvents: config.OrderEventsSettings{Heartbeat: time.Hour}})
+
+	updatedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("streams updates until subscription is closed", func(t *testing.T) {
+		updates := make(chan models.OrderUpdate, 2)
+		updates <- models.OrderUpdate{Number: "12345678903", Status: "PROCESSING", UpdatedAt: updatedAt, UserID: 1}
+		updates <- models.OrderUpdate{Number: "12345678903", Status: "PROCESSED", Accrual: 500, UpdatedAt: updatedAt}
+		close(updates)
+
+		_ = s.EXPECT().SubscribeOrderUpdates(gomock.Any()).Times(1).Return(updates, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetOrderEvents()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, EventStreamType, res.Header.Get(ContentTypeHeader))
+		assert.Equal(t, "no-cache", res.Header.Get("Cache-Control"))
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"event: order\n"+
+				`data: {"updated_at":"2024-05-01T00:00:00Z","number":"12345678903","status":"PROCESSING"}`+"\n\n"+
+				"event: order\n"+
+				`data: {"updated_at":"2024-05-01T00:00:00Z","number":"12345678903","status":"PROCESSED",`+
+				`"accrual":5}`+"\n\n",
+			string(body))
+	})
+
+	t.Run("client disconnected", func(t *testing.T) {
+		_ = s.EXPECT().SubscribeOrderUpdates(gomock.Any()).Times(1).Return(make(chan models.OrderUpdate), nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", http.NoBody).WithContext(ctx)
+		w := httptest.NewRecorder()
+		handlers.GetOrderEvents()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("subscribe failed", func(t *testing.T) {
+		errSome := errors.New("some error")
+		_ = s.EXPECT().SubscribeOrderUpdates(gomock.Any()).Times(1).Return(nil, errSome)
+		_ = l.EXPECT().Error("failed to subscribe order updates", zap.Error(errSome)).Times(1)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetOrderEvents()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+}
@@ -3,10 +3,18 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
@@ -19,7 +27,7 @@ func (h *Handlers) AddOrder() http.HandlerFunc {
 			return
 		}
 
-		err = h.services.AddOrder(r.Context(), string(body))
+		err = h.services.AddOrder(r.Context(), strings.TrimSpace(string(body)))
 		if err != nil {
 			if errors.Is(err, services.ErrOrderNumberValidation) {
 				w.WriteHeader(http.StatusUnprocessableEntity)
@@ -45,28 +53,186 @@ func (h *Handlers) AddOrder() http.HandlerFunc {
 	}
 }
 
+func (h *Handlers) AddOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, MaxOrderBatchBodySize)
+
+		numbers, err := parseOrderNumbers(r)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		results, err := h.services.AddOrders(r.Context(), numbers)
+		if err != nil {
+			if errors.Is(err, services.ErrOrderBatchValidation) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to add orders", zap.Error(err))
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(results); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+// parseOrderNumbers читает номера из JSON-массива строк или, для остальных типов содержимого, из текста
+// по номеру в строке. Пробелы вокруг номеров отбрасываются, пустые строки в тексте пропускаются.
+func parseOrderNumbers(r *http.Request) ([]string, error) {
+	var numbers []string
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(ContentTypeHeader))
+	if mediaType == JSONContentType {
+		if err := json.NewDecoder(r.Body).Decode(&numbers); err != nil {
+			return nil, fmt.Errorf("failed to decode numbers: %w", err)
+		}
+
+		for i := range numbers {
+			numbers[i] = strings.TrimSpace(numbers[i])
+		}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+
+		numbers = []string{}
+		for _, line := range strings.Split(string(body), "\n") {
+			if number := strings.TrimSpace(line); number != "" {
+				numbers = append(numbers, number)
+			}
+		}
+	}
+
+	return numbers, nil
+}
+
 func (h *Handlers) GetOrders() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		orders, err := h.services.GetOrders(r.Context())
+		req, err := parseOrdersRequest(r)
 		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		page, err := h.services.GetOrders(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrPaginationValidation) || errors.Is(err, services.ErrOrderFilterValidation) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
 			w.WriteHeader(http.StatusInternalServerError)
 			h.logger.Error("failed to get orders", zap.Error(err))
 			return
 		}
 
-		if len(orders) == 0 {
+		if len(page.Orders) == 0 {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
+		if page.NextCursor != "" {
+			w.Header().Set(NextCursorHeader, page.NextCursor)
+			w.Header().Set(LinkHeader, fmt.Sprintf(`<%s>; rel="next"`, nextPageURL(r, page.NextCursor)))
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(page.Orders); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) GetOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.services.GetOrder(r.Context(), chi.URLParam(r, "number"))
+		if err != nil {
+			if errors.Is(err, services.ErrOrderNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to get order", zap.Error(err))
+			return
+		}
+
 		w.Header().Set(ContentTypeHeader, JSONContentType)
 		w.WriteHeader(http.StatusOK)
 
 		enc := json.NewEncoder(w)
-		if err := enc.Encode(orders); err != nil {
+		if err := enc.Encode(res); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			h.logger.Error(encRespErrStr, zap.Error(err))
 			return
 		}
 	}
 }
+
+func parseOrdersRequest(r *http.Request) (models.OrdersRequest, error) {
+	query := r.URL.Query()
+	req := models.OrdersRequest{
+		Status: query.Get("status"),
+		After:  query.Get("after"),
+		Sort:   query.Get("sort"),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse limit: %w", err)
+		}
+		req.Limit = &limit
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse from: %w", err)
+		}
+		req.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse to: %w", err)
+		}
+		req.To = to
+	}
+
+	return req, nil
+}
+
+// nextPageURL повторяет запрос с теми же фильтрами, заменяя курсор.
+func nextPageURL(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Set("after", cursor)
+
+	u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+
+	return u.String()
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/MihailSergeenkov/gophermart/internal/app/services"
@@ -25,7 +26,7 @@ func TestAddOrder(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	orderNumber := "12345678"
 
@@ -106,7 +107,7 @@ func TestAddOrder(t *testing.T) {
 			_ = s.EXPECT().AddOrder(gomock.Any(), orderNumber).Times(1).Return(test.serviceResponse.err)
 			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceResponse.err)).Times(test.want.errorLogTimes)
 
-			request := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(orderNumber))
+			request := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(" "+orderNumber+"\r\n"))
 			w := httptest.NewRecorder()
 			handlers.AddOrder()(w, request)
 
@@ -124,33 +125,39 @@ func TestGetOrders(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	errSome := errors.New("some error")
 	uploadedAt := time.Now()
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	limit := 1
 
 	type serviceResponse struct {
-		res []models.Order
-		err error
+		err   error
+		res   models.OrdersPage
+		times int
 	}
 
 	type want struct {
 		code          int
 		contentType   string
 		body          string
+		link          string
 		errorLogTimes int
 		log           string
 	}
 
 	tests := []struct {
 		name            string
+		query           string
+		req             models.OrdersRequest
 		serviceResponse serviceResponse
 		want            want
 	}{
 		{
 			name: "get orders success",
 			serviceResponse: serviceResponse{
-				res: []models.Order{
+				res: models.OrdersPage{Orders: []models.Order{
 					{
 						Number:     "12345678",
 						Status:     "NEW",
@@ -158,8 +165,8 @@ func TestGetOrders(t *testing.T) {
 						UploadedAt: uploadedAt,
 						UserID:     1,
 					},
-				},
-				err: nil,
+				}},
+				times: 1,
 			},
 			want: want{
 				code:        http.StatusOK,
@@ -167,45 +174,68 @@ func TestGetOrders(t *testing.T) {
 				body: fmt.Sprintf(
 					"[{\"uploaded_at\":%q,\"number\":\"12345678\",\"status\":\"NEW\"}]\n",
 					uploadedAt.Format(time.RFC3339Nano)),
-				errorLogTimes: 0,
-				log:           "",
 			},
 		},
 		{
-			name: "get orders failed",
+			name:  "page with next cursor",
+			query: "?status=PROCESSED&from=2024-05-01T00:00:00Z&sort=desc&limit=1",
+			req:   models.OrdersRequest{Status: "PROCESSED", From: from, Sort: models.SortDesc, Limit: &limit},
 			serviceResponse: serviceResponse{
-				res: []models.Order{},
-				err: errSome,
+				res: models.OrdersPage{
+					Orders:     []models.Order{{Number: "12345678", Status: "PROCESSED", UploadedAt: from}},
+					NextCursor: "next",
+				},
+				times: 1,
 			},
+			want: want{
+				code:        http.StatusOK,
+				contentType: JSONContentType,
+				body:        "[{\"uploaded_at\":\"2024-05-01T00:00:00Z\",\"number\":\"12345678\",\"status\":\"PROCESSED\"}]\n",
+				link: `</api/user/orders?after=next&from=2024-05-01T00%3A00%3A00Z&limit=1&sort=desc&status=PROCESSED>; ` +
+					`rel="next"`,
+			},
+		},
+		{
+			name:  "invalid limit",
+			query: "?limit=abc",
+			want:  want{code: http.StatusBadRequest},
+		},
+		{
+			name:  "invalid time",
+			query: "?to=tomorrow",
+			want:  want{code: http.StatusBadRequest},
+		},
+		{
+			name:            "invalid filter",
+			query:           "?status=DONE",
+			req:             models.OrdersRequest{Status: "DONE"},
+			serviceResponse: serviceResponse{err: services.ErrOrderFilterValidation, times: 1},
+			want:            want{code: http.StatusBadRequest},
+		},
+		{
+			name:            "get orders failed",
+			serviceResponse: serviceResponse{err: errSome, times: 1},
 			want: want{
 				code:          http.StatusInternalServerError,
-				contentType:   "",
-				body:          "",
 				errorLogTimes: 1,
 				log:           "failed to get orders",
 			},
 		},
 		{
-			name: "when no orders",
-			serviceResponse: serviceResponse{
-				res: []models.Order{},
-				err: nil,
-			},
-			want: want{
-				code:          http.StatusNoContent,
-				contentType:   "",
-				body:          "",
-				errorLogTimes: 0,
-				log:           "",
-			},
+			name:            "when no orders",
+			serviceResponse: serviceResponse{res: models.OrdersPage{Orders: []models.Order{}}, times: 1},
+			want:            want{code: http.StatusNoContent},
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_ = s.EXPECT().GetOrders(gomock.Any()).Times(1).Return(test.serviceResponse.res, test.serviceResponse.err)
+			_ = s.EXPECT().
+				GetOrders(gomock.Any(), test.req).
+				Times(test.serviceResponse.times).
+				Return(test.serviceResponse.res, test.serviceResponse.err)
 			_ = l.EXPECT().Error(test.want.log, zap.Error(errSome)).Times(test.want.errorLogTimes)
 
-			request := httptest.NewRequest(http.MethodGet, "/api/user/orders", http.NoBody)
+			request := httptest.NewRequest(http.MethodGet, "/api/user/orders"+test.query, http.NoBody)
 			w := httptest.NewRecorder()
 			handlers.GetOrders()(w, request)
 
@@ -214,6 +244,7 @@ func TestGetOrders(t *testing.T) {
 
 			assert.Equal(t, test.want.code, res.StatusCode)
 			assert.Equal(t, test.want.contentType, res.Header.Get(ContentTypeHeader))
+			assert.Equal(t, test.want.link, res.Header.Get(LinkHeader))
 
 			resBody, err := io.ReadAll(res.Body)
 
@@ -222,3 +253,199 @@ func TestGetOrders(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+	uploadedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	details := models.OrderDetails{
+		Order: models.Order{Number: "12345678903", Status: "PROCESSED", Accrual: 500, UploadedAt: uploadedAt},
+		History: []models.OrderStatusEvent{
+			{Status: "NEW", CreatedAt: uploadedAt},
+			{Status: "PROCESSED", Accrual: 500, CreatedAt: uploadedAt.Add(time.Minute)},
+		},
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		serviceErr error
+		name       string
+		want       want
+	}{
+		{
+			name: "order found",
+			want: want{
+				code: http.StatusOK,
+				body: `{"uploaded_at":"2024-05-01T00:00:00Z","number":"12345678903","status":"PROCESSED",` +
+					`"accrual":5,"history":[{"created_at":"2024-05-01T00:00:00Z","status":"NEW"},` +
+					`{"created_at":"2024-05-01T00:01:00Z","status":"PROCESSED","accrual":5}]}` + "\n",
+			},
+		},
+		{
+			name:       "order not found",
+			serviceErr: services.ErrOrderNotFound,
+			want:       want{code: http.StatusNotFound},
+		},
+		{
+			name:       "get order failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to get order",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().GetOrder(gomock.Any(), "12345678903").Times(1).Return(details, test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodGet, "/api/user/orders/12345678903", http.NoBody)
+			w := httptest.NewRecorder()
+			handlers.GetOrder()(w, withOrderNumberParam(request, "12345678903"))
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(body))
+		})
+	}
+}
+
+func TestAddOrders(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+	results := []models.OrderUploadResult{
+		{Number: "12345678903", Status: models.OrderUploadAccepted},
+		{Number: "12345", Status: models.OrderUploadInvalid},
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		serviceErr   error
+		name         string
+		contentType  string
+		body         string
+		numbers      []string
+		serviceTimes int
+		want         want
+	}{
+		{
+			name:         "json array",
+			contentType:  JSONContentType,
+			body:         `["12345678903","12345"]`,
+			numbers:      []string{"12345678903", "12345"},
+			serviceTimes: 1,
+			want: want{
+				code: http.StatusOK,
+				body: `[{"number":"12345678903","status":"ACCEPTED"},{"number":"12345","status":"INVALID_NUMBER"}]` + "\n",
+			},
+		},
+		{
+			name:         "newline-delimited text",
+			contentType:  "text/plain",
+			body:         "12345678903\r\n\n 12345 \n",
+			numbers:      []string{"12345678903", "12345"},
+			serviceTimes: 1,
+			want: want{
+				code: http.StatusOK,
+				body: `[{"number":"12345678903","status":"ACCEPTED"},{"number":"12345","status":"INVALID_NUMBER"}]` + "\n",
+			},
+		},
+		{
+			name:         "json array with spaces",
+			contentType:  JSONContentType,
+			body:         `[" 12345678903\t","12345 "]`,
+			numbers:      []string{"12345678903", "12345"},
+			serviceTimes: 1,
+			want: want{
+				code: http.StatusOK,
+				body: `[{"number":"12345678903","status":"ACCEPTED"},{"number":"12345","status":"INVALID_NUMBER"}]` + "\n",
+			},
+		},
+		{
+			name:        "malformed json",
+			contentType: JSONContentType,
+			body:        `[12345678903]`,
+			want:        want{code: http.StatusBadRequest},
+		},
+		{
+			name:        "body too large",
+			contentType: "text/plain",
+			body:        strings.Repeat("12345678903\n", MaxOrderBatchBodySize/12+1),
+			want:        want{code: http.StatusRequestEntityTooLarge},
+		},
+		{
+			name:         "empty batch",
+			contentType:  "text/plain",
+			numbers:      []string{},
+			serviceTimes: 1,
+			serviceErr:   services.ErrOrderBatchValidation,
+			want:         want{code: http.StatusBadRequest},
+		},
+		{
+			name:         "add orders failed",
+			contentType:  JSONContentType,
+			body:         `["12345678903"]`,
+			numbers:      []string{"12345678903"},
+			serviceTimes: 1,
+			serviceErr:   errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to add orders",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().AddOrders(gomock.Any(), test.numbers).Times(test.serviceTimes).Return(results, test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/orders/batch", strings.NewReader(test.body))
+			request.Header.Set(ContentTypeHeader, test.contentType)
+			w := httptest.NewRecorder()
+			handlers.AddOrders()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(body))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) RequestPasswordReset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.PasswordResetRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		if err := h.services.RequestPasswordReset(r.Context(), req); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to request password reset", zap.Error(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *Handlers) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.ResetPasswordRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		err := h.services.ResetPassword(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidResetToken) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			var validationErr *services.ValidationError
+			if errors.As(err, &validationErr) {
+				h.writeValidationError(w, validationErr.Violations)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to reset password", zap.Error(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
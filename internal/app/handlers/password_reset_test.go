@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRequestPasswordReset(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+
+	type want struct {
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		want       want
+	}{
+		{
+			name: "reset requested",
+			want: want{code: http.StatusAccepted},
+		},
+		{
+			name:       "reset request failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to request password reset",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().
+				RequestPasswordReset(gomock.Any(), models.PasswordResetRequest{Login: "test"}).
+				Times(1).
+				Return(test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/password/reset-request",
+				strings.NewReader(`{"login":"test"}`))
+			w := httptest.NewRecorder()
+			handlers.RequestPasswordReset()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	requestBody := `{"token":"token","new_password":"NewSecret1"}`
+	requestObject := models.ResetPasswordRequest{Token: "token", NewPassword: "NewSecret1"}
+	errSome := errors.New("some error")
+
+	type want struct {
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		want       want
+	}{
+		{
+			name: "reset success",
+			want: want{code: http.StatusOK},
+		},
+		{
+			name:       "reset with invalid token",
+			serviceErr: services.ErrInvalidResetToken,
+			want:       want{code: http.StatusUnauthorized},
+		},
+		{
+			name: "reset with weak password",
+			serviceErr: &services.ValidationError{
+				Violations: []models.Violation{{Field: "new_password", Rule: services.RuleMinLength}},
+			},
+			want: want{code: http.StatusBadRequest},
+		},
+		{
+			name:       "reset failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to reset password",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().ResetPassword(gomock.Any(), requestObject).Times(1).Return(test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/password/reset", strings.NewReader(requestBody))
+			w := httptest.NewRecorder()
+			handlers.ResetPassword()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+		})
+	}
+}
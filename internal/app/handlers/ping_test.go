@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -18,7 +19,7 @@ func TestSuccessPing(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	_ = s.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
 	_ = l.EXPECT().Error(gomock.Any(), gomock.Any()).Times(0)
@@ -41,7 +42,7 @@ func TestFailedPing(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	errSome := errors.New("some error")
 	_ = s.EXPECT().Ping(gomock.Any()).Times(1).Return(errSome)
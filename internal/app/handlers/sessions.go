@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) RefreshToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		refreshToken, err := refreshTokenFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		if refreshToken == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		res, err := h.services.RefreshToken(r.Context(), refreshToken)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidRefreshToken) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to refresh token", zap.Error(err))
+			return
+		}
+
+		h.setAuthCookies(w, res.AuthToken, res.RefreshToken)
+		h.writeAuthToken(w, res)
+	}
+}
+
+// refreshTokenFromRequest берет refresh токен из тела запроса {"refresh_token": "..."}, а если его там нет —
+// из cookie. Пустая строка означает, что токен не передан.
+func refreshTokenFromRequest(r *http.Request) (string, error) {
+	var req models.RefreshTokenRequest
+
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to decode refresh token request: %w", err)
+	}
+
+	if req.RefreshToken != "" {
+		return req.RefreshToken, nil
+	}
+
+	if cookie, err := r.Cookie(RefreshCookie); err == nil {
+		return cookie.Value, nil
+	}
+
+	return "", nil
+}
+
+func (h *Handlers) Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.services.Logout(r.Context()); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to logout", zap.Error(err))
+			return
+		}
+
+		h.clearAuthCookies(w)
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *Handlers) GetJWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(h.services.GetJWKS()); err != nil {
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) setAuthCookies(w http.ResponseWriter, authToken string, refreshToken string) {
+	http.SetCookie(w, h.authCookie(AuthTokenCookie, authToken, AuthCookiePath, h.settings.Auth.AccessTokenTTL))
+	http.SetCookie(w, h.authCookie(RefreshCookie, refreshToken, RefreshCookiePath, h.settings.Auth.RefreshTokenTTL))
+}
+
+func (h *Handlers) clearAuthCookies(w http.ResponseWriter) {
+	http.SetCookie(w, h.authCookie(AuthTokenCookie, "", AuthCookiePath, -1))
+	http.SetCookie(w, h.authCookie(RefreshCookie, "", RefreshCookiePath, -1))
+}
+
+// writeAuthToken отдает токены в теле ответа для клиентов, которые не работают с cookie.
+func (h *Handlers) writeAuthToken(w http.ResponseWriter, res any) {
+	w.Header().Set(ContentTypeHeader, JSONContentType)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(res); err != nil {
+		h.logger.Error(encRespErrStr, zap.Error(err))
+		return
+	}
+}
+
+// authCookie собирает cookie с атрибутами из настроек. Отрицательный ttl удаляет cookie.
+func (h *Handlers) authCookie(name string, value string, path string, ttl time.Duration) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		HttpOnly: true,
+		Secure:   h.settings.Auth.Cookie.Secure,
+		SameSite: cookieSameSite(h.settings.Auth.Cookie.SameSite),
+	}
+
+	switch {
+	case ttl < 0:
+		cookie.MaxAge = -1
+	case ttl > 0:
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+
+	return cookie
+}
+
+func cookieSameSite(mode string) http.SameSite {
+	switch mode {
+	case config.SameSiteStrict:
+		return http.SameSiteStrictMode
+	case config.SameSiteNone:
+		return http.SameSiteNoneMode
+	case config.SameSiteLax:
+		return http.SameSiteLaxMode
+	default:
+		return http.SameSiteDefaultMode
+	}
+}
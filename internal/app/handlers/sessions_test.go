@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRefreshToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+
+	type serviceResponse struct {
+		res   models.RefreshTokenResponse
+		err   error
+		times int
+	}
+
+	type want struct {
+		cookies       map[string]string
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name            string
+		refreshToken    string
+		body            string
+		serviceResponse serviceResponse
+		want            want
+	}{
+		{
+			name:         "refresh token success",
+			refreshToken: "old",
+			serviceResponse: serviceResponse{
+				res:   models.RefreshTokenResponse{AuthToken: "access", RefreshToken: "new"},
+				times: 1,
+			},
+			want: want{
+				code:    http.StatusOK,
+				cookies: map[string]string{AuthTokenCookie: "access", RefreshCookie: "new"},
+				body:    `{"auth_token":"access","refresh_token":"new"}`,
+			},
+		},
+		{
+			name:         "refresh token from body",
+			refreshToken: "old",
+			body:         `{"refresh_token":"old"}`,
+			serviceResponse: serviceResponse{
+				res:   models.RefreshTokenResponse{AuthToken: "access", RefreshToken: "new"},
+				times: 1,
+			},
+			want: want{
+				code:    http.StatusOK,
+				cookies: map[string]string{AuthTokenCookie: "access", RefreshCookie: "new"},
+				body:    `{"auth_token":"access","refresh_token":"new"}`,
+			},
+		},
+		{
+			name:         "refresh token body malformed",
+			refreshToken: "",
+			body:         `{"refresh_token":`,
+			want: want{
+				code:          http.StatusBadRequest,
+				cookies:       map[string]string{},
+				errorLogTimes: 1,
+				log:           readReqErrStr,
+			},
+		},
+		{
+			name:         "refresh token invalid",
+			refreshToken: "old",
+			serviceResponse: serviceResponse{
+				err:   services.ErrInvalidRefreshToken,
+				times: 1,
+			},
+			want: want{
+				code:    http.StatusUnauthorized,
+				cookies: map[string]string{},
+			},
+		},
+		{
+			name:         "refresh token cookie missing",
+			refreshToken: "",
+			want: want{
+				code:    http.StatusUnauthorized,
+				cookies: map[string]string{},
+			},
+		},
+		{
+			name:         "refresh token failed",
+			refreshToken: "old",
+			serviceResponse: serviceResponse{
+				err:   errSome,
+				times: 1,
+			},
+			want: want{
+				code:          http.StatusInternalServerError,
+				cookies:       map[string]string{},
+				errorLogTimes: 1,
+				log:           "failed to refresh token",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().
+				RefreshToken(gomock.Any(), test.refreshToken).
+				Times(test.serviceResponse.times).
+				Return(test.serviceResponse.res, test.serviceResponse.err)
+			// Ошибку разбора тела создает декодер, поэтому для нее проверяется только сообщение.
+			var logField any = zap.Error(errSome)
+			if test.want.code == http.StatusBadRequest {
+				logField = gomock.Any()
+			}
+			_ = l.EXPECT().Error(test.want.log, logField).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/token/refresh", strings.NewReader(test.body))
+			if test.refreshToken != "" && test.body == "" {
+				request.AddCookie(&http.Cookie{Name: RefreshCookie, Value: test.refreshToken})
+			}
+			w := httptest.NewRecorder()
+			handlers.RefreshToken()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			cookies := map[string]string{}
+			for _, c := range res.Cookies() {
+				cookies[c.Name] = c.Value
+			}
+			assert.Equal(t, test.want.cookies, cookies)
+
+			if test.want.body != "" {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, test.want.body, string(body))
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+
+	t.Run("logout success", func(t *testing.T) {
+		_ = s.EXPECT().Logout(gomock.Any()).Times(1).Return(nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/user/logout", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.Logout()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		paths := map[string]string{AuthTokenCookie: AuthCookiePath, RefreshCookie: RefreshCookiePath}
+		for _, c := range res.Cookies() {
+			assert.Empty(t, c.Value)
+			assert.Negative(t, c.MaxAge)
+			assert.Equal(t, paths[c.Name], c.Path)
+		}
+		assert.Len(t, res.Cookies(), 2)
+	})
+
+	t.Run("logout failed", func(t *testing.T) {
+		_ = s.EXPECT().Logout(gomock.Any()).Times(1).Return(errSome)
+		_ = l.EXPECT().Error("failed to logout", zap.Error(errSome)).Times(1)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/user/logout", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.Logout()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+}
+
+func TestAuthCookieAttributes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	settings := &config.Settings{
+		Auth: config.AuthSettings{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: time.Hour,
+			Cookie:          config.CookieSettings{SameSite: config.SameSiteStrict, Secure: true},
+		},
+	}
+	handlers := NewHandlers(s, l, settings)
+
+	t.Run("cookies use configured attributes", func(t *testing.T) {
+		_ = s.EXPECT().
+			RefreshToken(gomock.Any(), "old").
+			Times(1).
+			Return(models.RefreshTokenResponse{AuthToken: "access", RefreshToken: "new"}, nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/user/token/refresh", http.NoBody)
+		request.AddCookie(&http.Cookie{Name: RefreshCookie, Value: "old"})
+		w := httptest.NewRecorder()
+		handlers.RefreshToken()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		maxAges := map[string]int{AuthTokenCookie: 900, RefreshCookie: 3600}
+		// Токен доступа нужен и под /api/admin, поэтому cookie не ограничена путем запроса.
+		paths := map[string]string{AuthTokenCookie: AuthCookiePath, RefreshCookie: RefreshCookiePath}
+		require.Len(t, res.Cookies(), 2)
+		for _, c := range res.Cookies() {
+			assert.Equal(t, paths[c.Name], c.Path)
+			assert.True(t, c.Secure)
+			assert.True(t, c.HttpOnly)
+			assert.Equal(t, http.SameSiteStrictMode, c.SameSite)
+			assert.Equal(t, maxAges[c.Name], c.MaxAge)
+		}
+	})
+}
+
+func TestGetJWKS(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	jwks := models.JWKSet{
+		Keys: []models.JWK{
+			{Kty: "OKP", Kid: "ed", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: "abc"},
+		},
+	}
+
+	t.Run("get jwks success", func(t *testing.T) {
+		_ = s.EXPECT().GetJWKS().Times(1).Return(jwks)
+
+		request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetJWKS()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		resBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, JSONContentType, res.Header.Get(ContentTypeHeader))
+		assert.Equal(t,
+			`{"keys":[{"kty":"OKP","kid":"ed","use":"sig","alg":"EdDSA","crv":"Ed25519","x":"abc"}]}`+"\n",
+			string(resBody))
+	})
+}
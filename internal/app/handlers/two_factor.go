@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) SetupTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.services.SetupTwoFactor(r.Context())
+		if err != nil {
+			if errors.Is(err, services.ErrTwoFactorEnabled) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to setup two-factor authentication", zap.Error(err))
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(res); err != nil {
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) ConfirmTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.TwoFactorCodeRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		err := h.services.ConfirmTwoFactor(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrTOTPInvalid) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+
+			if errors.Is(err, services.ErrTwoFactorNotSetup) || errors.Is(err, services.ErrTwoFactorEnabled) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to confirm two-factor authentication", zap.Error(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *Handlers) CompleteTwoFactorLogin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.TwoFactorLoginRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		res, err := h.services.CompleteTwoFactorLogin(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidLoginChallenge) || errors.Is(err, services.ErrTOTPInvalid) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to complete two-factor login", zap.Error(err))
+			return
+		}
+
+		h.setAuthCookies(w, res.AuthToken, res.RefreshToken)
+		h.writeAuthToken(w, res)
+	}
+}
+
+// writeChallenge сообщает, что пароль принят, но для входа нужен код второго фактора.
+func (h *Handlers) writeChallenge(w http.ResponseWriter, res models.LoginUserResponse) {
+	w.Header().Set(ContentTypeHeader, JSONContentType)
+	w.WriteHeader(http.StatusAccepted)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(res); err != nil {
+		h.logger.Error(encRespErrStr, zap.Error(err))
+		return
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSetupTwoFactor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+
+	type want struct {
+		log           string
+		body          string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name       string
+		serviceRes models.TwoFactorSetupResponse
+		serviceErr error
+		want       want
+	}{
+		{
+			name: "setup success",
+			serviceRes: models.TwoFactorSetupResponse{
+				OTPAuthURI:    "otpauth://totp/Gophermart:test?secret=ABC",
+				Secret:        "ABC",
+				RecoveryCodes: []string{"one", "two"},
+			},
+			want: want{
+				code: http.StatusOK,
+				body: `{"otpauth_uri":"otpauth://totp/Gophermart:test?secret=ABC","secret":"ABC",` +
+					`"recovery_codes":["one","two"]}`,
+			},
+		},
+		{
+			name:       "setup when already enabled",
+			serviceErr: services.ErrTwoFactorEnabled,
+			want:       want{code: http.StatusConflict},
+		},
+		{
+			name:       "setup failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to setup two-factor authentication",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().SetupTwoFactor(gomock.Any()).Times(1).Return(test.serviceRes, test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/2fa/setup", http.NoBody)
+			w := httptest.NewRecorder()
+			handlers.SetupTwoFactor()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			if test.want.body != "" {
+				resBody, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+				assert.JSONEq(t, test.want.body, string(resBody))
+			}
+		})
+	}
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	requestObject := models.TwoFactorCodeRequest{Code: "123456"}
+	errSome := errors.New("some error")
+
+	type want struct {
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		want       want
+	}{
+		{
+			name: "confirm success",
+			want: want{code: http.StatusOK},
+		},
+		{
+			name:       "confirm with invalid code",
+			serviceErr: services.ErrTOTPInvalid,
+			want:       want{code: http.StatusUnprocessableEntity},
+		},
+		{
+			name:       "confirm without setup",
+			serviceErr: services.ErrTwoFactorNotSetup,
+			want:       want{code: http.StatusConflict},
+		},
+		{
+			name:       "confirm failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to confirm two-factor authentication",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().ConfirmTwoFactor(gomock.Any(), requestObject).Times(1).Return(test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/2fa/confirm", strings.NewReader(`{"code":"123456"}`))
+			w := httptest.NewRecorder()
+			handlers.ConfirmTwoFactor()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+		})
+	}
+}
+
+func TestCompleteTwoFactorLogin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	requestBody := `{"challenge_token":"challenge","code":"123456"}`
+	requestObject := models.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "123456"}
+	errSome := errors.New("some error")
+
+	type want struct {
+		cookies       map[string]string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name       string
+		serviceRes models.LoginUserResponse
+		serviceErr error
+		want       want
+	}{
+		{
+			name:       "complete login success",
+			serviceRes: models.LoginUserResponse{AuthToken: "access", RefreshToken: "refresh"},
+			want: want{
+				code:    http.StatusOK,
+				cookies: map[string]string{AuthTokenCookie: "access", RefreshCookie: "refresh"},
+			},
+		},
+		{
+			name:       "complete login with invalid challenge",
+			serviceErr: services.ErrInvalidLoginChallenge,
+			want:       want{code: http.StatusUnauthorized, cookies: map[string]string{}},
+		},
+		{
+			name:       "complete login with invalid code",
+			serviceErr: services.ErrTOTPInvalid,
+			want:       want{code: http.StatusUnauthorized, cookies: map[string]string{}},
+		},
+		{
+			name:       "complete login failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				cookies:       map[string]string{},
+				errorLogTimes: 1,
+				log:           "failed to complete two-factor login",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().
+				CompleteTwoFactorLogin(gomock.Any(), requestObject).
+				Times(1).
+				Return(test.serviceRes, test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/login/2fa", strings.NewReader(requestBody))
+			w := httptest.NewRecorder()
+			handlers.CompleteTwoFactorLogin()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			cookies := map[string]string{}
+			for _, c := range res.Cookies() {
+				cookies[c.Name] = c.Value
+			}
+			assert.Equal(t, test.want.cookies, cookies)
+		})
+	}
+}
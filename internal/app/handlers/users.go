@@ -3,7 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/MihailSergeenkov/gophermart/internal/app/services"
@@ -24,6 +27,12 @@ func (h *Handlers) RegisterUser() http.HandlerFunc {
 		res, err := h.services.RegisterUser(r.Context(), req)
 
 		if err != nil {
+			var validationErr *services.ValidationError
+			if errors.As(err, &validationErr) {
+				h.writeValidationError(w, validationErr.Violations)
+				return
+			}
+
 			if errors.Is(err, services.ErrUserValidationFields) {
 				w.WriteHeader(http.StatusBadRequest)
 				return
@@ -39,15 +48,8 @@ func (h *Handlers) RegisterUser() http.HandlerFunc {
 			return
 		}
 
-		cookie := &http.Cookie{
-			Name:     "AUTH_TOKEN",
-			Value:    res.AuthToken,
-			HttpOnly: true,
-		}
-
-		http.SetCookie(w, cookie)
-
-		w.WriteHeader(http.StatusOK)
+		h.setAuthCookies(w, res.AuthToken, res.RefreshToken)
+		h.writeAuthToken(w, res)
 	}
 }
 
@@ -70,19 +72,111 @@ func (h *Handlers) LoginUser() http.HandlerFunc {
 				return
 			}
 
+			var lockedErr *services.LoginLockedError
+			if errors.As(err, &lockedErr) {
+				w.Header().Set(RetryAfterHeader, retryAfterSeconds(lockedErr.RetryAfter()))
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
 			w.WriteHeader(http.StatusInternalServerError)
 			h.logger.Error("failed to login user", zap.Error(err))
 			return
 		}
 
-		cookie := &http.Cookie{
-			Name:     "AUTH_TOKEN",
-			Value:    res.AuthToken,
-			HttpOnly: true,
+		if res.ChallengeToken != "" {
+			h.writeChallenge(w, res)
+			return
+		}
+
+		h.setAuthCookies(w, res.AuthToken, res.RefreshToken)
+		h.writeAuthToken(w, res)
+	}
+}
+
+func (h *Handlers) GetCurrentUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profile, err := h.services.GetCurrentUser(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to get current user", zap.Error(err))
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(profile); err != nil {
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) ChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.ChangePasswordRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		err := h.services.ChangePassword(r.Context(), req)
+		if err != nil {
+			var validationErr *services.ValidationError
+			if errors.As(err, &validationErr) {
+				h.writeValidationError(w, validationErr.Violations)
+				return
+			}
+
+			if errors.Is(err, services.ErrUserCurrentPassword) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to change password", zap.Error(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *Handlers) DeleteUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.services.DeleteUser(r.Context()); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to delete user", zap.Error(err))
+			return
 		}
 
-		http.SetCookie(w, cookie)
+		h.clearAuthCookies(w)
 
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+func (h *Handlers) writeValidationError(w http.ResponseWriter, violations []models.Violation) {
+	w.Header().Set(ContentTypeHeader, JSONContentType)
+	w.WriteHeader(http.StatusBadRequest)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(models.ValidationErrorResponse{Errors: violations}); err != nil {
+		h.logger.Error(encRespErrStr, zap.Error(err))
+		return
+	}
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	seconds := int64(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return strconv.FormatInt(seconds, 10)
+}
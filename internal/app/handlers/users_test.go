@@ -2,16 +2,20 @@ package handlers
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/MihailSergeenkov/gophermart/internal/app/services"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -21,7 +25,7 @@ func TestRegisterUser(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	requestBody := "{\"login\":\"test\",\"password\":\"test\"}"
 	requestObject := models.RegisterUserRequest{
@@ -49,7 +53,8 @@ func TestRegisterUser(t *testing.T) {
 			name: "register user success",
 			serviceResponse: serviceResponse{
 				res: models.RegisterUserResponse{
-					AuthToken: "qwerty",
+					AuthToken:    "qwerty",
+					RefreshToken: "refresh",
 				},
 				err: nil,
 			},
@@ -124,18 +129,67 @@ func TestRegisterUser(t *testing.T) {
 					}
 				}
 				assert.Equal(t, test.serviceResponse.res.AuthToken, authCookie.Value)
+
+				resBody, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, JSONContentType, res.Header.Get(ContentTypeHeader))
+				assert.JSONEq(t, `{"auth_token":"`+test.serviceResponse.res.AuthToken+`","refresh_token":"`+
+					test.serviceResponse.res.RefreshToken+`"}`, string(resBody))
+			}
+
+			if http.StatusAccepted == res.StatusCode {
+				assert.Empty(t, res.Cookies())
+
+				resBody, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, "{\"challenge_token\":\"challenge\"}", string(resBody))
 			}
 		})
 	}
 }
 
+func TestRegisterUserValidationErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	t.Run("violations are listed in response", func(t *testing.T) {
+		validationErr := &services.ValidationError{
+			Violations: []models.Violation{
+				{Field: "password", Rule: services.RuleMinLength, Message: "password must be at least 8 characters"},
+			},
+		}
+		_ = s.EXPECT().RegisterUser(gomock.Any(), gomock.Any()).Times(1).Return(models.RegisterUserResponse{}, validationErr)
+
+		requestBody := "{\"login\":\"test\",\"password\":\"1\"}"
+		request := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(requestBody))
+		w := httptest.NewRecorder()
+		handlers.RegisterUser()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		resBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, JSONContentType, res.Header.Get(ContentTypeHeader))
+		assert.JSONEq(t,
+			`{"errors":[{"field":"password","rule":"min_length","message":"password must be at least 8 characters"}]}`,
+			string(resBody))
+	})
+}
+
 func TestFailedReadBodyRegisterUser(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	requestBody := "{\"login\":\"test\",\"password\":\"test\",adasd}"
 
@@ -160,7 +214,7 @@ func TestLoginUser(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	requestBody := "{\"login\":\"test\",\"password\":\"test\"}"
 	requestObject := models.LoginUserRequest{
@@ -174,9 +228,10 @@ func TestLoginUser(t *testing.T) {
 	}
 
 	type want struct {
+		log           string
+		retryAfter    string
 		code          int
 		errorLogTimes int
-		log           string
 	}
 
 	tests := []struct {
@@ -188,7 +243,8 @@ func TestLoginUser(t *testing.T) {
 			name: "login user success",
 			serviceResponse: serviceResponse{
 				res: models.LoginUserResponse{
-					AuthToken: "qwerty",
+					AuthToken:    "qwerty",
+					RefreshToken: "refresh",
 				},
 				err: nil,
 			},
@@ -198,6 +254,17 @@ func TestLoginUser(t *testing.T) {
 				log:           "",
 			},
 		},
+		{
+			name: "login user requires second factor",
+			serviceResponse: serviceResponse{
+				res: models.LoginUserResponse{
+					ChallengeToken: "challenge",
+				},
+			},
+			want: want{
+				code: http.StatusAccepted,
+			},
+		},
 		{
 			name: "login user failed with ErrUserLoginCreds",
 			serviceResponse: serviceResponse{
@@ -210,6 +277,17 @@ func TestLoginUser(t *testing.T) {
 				log:           "",
 			},
 		},
+		{
+			name: "login user failed with LoginLockedError",
+			serviceResponse: serviceResponse{
+				res: models.LoginUserResponse{},
+				err: &services.LoginLockedError{Until: time.Now().Add(90 * time.Second)},
+			},
+			want: want{
+				code:       http.StatusTooManyRequests,
+				retryAfter: "90",
+			},
+		},
 		{
 			name: "login user failed with some error",
 			serviceResponse: serviceResponse{
@@ -241,6 +319,7 @@ func TestLoginUser(t *testing.T) {
 			defer closeBody(t, res)
 
 			assert.Equal(t, test.want.code, res.StatusCode)
+			assert.Equal(t, test.want.retryAfter, res.Header.Get(RetryAfterHeader))
 
 			if http.StatusOK == res.StatusCode {
 				cookies := res.Cookies()
@@ -251,6 +330,12 @@ func TestLoginUser(t *testing.T) {
 					}
 				}
 				assert.Equal(t, test.serviceResponse.res.AuthToken, authCookie.Value)
+
+				resBody, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, JSONContentType, res.Header.Get(ContentTypeHeader))
+				assert.JSONEq(t, `{"auth_token":"`+test.serviceResponse.res.AuthToken+`","refresh_token":"`+
+					test.serviceResponse.res.RefreshToken+`"}`, string(resBody))
 			}
 		})
 	}
@@ -262,7 +347,7 @@ func TestFailedReadBodyLoginUser(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	requestBody := "{\"login\":\"test\",\"password\":\"test\",adasd}"
 
@@ -280,3 +365,162 @@ func TestFailedReadBodyLoginUser(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
 	})
 }
+
+func TestGetCurrentUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+
+	t.Run("get current user success", func(t *testing.T) {
+		profile := models.UserProfile{
+			ID:        1,
+			Login:     "test",
+			Role:      models.RoleUser,
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		_ = s.EXPECT().GetCurrentUser(gomock.Any()).Times(1).Return(profile, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/me", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetCurrentUser()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		resBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"id":1,"login":"test","role":"USER","created_at":"2024-01-01T00:00:00Z"}`, string(resBody))
+	})
+
+	t.Run("get current user failed", func(t *testing.T) {
+		_ = s.EXPECT().GetCurrentUser(gomock.Any()).Times(1).Return(models.UserProfile{}, errSome)
+		_ = l.EXPECT().Error("failed to get current user", zap.Error(errSome)).Times(1)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/me", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetCurrentUser()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	requestBody := `{"current_password":"old","new_password":"NewSecret1"}`
+	requestObject := models.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "NewSecret1"}
+	errSome := errors.New("some error")
+
+	type want struct {
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		want       want
+	}{
+		{
+			name: "change password success",
+			want: want{code: http.StatusOK},
+		},
+		{
+			name:       "change password with invalid current password",
+			serviceErr: services.ErrUserCurrentPassword,
+			want:       want{code: http.StatusForbidden},
+		},
+		{
+			name: "change password with weak new password",
+			serviceErr: &services.ValidationError{
+				Violations: []models.Violation{{Field: "new_password", Rule: services.RuleMinLength}},
+			},
+			want: want{code: http.StatusBadRequest},
+		},
+		{
+			name:       "change password failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to change password",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().ChangePassword(gomock.Any(), requestObject).Times(1).Return(test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			request := httptest.NewRequest(http.MethodPut, "/api/user/password", strings.NewReader(requestBody))
+			w := httptest.NewRecorder()
+			handlers.ChangePassword()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+
+	t.Run("delete user success", func(t *testing.T) {
+		_ = s.EXPECT().DeleteUser(gomock.Any()).Times(1).Return(nil)
+
+		request := httptest.NewRequest(http.MethodDelete, "/api/user", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.DeleteUser()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		for _, c := range res.Cookies() {
+			assert.Empty(t, c.Value)
+			assert.Negative(t, c.MaxAge)
+		}
+		assert.Len(t, res.Cookies(), 2)
+	})
+
+	t.Run("delete user failed", func(t *testing.T) {
+		_ = s.EXPECT().DeleteUser(gomock.Any()).Times(1).Return(errSome)
+		_ = l.EXPECT().Error("failed to delete user", zap.Error(errSome)).Times(1)
+
+		request := httptest.NewRequest(http.MethodDelete, "/api/user", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.DeleteUser()(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		assert.Empty(t, res.Cookies())
+	})
+}
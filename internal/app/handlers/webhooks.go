@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+func (h *Handlers) CreateWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.WebhookRequest
+
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			h.logger.Error(readReqErrStr, zap.Error(err))
+			return
+		}
+
+		res, err := h.services.CreateWebhook(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrWebhookValidation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to create webhook", zap.Error(err))
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusCreated)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) GetWebhooks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := h.services.GetWebhooks(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to get webhooks", zap.Error(err))
+			return
+		}
+
+		if len(webhooks) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(webhooks); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) DeleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseWebhookID(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := h.services.DeleteWebhook(r.Context(), id); err != nil {
+			if errors.Is(err, services.ErrWebhookNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to delete webhook", zap.Error(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *Handlers) GetWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseWebhookDeliveriesRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		deliveries, err := h.services.GetWebhookDeliveries(r.Context(), req)
+		if err != nil {
+			if errors.Is(err, services.ErrPaginationValidation) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, services.ErrWebhookNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to get webhook deliveries", zap.Error(err))
+			return
+		}
+
+		if len(deliveries) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(deliveries); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *Handlers) ReplayWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseWebhookID(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.services.ReplayWebhookDeliveries(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, services.ErrWebhookNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error("failed to replay webhook deliveries", zap.Error(err))
+			return
+		}
+
+		w.Header().Set(ContentTypeHeader, JSONContentType)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			h.logger.Error(encRespErrStr, zap.Error(err))
+			return
+		}
+	}
+}
+
+func parseWebhookID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse webhook ID: %w", err)
+	}
+
+	return id, nil
+}
+
+func parseWebhookDeliveriesRequest(r *http.Request) (models.WebhookDeliveriesRequest, error) {
+	var req models.WebhookDeliveriesRequest
+	query := r.URL.Query()
+
+	id, err := parseWebhookID(r)
+	if err != nil {
+		return req, err
+	}
+	req.WebhookID = id
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse limit: %w", err)
+		}
+		req.Limit = limit
+	}
+
+	if v := query.Get("after"); v != "" {
+		afterID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return req, fmt.Errorf("failed to parse after: %w", err)
+		}
+		req.AfterID = afterID
+	}
+
+	return req, nil
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	errSome := errors.New("some error")
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	req := models.WebhookRequest{URL: "https://partner.example/hooks", EventTypes: []string{"ORDER_PROCESSED"}}
+	webhook := models.Webhook{
+		ID: 1, URL: req.URL, Secret: "0123456789abcdef", EventTypes: req.EventTypes, CreatedAt: createdAt,
+	}
+
+	type want struct {
+		body          string
+		log           string
+		code          int
+		errorLogTimes int
+	}
+
+	tests := []struct {
+		serviceErr error
+		name       string
+		want       want
+	}{
+		{
+			name: "created",
+			want: want{
+				code: http.StatusCreated,
+				body: `{"created_at":"2024-05-01T00:00:00Z","url":"https://partner.example/hooks",` +
+					`"secret":"0123456789abcdef","event_types":["ORDER_PROCESSED"],"id":1}` + "\n",
+			},
+		},
+		{
+			name:       "invalid webhook",
+			serviceErr: services.ErrWebhookValidation,
+			want:       want{code: http.StatusUnprocessableEntity},
+		},
+		{
+			name:       "create webhook failed",
+			serviceErr: errSome,
+			want: want{
+				code:          http.StatusInternalServerError,
+				errorLogTimes: 1,
+				log:           "failed to create webhook",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().CreateWebhook(gomock.Any(), req).Times(1).Return(webhook, test.serviceErr)
+			_ = l.EXPECT().Error(test.want.log, zap.Error(test.serviceErr)).Times(test.want.errorLogTimes)
+
+			body := strings.NewReader(`{"url":"https://partner.example/hooks","event_types":["ORDER_PROCESSED"]}`)
+			request := httptest.NewRequest(http.MethodPost, "/api/user/webhooks", body)
+			w := httptest.NewRecorder()
+			handlers.CreateWebhook()(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.body, string(resBody))
+		})
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	tests := []struct {
+		serviceErr error
+		name       string
+		id         string
+		code       int
+	}{
+		{name: "deleted", id: "1", code: http.StatusNoContent},
+		{name: "not found", id: "1", serviceErr: services.ErrWebhookNotFound, code: http.StatusNotFound},
+		{name: "bad id", id: "abc", code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.code != http.StatusBadRequest {
+				_ = s.EXPECT().DeleteWebhook(gomock.Any(), int64(1)).Times(1).Return(test.serviceErr)
+			}
+
+			request := httptest.NewRequest(http.MethodDelete, "/api/user/webhooks/"+test.id, http.NoBody)
+			w := httptest.NewRecorder()
+			handlers.DeleteWebhook()(w, withWebhookIDParam(request, test.id))
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.code, res.StatusCode)
+		})
+	}
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	deliveries := []models.WebhookDelivery{{
+		ID:             5,
+		WebhookID:      1,
+		EventType:      models.WebhookPointsWithdrawn,
+		Payload:        []byte(`{"order":"2377225624","sum":5}`),
+		Status:         models.WebhookDeliveryFailed,
+		Attempts:       8,
+		ResponseStatus: http.StatusServiceUnavailable,
+		LastError:      "unexpected status code: 503",
+		CreatedAt:      createdAt,
+		NextAttemptAt:  createdAt,
+		LastAttemptAt:  &createdAt,
+		URL:            "https://partner.example/hooks",
+		Secret:         "0123456789abcdef",
+	}}
+
+	t.Run("delivery log", func(t *testing.T) {
+		_ = s.EXPECT().
+			GetWebhookDeliveries(gomock.Any(), models.WebhookDeliveriesRequest{WebhookID: 1, AfterID: 4, Limit: 10}).
+			Times(1).Return(deliveries, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/webhooks/1/deliveries?after=4&limit=10", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetWebhookDeliveries()(w, withWebhookIDParam(request, "1"))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `[{"created_at":"2024-05-01T00:00:00Z","next_attempt_at":"2024-05-01T00:00:00Z",`+
+			`"last_attempt_at":"2024-05-01T00:00:00Z","event_type":"POINTS_WITHDRAWN","status":"FAILED",`+
+			`"last_error":"unexpected status code: 503","payload":{"order":"2377225624","sum":5},"id":5,`+
+			`"webhook_id":1,"attempts":8,"response_status":503}]`+"\n", string(body))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_ = s.EXPECT().GetWebhookDeliveries(gomock.Any(), models.WebhookDeliveriesRequest{WebhookID: 2}).
+			Times(1).Return(nil, services.ErrWebhookNotFound)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/user/webhooks/2/deliveries", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetWebhookDeliveries()(w, withWebhookIDParam(request, "2"))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("bad cursor", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/api/user/webhooks/1/deliveries?after=x", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.GetWebhookDeliveries()(w, withWebhookIDParam(request, "1"))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestReplayWebhookDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockServicer(mockCtrl)
+	l := mocks.NewMockLogger(mockCtrl)
+	handlers := NewHandlers(s, l, &config.Settings{})
+
+	t.Run("replayed", func(t *testing.T) {
+		_ = s.EXPECT().ReplayWebhookDeliveries(gomock.Any(), int64(1)).Times(1).
+			Return(models.WebhookReplayResponse{Replayed: 3}, nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/user/webhooks/1/replay", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.ReplayWebhookDeliveries()(w, withWebhookIDParam(request, "1"))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"replayed":3}`+"\n", string(body))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_ = s.EXPECT().ReplayWebhookDeliveries(gomock.Any(), int64(2)).Times(1).
+			Return(models.WebhookReplayResponse{}, services.ErrWebhookNotFound)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/user/webhooks/2/replay", http.NoBody)
+		w := httptest.NewRecorder()
+		handlers.ReplayWebhookDeliveries()(w, withWebhookIDParam(request, "2"))
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func withWebhookIDParam(r *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
@@ -48,7 +48,8 @@ func (h *Handlers) AddWithdraw() http.HandlerFunc {
 
 		err := h.services.AddWithdraw(r.Context(), req)
 		if err != nil {
-			if errors.Is(err, services.ErrOrderNumberValidation) {
+			if errors.Is(err, services.ErrOrderNumberValidation) ||
+				errors.Is(err, services.ErrWithdrawSumValidation) {
 				w.WriteHeader(http.StatusUnprocessableEntity)
 				return
 			}
@@ -58,6 +59,18 @@ func (h *Handlers) AddWithdraw() http.HandlerFunc {
 				return
 			}
 
+			if errors.Is(err, services.ErrTOTPRequired) || errors.Is(err, services.ErrTOTPInvalid) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			var lockedErr *services.TOTPLockedError
+			if errors.As(err, &lockedErr) {
+				w.Header().Set(RetryAfterHeader, retryAfterSeconds(lockedErr.RetryAfter()))
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
 			w.WriteHeader(http.StatusInternalServerError)
 			h.logger.Error("failed to add withdraw", zap.Error(err))
 			return
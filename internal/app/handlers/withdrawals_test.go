@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/handlers/mocks"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/MihailSergeenkov/gophermart/internal/app/services"
@@ -25,12 +26,12 @@ func TestAddWithdraw(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	requestBody := "{\"order\":\"12345678\",\"sum\":100.22}"
 	requestObject := models.AddWithdrawRequest{
 		OrderNumber: "12345678",
-		Sum:         100.22,
+		Sum:         10022,
 	}
 
 	type serviceResponse struct {
@@ -81,6 +82,35 @@ func TestAddWithdraw(t *testing.T) {
 				log:           "",
 			},
 		},
+		{
+			name: "add order failed with ErrTOTPRequired",
+			serviceResponse: serviceResponse{
+				err: services.ErrTOTPRequired,
+			},
+			want: want{
+				code: http.StatusForbidden,
+			},
+		},
+		{
+			name: "add withdraw failed with locked TOTP",
+			serviceResponse: serviceResponse{
+				err: &services.TOTPLockedError{Until: time.Now().Add(time.Minute)},
+			},
+			want: want{
+				code: http.StatusTooManyRequests,
+			},
+		},
+		{
+			name: "add order failed with ErrWithdrawSumValidation",
+			serviceResponse: serviceResponse{
+				err: services.ErrWithdrawSumValidation,
+			},
+			want: want{
+				code:          http.StatusUnprocessableEntity,
+				errorLogTimes: 0,
+				log:           "",
+			},
+		},
 		{
 			name: "add withdraw failed with some error",
 			serviceResponse: serviceResponse{
@@ -117,23 +147,37 @@ func TestFailedReadBodyAddWithdraw(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
-	requestBody := "{\"order\":\"12345678\",\"sum\":100.22,adasd}"
+	tests := []struct {
+		name        string
+		requestBody string
+	}{
+		{
+			name:        "failed to read request body",
+			requestBody: "{\"order\":\"12345678\",\"sum\":100.22,adasd}",
+		},
+		{
+			name:        "sum with too many fractional digits",
+			requestBody: "{\"order\":\"12345678\",\"sum\":100.225}",
+		},
+	}
 
-	t.Run("failed to read request body", func(t *testing.T) {
-		_ = s.EXPECT().AddWithdraw(gomock.Any(), gomock.Any()).Times(0)
-		_ = l.EXPECT().Error("failed to read request body", gomock.Any()).Times(1)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = s.EXPECT().AddWithdraw(gomock.Any(), gomock.Any()).Times(0)
+			_ = l.EXPECT().Error("failed to read request body", gomock.Any()).Times(1)
 
-		request := httptest.NewRequest(http.MethodPost, "/api/user/balance/withdraw", strings.NewReader(requestBody))
-		w := httptest.NewRecorder()
-		handlers.AddWithdraw()(w, request)
+			request := httptest.NewRequest(http.MethodPost, "/api/user/balance/withdraw", strings.NewReader(test.requestBody))
+			w := httptest.NewRecorder()
+			handlers.AddWithdraw()(w, request)
 
-		res := w.Result()
-		defer closeBody(t, res)
+			res := w.Result()
+			defer closeBody(t, res)
 
-		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-	})
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		})
+	}
 }
 
 func TestGetWithdrawals(t *testing.T) {
@@ -142,7 +186,7 @@ func TestGetWithdrawals(t *testing.T) {
 
 	s := mocks.NewMockServicer(mockCtrl)
 	l := mocks.NewMockLogger(mockCtrl)
-	handlers := NewHandlers(s, l)
+	handlers := NewHandlers(s, l, &config.Settings{})
 
 	errSome := errors.New("some error")
 	processedAt := time.Now()
@@ -171,7 +215,7 @@ func TestGetWithdrawals(t *testing.T) {
 				res: []models.Withdraw{
 					{
 						OrderNumber: "12345678",
-						Sum:         100.22,
+						Sum:         10022,
 						ProcessedAt: processedAt,
 					},
 				},
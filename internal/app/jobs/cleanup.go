@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (bp *BackgroudProcessing) cleanupExpired(ctx context.Context) {
+	cleanups := map[string]func(ctx context.Context) (int64, error){
+		"idempotency keys": bp.store.DeleteExpiredIdempotencyKeys,
+		"sessions":         bp.store.DeleteExpiredSessions,
+		"login challenges": bp.store.DeleteExpiredLoginChallenges,
+		"password resets":  bp.store.DeleteExpiredPasswordResetTokens,
+		"login throttles": func(ctx context.Context) (int64, error) {
+			return bp.store.DeleteExpiredLoginThrottles(ctx, time.Now().Add(-bp.settings.LoginThrottle.FailureWindow))
+		},
+		"webhook deliveries": func(ctx context.Context) (int64, error) {
+			return bp.store.DeleteExpiredWebhookDeliveries(ctx, time.Now().Add(-bp.settings.Webhooks.Retention))
+		},
+	}
+
+	ticker := time.NewTicker(bp.settings.CleanupPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for name, cleanup := range cleanups {
+				deleted, err := cleanup(ctx)
+				if err != nil {
+					bp.logger.Error("failed to delete expired records", zap.String("records", name), zap.Error(err))
+					continue
+				}
+
+				bp.logger.Info("expired records deleted", zap.String("records", name), zap.Int64("count", deleted))
+			}
+		}
+	}
+}
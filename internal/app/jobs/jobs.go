@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
@@ -12,21 +13,38 @@ type BackgroudProcessing struct {
 	settings *config.Settings
 	logger   *zap.Logger
 	store    Storager
+	auditor  Auditor
 }
 
 type Storager interface {
-	UpdateOrder(ctx context.Context, number string, status string, accrual float32) error
+	UpdateOrder(ctx context.Context, number string, status string, accrual models.Points) (bool, error)
 	GetOrdersByStatus(ctx context.Context, statuses ...string) ([]models.Order, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	DeleteExpiredLoginChallenges(ctx context.Context) (int64, error)
+	DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error)
+	DeleteExpiredLoginThrottles(ctx context.Context, windowStart time.Time) (int64, error)
+	DeleteExpiredWebhookDeliveries(ctx context.Context, deliveredBefore time.Time) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, a models.WebhookAttempt) error
 }
 
-func NewBackgroudProcessing(settings *config.Settings, logger *zap.Logger, store Storager) *BackgroudProcessing {
+type Auditor interface {
+	Record(ctx context.Context, e models.AuditEvent)
+}
+
+func NewBackgroudProcessing(settings *config.Settings,
+	logger *zap.Logger, store Storager, auditor Auditor) *BackgroudProcessing {
 	return &BackgroudProcessing{
 		settings: settings,
 		logger:   logger,
 		store:    store,
+		auditor:  auditor,
 	}
 }
 
 func (bp *BackgroudProcessing) Start(ctx context.Context) {
 	go bp.processOrdersAccrual(ctx)
+	go bp.cleanupExpired(ctx)
+	go bp.deliverWebhooks(ctx)
 }
@@ -86,7 +86,7 @@ func worker(
 				time.Sleep(b.retryAfter.Sub(now))
 			}
 
-			err := processOrderAccrual(ctx, bp.store, client, order)
+			err := processOrderAccrual(ctx, bp.store, bp.auditor, client, order)
 			if err != nil {
 				var pgxError *clients.TooManyRequestsError
 				if errors.As(err, &pgxError) {
@@ -99,22 +99,30 @@ func worker(
 	}
 }
 
-func processOrderAccrual(ctx context.Context, s Storager, client *clients.AccrualClient, order models.Order) error {
-	statusMap := map[string]string{
-		"REGISTERED": "PROCESSING",
-		"PROCESSING": "PROCESSING",
-		"INVALID":    "INVALID",
-		"PROCESSED":  "PROCESSED",
-	}
+func processOrderAccrual(ctx context.Context,
+	s Storager, a Auditor, client *clients.AccrualClient, order models.Order) error {
 	status, accrual, err := client.GetOrderAccrual(order.Number)
 	if err != nil {
 		return fmt.Errorf("failed process to get order accrual: %w", err)
 	}
 
-	err = s.UpdateOrder(ctx, order.Number, statusMap[status], accrual)
+	newStatus := clients.OrderStatuses[status]
+
+	updated, err := s.UpdateOrder(ctx, order.Number, newStatus, accrual)
 	if err != nil {
 		return fmt.Errorf("failed process to update order: %w", err)
 	}
 
+	if updated && newStatus != order.Status {
+		a.Record(ctx, models.AuditEvent{
+			Action:     models.AuditOrderStatus,
+			TargetType: models.AuditTargetOrder,
+			TargetID:   order.Number,
+			UserID:     order.UserID,
+			Before:     map[string]any{"status": order.Status},
+			After:      map[string]any{"status": newStatus, "accrual": accrual},
+		})
+	}
+
 	return nil
 }
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/clients"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"go.uber.org/zap"
+)
+
+// maxWebhookErrorLength ограничивает текст ошибки в журнале доставок.
+const maxWebhookErrorLength = 1000
+
+type WebhookSender interface {
+	Deliver(ctx context.Context, d models.WebhookDelivery) (int, error)
+}
+
+func (bp *BackgroudProcessing) deliverWebhooks(ctx context.Context) {
+	client := clients.NewWebhookClient(&bp.settings.Webhooks, bp.logger)
+
+	ticker := time.NewTicker(bp.settings.Webhooks.DeliveryPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bp.deliverPendingWebhooks(ctx, client)
+		}
+	}
+}
+
+// deliverPendingWebhooks отправляет пачку доставок, время которых наступило, параллельно и сохраняет результаты.
+// Доставки откладываются на два таймаута запроса, поэтому другой экземпляр не возьмет их, пока идет отправка.
+func (bp *BackgroudProcessing) deliverPendingWebhooks(ctx context.Context, sender WebhookSender) {
+	settings := bp.settings.Webhooks
+
+	deliveries, err := bp.store.ClaimWebhookDeliveries(ctx, settings.BatchSize, time.Now().Add(2*settings.Timeout))
+	if err != nil {
+		bp.logger.Error("failed to claim webhook deliveries", zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func(d models.WebhookDelivery) {
+			defer wg.Done()
+
+			attempt := bp.deliverWebhook(ctx, sender, d)
+			if err := bp.store.CompleteWebhookDelivery(context.WithoutCancel(ctx), attempt); err != nil {
+				bp.logger.Error("failed to save webhook delivery attempt", zap.Int64("delivery_id", d.ID), zap.Error(err))
+			}
+		}(d)
+	}
+
+	wg.Wait()
+}
+
+func (bp *BackgroudProcessing) deliverWebhook(ctx context.Context,
+	sender WebhookSender, d models.WebhookDelivery) models.WebhookAttempt {
+	attempt := models.WebhookAttempt{DeliveryID: d.ID}
+
+	code, err := sender.Deliver(ctx, d)
+	attempt.ResponseStatus = code
+
+	if err == nil {
+		attempt.Delivered = true
+		return attempt
+	}
+
+	attempt.Error = err.Error()
+	if len(attempt.Error) > maxWebhookErrorLength {
+		attempt.Error = attempt.Error[:maxWebhookErrorLength]
+	}
+
+	if attempts := d.Attempts + 1; attempts < bp.settings.Webhooks.MaxAttempts {
+		attempt.NextAttemptAt = time.Now().Add(webhookBackoff(bp.settings.Webhooks.BaseBackoff,
+			bp.settings.Webhooks.MaxBackoff, attempts))
+	}
+
+	bp.logger.Info("failed to deliver webhook",
+		zap.Int64("delivery_id", d.ID),
+		zap.Int("attempts", d.Attempts+1),
+		zap.Error(err))
+
+	return attempt
+}
+
+// webhookBackoff возвращает паузу после attempts неудачных попыток: base, 2*base, 4*base... но не больше limit.
+func webhookBackoff(base time.Duration, limit time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < limit; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, limit)
+}
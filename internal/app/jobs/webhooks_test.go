@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/clients"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeWebhookStore выдает заранее заданные доставки и запоминает результаты попыток.
+// Остальные методы хранилища в этих тестах не вызываются.
+type fakeWebhookStore struct {
+	Storager
+	leaseUntil time.Time
+	deliveries []models.WebhookDelivery
+	attempts   []models.WebhookAttempt
+	mu         sync.Mutex
+}
+
+func (f *fakeWebhookStore) ClaimWebhookDeliveries(_ context.Context,
+	limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error) {
+	f.leaseUntil = leaseUntil
+	return f.deliveries[:min(limit, len(f.deliveries))], nil
+}
+
+func (f *fakeWebhookStore) CompleteWebhookDelivery(_ context.Context, a models.WebhookAttempt) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.attempts = append(f.attempts, a)
+	return nil
+}
+
+func TestDeliverPendingWebhooks(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	settings := config.Settings{Webhooks: config.WebhookSettings{
+		Timeout:     time.Second,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  time.Hour,
+		MaxAttempts: 3,
+		BatchSize:   10,
+		// Тестовый получатель слушает 127.0.0.1.
+		AllowPrivate: true,
+	}}
+
+	store := &fakeWebhookStore{deliveries: []models.WebhookDelivery{
+		{ID: 1, URL: receiver.URL + "/ok", Payload: []byte(`{}`)},
+		{ID: 2, URL: receiver.URL + "/fail", Attempts: 1, Payload: []byte(`{}`)},
+		{ID: 3, URL: receiver.URL + "/fail", Attempts: 2, Payload: []byte(`{}`)},
+	}}
+
+	bp := NewBackgroudProcessing(&settings, zap.NewNop(), store, nil)
+
+	start := time.Now()
+	bp.deliverPendingWebhooks(context.Background(), clients.NewWebhookClient(&settings.Webhooks, zap.NewNop()))
+
+	assert.WithinDuration(t, start.Add(2*time.Second), store.leaseUntil, time.Second)
+
+	require.Len(t, store.attempts, 3)
+	sort.Slice(store.attempts, func(i, j int) bool { return store.attempts[i].DeliveryID < store.attempts[j].DeliveryID })
+
+	delivered := store.attempts[0]
+	assert.True(t, delivered.Delivered)
+	assert.Equal(t, http.StatusOK, delivered.ResponseStatus)
+	assert.Empty(t, delivered.Error)
+
+	retried := store.attempts[1]
+	assert.False(t, retried.Delivered)
+	assert.Equal(t, http.StatusServiceUnavailable, retried.ResponseStatus)
+	assert.NotEmpty(t, retried.Error)
+	assert.WithinDuration(t, start.Add(2*time.Minute), retried.NextAttemptAt, time.Second)
+
+	failed := store.attempts[2]
+	assert.False(t, failed.Delivered)
+	assert.True(t, failed.NextAttemptAt.IsZero())
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 10, want: 10 * time.Minute},
+		{attempts: 1000, want: 10 * time.Minute},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, webhookBackoff(30*time.Second, 10*time.Minute, test.attempts))
+	}
+}
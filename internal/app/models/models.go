@@ -1,18 +1,77 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	LedgerKindAccrual    = "ACCRUAL"
+	LedgerKindWithdrawal = "WITHDRAWAL"
+	LedgerKindAdjustment = "ADJUSTMENT"
+	LedgerKindReversal   = "REVERSAL"
+)
+
+const (
+	LoginScopeLogin = "LOGIN"
+	LoginScopeIP    = "IP"
+	LoginScopeTOTP  = "TOTP"
+)
+
+const (
+	RoleUser  = "USER"
+	RoleAdmin = "ADMIN"
+)
+
+const (
+	AuditUserRegistered    = "USER_REGISTERED"
+	AuditLogin             = "LOGIN"
+	AuditLoginFailed       = "LOGIN_FAILED"
+	AuditPasswordChanged   = "PASSWORD_CHANGED"
+	AuditPasswordReset     = "PASSWORD_RESET"
+	AuditUserDeleted       = "USER_DELETED"
+	AuditTwoFactorEnabled  = "TWO_FACTOR_ENABLED"
+	AuditRoleChanged       = "ROLE_CHANGED"
+	AuditWithdrawal        = "WITHDRAWAL"
+	AuditOrderStatus       = "ORDER_STATUS_CHANGED"
+	AuditBalanceAdjustment = "BALANCE_ADJUSTMENT"
+	AuditOrderOverride     = "ORDER_OVERRIDE"
+)
+
+const (
+	AuditTargetUser       = "USER"
+	AuditTargetOrder      = "ORDER"
+	AuditTargetWithdrawal = "WITHDRAWAL"
+)
+
+const (
+	OrderActionRecheck   = "RECHECK"
+	OrderActionReset     = "RESET"
+	OrderActionSetStatus = "SET_STATUS"
+)
+
 type RegisterUserRequest struct {
 	Login    string `json:"login"`
+	Email    string `json:"email,omitempty"`
 	Password string `json:"password"`
 }
 
 type RegisterUserResponse struct {
-	AuthToken string
+	AuthToken    string `json:"auth_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// Violation описывает нарушенное правило валидации поля запроса.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type ValidationErrorResponse struct {
+	Errors []Violation `json:"errors"`
 }
 
 type LoginUserRequest struct {
@@ -20,41 +79,406 @@ type LoginUserRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginUserResponse содержит либо токены, либо ChallengeToken, если для входа нужен код второго фактора.
 type LoginUserResponse struct {
-	AuthToken string
+	AuthToken      string `json:"auth_token,omitempty"`
+	RefreshToken   string `json:"refresh_token,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorSetupResponse struct {
+	OTPAuthURI    string   `json:"otpauth_uri"`
+	Secret        string   `json:"secret"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TOTP struct {
+	ConfirmedAt  *time.Time
+	Secret       string
+	LastUsedStep int64
+	UserID       int
+}
+
+type LoginChallenge struct {
+	ExpiresAt time.Time
+	TokenHash []byte
+	UserID    int
+	Attempts  int
+}
+
+// RefreshTokenRequest — тело запроса обновления токена для клиентов без cookie.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenResponse struct {
+	AuthToken    string `json:"auth_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 type AddWithdrawRequest struct {
-	OrderNumber string  `json:"order"`
-	Sum         float32 `json:"sum"`
+	OrderNumber string `json:"order"`
+	TOTPCode    string `json:"totp_code,omitempty"`
+	Sum         Points `json:"sum"`
 }
 
 type Order struct {
 	UploadedAt time.Time `json:"uploaded_at"`
 	Number     string    `json:"number"`
 	Status     string    `json:"status"`
-	Accrual    float32   `json:"accrual,omitempty"`
+	Accrual    Points    `json:"accrual,omitempty"`
 	UserID     int       `json:"-"`
 }
 
+const (
+	OrderUploadAccepted  = "ACCEPTED"
+	OrderUploadDuplicate = "DUPLICATE"
+	OrderUploadConflict  = "OWNED_BY_ANOTHER_USER"
+	OrderUploadInvalid   = "INVALID_NUMBER"
+)
+
+// BatchOrder — заказ из пакетной загрузки. Created — заказ добавлен этой загрузкой.
+type BatchOrder struct {
+	Order
+	Created bool
+}
+
+type OrderUploadResult struct {
+	Number string `json:"number"`
+	Status string `json:"status"`
+}
+
+// OrderStatusEvent — смена статуса заказа. Accrual — начисление, действовавшее после смены.
+type OrderStatusEvent struct {
+	CreatedAt time.Time `json:"created_at"`
+	Status    string    `json:"status"`
+	Accrual   Points    `json:"accrual,omitempty"`
+}
+
+// OrderUpdate — смена статуса заказа, которую получают подписчики потока событий пользователя.
+type OrderUpdate struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Number    string    `json:"number"`
+	Status    string    `json:"status"`
+	Accrual   Points    `json:"accrual,omitempty"`
+	UserID    int       `json:"-"`
+}
+
+type OrderDetails struct {
+	Order
+	History []OrderStatusEvent `json:"history"`
+}
+
 type Balance struct {
-	Current   float32 `json:"current"`
-	Withdrawn float32 `json:"withdrawn"`
+	Current   Points `json:"current"`
+	Withdrawn Points `json:"withdrawn"`
 }
 
 type Withdraw struct {
 	ProcessedAt time.Time `json:"processed_at"`
 	OrderNumber string    `json:"order"`
-	Sum         float32   `json:"sum"`
+	Sum         Points    `json:"sum"`
+}
+
+type LedgerEntry struct {
+	CreatedAt    time.Time `json:"created_at"`
+	Kind         string    `json:"kind"`
+	OrderNumber  string    `json:"order,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	ID           int64     `json:"id"`
+	ReversesID   int64     `json:"reverses_id,omitempty"`
+	Amount       Points    `json:"amount"`
+	BalanceAfter Points    `json:"balance_after"`
+	UserID       int       `json:"-"`
+}
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// OrdersRequest — параметры страницы списка заказов. After — непрозрачный курсор из предыдущей страницы.
+// OrdersRequest — параметры списка заказов. Limit равен nil, если размер страницы не передан.
+type OrdersRequest struct {
+	From   time.Time
+	To     time.Time
+	Limit  *int
+	Status string
+	After  string
+	Sort   string
+}
+
+// OrderQuery — выборка заказов текущего пользователя. Заказы упорядочены по времени загрузки и номеру,
+// страница начинается после заказа AfterUploadedAt, AfterNumber, если он задан. Нулевой Limit снимает ограничение.
+type OrderQuery struct {
+	From            time.Time
+	To              time.Time
+	AfterUploadedAt time.Time
+	Status          string
+	AfterNumber     string
+	Limit           int
+	Desc            bool
+}
+
+type OrdersPage struct {
+	NextCursor string
+	Orders     []Order
+}
+
+type BalanceHistoryRequest struct {
+	AfterID int64
+	Limit   int
+}
+
+type UserSearchRequest struct {
+	Login string
+	Limit int
+}
+
+// AdminUserDetails — карточка пользователя для поддержки: профиль, баланс, заказы и списания.
+type AdminUserDetails struct {
+	User        UserProfile `json:"user"`
+	Orders      []Order     `json:"orders"`
+	Withdrawals []Withdraw  `json:"withdrawals"`
+	Balance     Balance     `json:"balance"`
+}
+
+type BalanceAdjustmentRequest struct {
+	Reason string `json:"reason"`
+	Amount Points `json:"amount"`
+}
+
+type BalanceAdjustment struct {
+	CreatedAt    time.Time `json:"created_at"`
+	Reason       string    `json:"reason"`
+	ID           int64     `json:"id"`
+	LedgerID     int64     `json:"ledger_id"`
+	Amount       Points    `json:"amount"`
+	BalanceAfter Points    `json:"balance_after"`
+	UserID       int       `json:"user_id"`
+	AdminID      int       `json:"admin_id"`
+}
+
+type OrderOverrideRequest struct {
+	Reason string `json:"reason"`
+}
+
+type OrderStatusRequest struct {
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Accrual Points `json:"accrual"`
+}
+
+// OrderOverride — запись аудита ручного изменения заказа администратором.
+type OrderOverride struct {
+	CreatedAt    time.Time `json:"created_at"`
+	OrderNumber  string    `json:"order"`
+	Action       string    `json:"action"`
+	OldStatus    string    `json:"old_status"`
+	NewStatus    string    `json:"new_status"`
+	Reason       string    `json:"reason"`
+	ID           int64     `json:"id"`
+	LedgerID     int64     `json:"ledger_id,omitempty"`
+	OldAccrual   Points    `json:"old_accrual"`
+	NewAccrual   Points    `json:"new_accrual"`
+	BalanceDelta Points    `json:"balance_delta"`
+	UserID       int       `json:"user_id"`
+	AdminID      int       `json:"admin_id"`
+}
+
+// AuditEvent — запись журнала аудита. ActorID — кто выполнил действие (0 — система или аноним),
+// UserID — чью учетную запись или баланс оно затронуло.
+type AuditEvent struct {
+	CreatedAt  time.Time `json:"created_at"`
+	Before     any       `json:"before,omitempty"`
+	After      any       `json:"after,omitempty"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	RequestID  string    `json:"request_id,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	ID         int64     `json:"id"`
+	ActorID    int       `json:"actor_id,omitempty"`
+	UserID     int       `json:"user_id,omitempty"`
+}
+
+type AuditQuery struct {
+	From    time.Time
+	To      time.Time
+	AfterID int64
+	UserID  int
+	Limit   int
+}
+
+const (
+	WebhookOrderProcessed  = "ORDER_PROCESSED"
+	WebhookPointsWithdrawn = "POINTS_WITHDRAWN"
+)
+
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+// WebhookRequest — подписка на события. Если секрет не задан, он генерируется и возвращается один раз.
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types"`
+}
+
+// Webhook — подписка пользователя. Secret заполнен только в ответе на создание подписки.
+type Webhook struct {
+	CreatedAt  time.Time `json:"created_at"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	ID         int64     `json:"id"`
+	UserID     int       `json:"-"`
+}
+
+// WebhookDelivery — отправка события подписчику и результат последней попытки. URL и Secret подписки
+// заполняются только для доставки.
+type WebhookDelivery struct {
+	CreatedAt      time.Time       `json:"created_at"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	LastError      string          `json:"last_error,omitempty"`
+	URL            string          `json:"-"`
+	Secret         string          `json:"-"`
+	Payload        json.RawMessage `json:"payload"`
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+}
+
+// WebhookAttempt — результат попытки доставки. Нулевой NextAttemptAt у неудачной попытки означает,
+// что попытки закончились.
+type WebhookAttempt struct {
+	NextAttemptAt  time.Time
+	Error          string
+	DeliveryID     int64
+	ResponseStatus int
+	Delivered      bool
+}
+
+type WebhookDeliveriesRequest struct {
+	WebhookID int64
+	AfterID   int64
+	Limit     int
+}
+
+type WebhookReplayResponse struct {
+	Replayed int64 `json:"replayed"`
+}
+
+type IdempotencyKey struct {
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	Key         string
+	Fingerprint string
+	ContentType string
+	Body        []byte
+	StatusCode  int
+	UserID      int
 }
 
 type User struct {
-	Login    string
-	Password []byte
-	ID       int
+	CreatedAt         time.Time
+	PasswordChangedAt *time.Time
+	Login             string
+	Email             string
+	Role              string
+	Password          []byte
+	ID                int
+}
+
+type UserProfile struct {
+	CreatedAt         time.Time  `json:"created_at"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	Login             string     `json:"login"`
+	Email             string     `json:"email,omitempty"`
+	Role              string     `json:"role"`
+	ID                int        `json:"id"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type PasswordResetRequest struct {
+	Login string `json:"login"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type PasswordResetToken struct {
+	ExpiresAt time.Time
+	TokenHash []byte
+	UserID    int
+}
+
+// Notification — сообщение пользователю, которое доставляет настроенный канал уведомлений.
+type Notification struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Session struct {
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	ID               string
+	RefreshTokenHash []byte
+	UserID           int
+	Revoked          bool
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
 }
 
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID int
+	SessionID string `json:"sid"`
+	Role      string `json:"role,omitempty"`
+	UserID    int
+}
+
+// UserRole возвращает роль из токена. Токены, выданные до появления ролей, относятся к обычным пользователям.
+func (c *Claims) UserRole() string {
+	if c.Role == "" {
+		return RoleUser
+	}
+
+	return c.Role
 }
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Points хранит количество баллов в сотых долях, чтобы арифметика была точной.
+type Points int64
+
+const (
+	pointsScale    = 100
+	pointsFracSize = 2
+)
+
+var ErrPointsFormat = errors.New("invalid points format")
+
+// ParsePoints разбирает десятичную запись вида "123", "123.4" или "-123.45".
+// Больше двух знаков после точки, экспонента и пустые части не допускаются.
+func ParsePoints(s string) (Points, error) {
+	str := s
+	negative := false
+
+	if strings.HasPrefix(str, "-") {
+		negative = true
+		str = str[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(str, ".")
+	if intPart == "" || (hasDot && fracPart == "") || len(fracPart) > pointsFracSize {
+		return 0, fmt.Errorf("%w: %q", ErrPointsFormat, s)
+	}
+
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("%w: %q", ErrPointsFormat, s)
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || units > math.MaxInt64/pointsScale-1 {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrPointsFormat, s)
+	}
+
+	var cents int64
+	if fracPart != "" {
+		fracPart += strings.Repeat("0", pointsFracSize-len(fracPart))
+		cents, _ = strconv.ParseInt(fracPart, 10, 64)
+	}
+
+	result := Points(units*pointsScale + cents)
+	if negative {
+		result = -result
+	}
+
+	return result, nil
+}
+
+func (p Points) String() string {
+	abs := int64(p)
+	sign := ""
+
+	if abs < 0 {
+		abs = -abs
+		sign = "-"
+	}
+
+	units := abs / pointsScale
+	cents := abs % pointsScale
+
+	if cents == 0 {
+		return fmt.Sprintf("%s%d", sign, units)
+	}
+
+	return strings.TrimRight(fmt.Sprintf("%s%d.%02d", sign, units, cents), "0")
+}
+
+func (p Points) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Points) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	v, err := ParsePoints(string(data))
+	if err != nil {
+		return err
+	}
+
+	*p = v
+	return nil
+}
+
+// UnmarshalText позволяет задавать баллы в переменных окружения.
+func (p *Points) UnmarshalText(text []byte) error {
+	return p.scanString(string(text))
+}
+
+// Scan реализует sql.Scanner для колонок NUMERIC.
+func (p *Points) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return p.scanString(v)
+	case []byte:
+		return p.scanString(string(v))
+	case int64:
+		*p = Points(v * pointsScale)
+		return nil
+	case nil:
+		*p = 0
+		return nil
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrPointsFormat, src)
+	}
+}
+
+// Value реализует driver.Valuer, значение передается в БД строкой.
+func (p Points) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+func (p *Points) scanString(s string) error {
+	v, err := ParsePoints(s)
+	if err != nil {
+		return err
+	}
+
+	*p = v
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Points
+		wantErr bool
+	}{
+		{name: "integer", in: "500", want: 50000},
+		{name: "one fractional digit", in: "500.5", want: 50050},
+		{name: "two fractional digits", in: "729.98", want: 72998},
+		{name: "negative", in: "-0.01", want: -1},
+		{name: "three fractional digits", in: "1.005", wantErr: true},
+		{name: "exponent", in: "1e2", wantErr: true},
+		{name: "empty fraction", in: "1.", wantErr: true},
+		{name: "empty integer part", in: ".5", wantErr: true},
+		{name: "not a number", in: "abc", wantErr: true},
+		{name: "out of range", in: "99999999999999999999", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParsePoints(test.in)
+
+			if test.wantErr {
+				assert.ErrorIs(t, err, ErrPointsFormat)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestPointsString(t *testing.T) {
+	tests := []struct {
+		in   Points
+		want string
+	}{
+		{in: 0, want: "0"},
+		{in: 4200, want: "42"},
+		{in: 50050, want: "500.5"},
+		{in: 72998, want: "729.98"},
+		{in: -1, want: "-0.01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			assert.Equal(t, test.want, test.in.String())
+		})
+	}
+}
+
+func TestPointsJSON(t *testing.T) {
+	var b Balance
+
+	err := json.Unmarshal([]byte(`{"current":500.5,"withdrawn":42}`), &b)
+	require.NoError(t, err)
+	assert.Equal(t, Balance{Current: 50050, Withdrawn: 4200}, b)
+
+	out, err := json.Marshal(b)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"current":500.5,"withdrawn":42}`, string(out))
+}
+
+func TestPointsScan(t *testing.T) {
+	var p Points
+
+	require.NoError(t, p.Scan("100.20"))
+	assert.Equal(t, Points(10020), p)
+
+	require.NoError(t, p.Scan([]byte("0.00")))
+	assert.Equal(t, Points(0), p)
+
+	assert.Error(t, p.Scan(1.5))
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+const notifyFilePerm = 0o600
+
+// LogNotifier дописывает уведомления в файл, а если файл не задан — в stderr.
+// Используется при локальной разработке вместо настоящей доставки.
+type LogNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogNotifier(path string) *LogNotifier {
+	return &LogNotifier{path: path}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, msg models.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.path == "" {
+		return writeNotification(os.Stderr, msg)
+	}
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, notifyFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open notify file: %w", err)
+	}
+
+	if err := writeNotification(f, msg); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close notify file: %w", err)
+	}
+
+	return nil
+}
+
+func writeNotification(w io.Writer, msg models.Notification) error {
+	_, err := fmt.Fprintf(w, "To: %s\nSubject: %s\n\n%s\n\n", msg.To, msg.Subject, msg.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	n := NewLogNotifier(path)
+
+	msg := models.Notification{To: "user@example.com", Subject: "Subject", Body: "Body"}
+	require.NoError(t, n.Notify(context.Background(), msg))
+	require.NoError(t, n.Notify(context.Background(), msg))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	entry := "To: user@example.com\nSubject: Subject\n\nBody\n\n"
+	assert.Equal(t, entry+entry, string(content))
+}
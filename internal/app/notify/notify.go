@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+var ErrInvalidRecipient = errors.New("invalid notification recipient")
+
+type Notifier interface {
+	Notify(ctx context.Context, n models.Notification) error
+}
+
+// New создает канал доставки уведомлений, выбранный в настройках.
+func New(settings *config.NotifySettings) (Notifier, error) {
+	switch settings.Driver {
+	case config.NotifyDriverSMTP:
+		return NewSMTPNotifier(&settings.SMTP), nil
+	case config.NotifyDriverLog:
+		return NewLogNotifier(settings.File), nil
+	default:
+		return nil, config.ErrNotifyDriver
+	}
+}
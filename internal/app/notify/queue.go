@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"go.uber.org/zap"
+)
+
+// Queue отправляет уведомления в фоне, чтобы ни код, ни время ответа на запрос не зависели от канала доставки.
+// Ошибки отправки только логируются. Если очередь заполнена, уведомление отбрасывается, а уведомления, не
+// отправленные до остановки сервиса, теряются.
+type Queue struct {
+	next   Notifier
+	logger *zap.Logger
+	queue  chan models.Notification
+}
+
+func NewQueue(next Notifier, logger *zap.Logger, size int) *Queue {
+	return &Queue{
+		next:   next,
+		logger: logger,
+		queue:  make(chan models.Notification, size),
+	}
+}
+
+// Start запускает отправку уведомлений до отмены ctx.
+func (q *Queue) Start(ctx context.Context) {
+	go q.run(ctx)
+}
+
+// Notify ставит уведомление в очередь и не ждет отправки. Ошибка не возвращается никогда.
+func (q *Queue) Notify(_ context.Context, n models.Notification) error {
+	select {
+	case q.queue <- n:
+	default:
+		q.logger.Error("notification queue is full, notification dropped", zap.String("subject", n.Subject))
+	}
+
+	return nil
+}
+
+func (q *Queue) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-q.queue:
+			if err := q.next.Notify(ctx, n); err != nil {
+				q.logger.Error("failed to send notification", zap.String("subject", n.Subject), zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// chanNotifier передает отправленные уведомления в канал и возвращает err.
+type chanNotifier struct {
+	err  error
+	sent chan models.Notification
+}
+
+func (n *chanNotifier) Notify(_ context.Context, msg models.Notification) error {
+	n.sent <- msg
+	return n.err
+}
+
+func TestQueue(t *testing.T) {
+	msg := models.Notification{To: "user@example.com", Subject: "Subject", Body: "Body"}
+
+	t.Run("sends in background and logs errors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		core, logs := observer.New(zap.ErrorLevel)
+		next := &chanNotifier{err: errors.New("smtp is down"), sent: make(chan models.Notification)}
+		q := NewQueue(next, zap.New(core), 1)
+		q.Start(ctx)
+
+		require.NoError(t, q.Notify(context.Background(), msg))
+		assert.Equal(t, msg, <-next.sent)
+
+		assert.Eventually(t, func() bool {
+			return logs.FilterMessage("failed to send notification").Len() == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("drops notifications when full", func(t *testing.T) {
+		core, logs := observer.New(zap.ErrorLevel)
+		q := NewQueue(&chanNotifier{}, zap.New(core), 1)
+
+		require.NoError(t, q.Notify(context.Background(), msg))
+		require.NoError(t, q.Notify(context.Background(), msg))
+
+		assert.Equal(t, 1, logs.FilterMessage("notification queue is full, notification dropped").Len())
+	})
+}
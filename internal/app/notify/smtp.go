@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+// SMTPNotifier отправляет уведомления письмами. Если сервер поддерживает STARTTLS, соединение шифруется.
+type SMTPNotifier struct {
+	settings *config.SMTPSettings
+}
+
+func NewSMTPNotifier(settings *config.SMTPSettings) *SMTPNotifier {
+	return &SMTPNotifier{settings: settings}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, msg models.Notification) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRecipient, err)
+	}
+
+	host, _, err := net.SplitHostPort(n.settings.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to parse SMTP address: %w", err)
+	}
+
+	dialer := net.Dialer{Timeout: n.settings.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", n.settings.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	deadline := time.Now().Add(n.settings.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to set SMTP deadline: %w", err)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer c.Close() //nolint:errcheck // После Quit соединение уже закрыто, ошибка повторного закрытия не важна
+
+	if err := n.send(c, host, to.Address, msg); err != nil {
+		return err
+	}
+
+	if err := c.Quit(); err != nil {
+		return fmt.Errorf("failed to quit SMTP session: %w", err)
+	}
+
+	return nil
+}
+
+func (n *SMTPNotifier) send(c *smtp.Client, host string, to string, msg models.Notification) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if n.settings.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.settings.Username, n.settings.Password, host)); err != nil {
+			return fmt.Errorf("failed to authenticate on SMTP server: %w", err)
+		}
+	}
+
+	if err := c.Mail(n.settings.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+
+	if _, err := w.Write(n.buildMessage(to, msg)); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+func (n *SMTPNotifier) buildMessage(to string, msg models.Notification) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", n.settings.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
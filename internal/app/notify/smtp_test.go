@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedMail struct {
+	from string
+	to   string
+	data string
+}
+
+// startSMTPServer поднимает минимальный SMTP-сервер, принимающий одно письмо без шифрования и авторизации.
+func startSMTPServer(t *testing.T) (string, <-chan receivedMail) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	mails := make(chan receivedMail, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close() //nolint:errcheck // Тестовый сервер
+
+		tp := textproto.NewConn(conn)
+		var m receivedMail
+
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				_ = tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				m.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				_ = tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				m.to = strings.Trim(line[len("RCPT TO:"):], "<>")
+				_ = tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				lines, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				m.data = strings.Join(lines, "\n")
+				_ = tp.PrintfLine("250 OK")
+			case cmd == "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				mails <- m
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+
+	return l.Addr().String(), mails
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, mails := startSMTPServer(t)
+
+	n := NewSMTPNotifier(&config.SMTPSettings{
+		Addr:    addr,
+		From:    "gophermart@localhost",
+		Timeout: time.Second,
+	})
+
+	err := n.Notify(context.Background(), models.Notification{
+		To:      "user@example.com",
+		Subject: "Сброс пароля",
+		Body:    "first line\nsecond line",
+	})
+	require.NoError(t, err)
+
+	m := <-mails
+	assert.Equal(t, "gophermart@localhost", m.from)
+	assert.Equal(t, "user@example.com", m.to)
+
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(m.data + "\n"))).ReadMIMEHeader()
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", msg.Get("To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Сброс пароля", subject)
+	assert.Contains(t, m.data, "\n\nfirst line\nsecond line")
+}
+
+func TestSMTPNotifierInvalidRecipient(t *testing.T) {
+	n := NewSMTPNotifier(&config.SMTPSettings{Addr: "127.0.0.1:1", Timeout: time.Second})
+
+	err := n.Notify(context.Background(), models.Notification{To: "not an address"})
+
+	assert.ErrorIs(t, err, ErrInvalidRecipient)
+}
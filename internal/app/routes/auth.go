@@ -5,34 +5,62 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/common"
-	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/data"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
-func authMiddleware(settings *config.Settings, l *zap.Logger, s Storager) func(next http.Handler) http.Handler {
+const (
+	AuthorizationHeader = "Authorization"
+	AuthTokenCookie     = "AUTH_TOKEN"
+	bearerScheme        = "Bearer"
+)
+
+var ErrInvalidAuthHeader = errors.New("invalid authorization header")
+
+func authMiddleware(v TokenVerifier, l *zap.Logger, s Storager) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authCookie, cookieErr := r.Cookie("AUTH_TOKEN")
-			if cookieErr != nil {
+			token, err := fetchToken(r)
+			if err != nil {
 				w.WriteHeader(http.StatusUnauthorized)
-				l.Error("failed to fetch auth token", zap.Error(cookieErr))
+				l.Error("failed to fetch auth token", zap.Error(err))
 				return
 			}
 
-			userID := getUserID(settings, authCookie.Value)
+			claims, err := parseToken(v, token)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				l.Error("failed to parse auth token", zap.Error(err))
+				return
+			}
+
+			session, err := s.GetSession(r.Context(), claims.SessionID)
+			if err != nil {
+				if errors.Is(err, data.ErrSessionNotFound) {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				w.WriteHeader(http.StatusUnauthorized)
+				l.Error("failed to get session from DB", zap.Error(err))
+				return
+			}
 
-			if userID == -1 {
+			if session.Revoked || session.UserID != claims.UserID || time.Now().After(session.ExpiresAt) {
 				w.WriteHeader(http.StatusUnauthorized)
-				l.Error("failed to parse auth token")
 				return
 			}
 
-			_, err := s.GetUserByID(r.Context(), userID)
+			userID := claims.UserID
+
+			user, err := s.GetUserByID(r.Context(), userID)
 			if err != nil {
 				if errors.Is(err, data.ErrUserNotFound) {
 					w.WriteHeader(http.StatusUnauthorized)
@@ -44,26 +72,67 @@ func authMiddleware(settings *config.Settings, l *zap.Logger, s Storager) func(n
 				return
 			}
 
+			// После смены роли токен со старой ролью отклоняется, клиент получит новый через refresh.
+			if user.Role != claims.UserRole() {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
 			newContext := context.WithValue(r.Context(), common.KeyUserID, userID)
+			newContext = context.WithValue(newContext, common.KeySessionID, session.ID)
+			newContext = context.WithValue(newContext, common.KeyUserRole, user.Role)
+			if claims.ExpiresAt != nil {
+				newContext = context.WithValue(newContext, common.KeyTokenExpiresAt, claims.ExpiresAt.Time)
+			}
 			newRequest := r.WithContext(newContext)
 			next.ServeHTTP(w, newRequest)
 		})
 	}
 }
 
-func getUserID(settings *config.Settings, tokenString string) int {
-	claims := &models.Claims{}
+// requireRole пропускает запрос, только если роль пользователя входит в roles. Используется после authMiddleware.
+func requireRole(roles ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(common.KeyUserRole).(string)
+
+			if !slices.Contains(roles, role) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+// fetchToken берет токен из заголовка Authorization, а при его отсутствии — из cookie.
+func fetchToken(r *http.Request) (string, error) {
+	if header := r.Header.Get(AuthorizationHeader); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, bearerScheme) || token == "" {
+			return "", ErrInvalidAuthHeader
 		}
-		return []byte(settings.SecretKey), nil
-	})
+
+		return token, nil
+	}
+
+	authCookie, err := r.Cookie(AuthTokenCookie)
+	if err != nil {
+		return "", fmt.Errorf("failed to read auth cookie: %w", err)
+	}
+
+	return authCookie.Value, nil
+}
+
+func parseToken(v TokenVerifier, tokenString string) (*models.Claims, error) {
+	claims := &models.Claims{}
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, v.Keyfunc, jwt.WithExpirationRequired(), jwt.WithIssuedAt())
 
 	if err != nil {
-		return -1
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	return claims.UserID
+	return claims, nil
 }
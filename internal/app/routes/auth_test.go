@@ -0,0 +1,211 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/routes/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/tokens"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFetchToken(t *testing.T) {
+	type want struct {
+		token string
+		err   bool
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		want   want
+	}{
+		{
+			name:   "bearer header",
+			header: "Bearer header-token",
+			want:   want{token: "header-token"},
+		},
+		{
+			name:   "header takes precedence over cookie",
+			header: "bearer header-token",
+			cookie: "cookie-token",
+			want:   want{token: "header-token"},
+		},
+		{
+			name:   "cookie",
+			cookie: "cookie-token",
+			want:   want{token: "cookie-token"},
+		},
+		{
+			name:   "unsupported scheme",
+			header: "Basic dXNlcjpwYXNz",
+			want:   want{err: true},
+		},
+		{
+			name:   "empty bearer token",
+			header: "Bearer ",
+			want:   want{err: true},
+		},
+		{
+			name: "no token",
+			want: want{err: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/user/orders", http.NoBody)
+			if test.header != "" {
+				request.Header.Set(AuthorizationHeader, test.header)
+			}
+			if test.cookie != "" {
+				request.AddCookie(&http.Cookie{Name: AuthTokenCookie, Value: test.cookie})
+			}
+
+			token, err := fetchToken(request)
+
+			if test.want.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.token, token)
+		})
+	}
+}
+
+func TestAuthMiddlewareRole(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockStorager(mockCtrl)
+	keys, err := tokens.NewKeyset("test", tokens.Key{ID: "test", Secret: "secret"})
+	require.NoError(t, err)
+
+	session := models.Session{ID: "session", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		tokenRole  string
+		userRole   string
+		wantRole   string
+		wantStatus int
+	}{
+		{
+			name:       "role matches",
+			tokenRole:  models.RoleAdmin,
+			userRole:   models.RoleAdmin,
+			wantRole:   models.RoleAdmin,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "token without role",
+			userRole:   models.RoleUser,
+			wantRole:   models.RoleUser,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "role changed after token was issued",
+			tokenRole:  models.RoleAdmin,
+			userRole:   models.RoleUser,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expiresAt := jwt.NewNumericDate(time.Now().Add(time.Minute))
+			token, err := keys.Sign(models.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					IssuedAt:  jwt.NewNumericDate(time.Now()),
+					ExpiresAt: expiresAt,
+				},
+				SessionID: session.ID,
+				Role:      test.tokenRole,
+				UserID:    session.UserID,
+			})
+			require.NoError(t, err)
+
+			_ = s.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(session, nil)
+			_ = s.EXPECT().
+				GetUserByID(gomock.Any(), session.UserID).
+				Times(1).
+				Return(models.User{ID: session.UserID, Role: test.userRole}, nil)
+
+			var (
+				gotRole      string
+				gotExpiresAt time.Time
+			)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRole, _ = r.Context().Value(common.KeyUserRole).(string)
+				gotExpiresAt, _ = r.Context().Value(common.KeyTokenExpiresAt).(time.Time)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/api/user/balance", http.NoBody)
+			request.Header.Set(AuthorizationHeader, "Bearer "+token)
+			w := httptest.NewRecorder()
+			authMiddleware(keys, zap.NewNop(), s)(next).ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			assert.Equal(t, test.wantRole, gotRole)
+			if test.wantStatus == http.StatusOK {
+				assert.True(t, expiresAt.Equal(gotExpiresAt))
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       any
+		wantStatus int
+	}{
+		{
+			name:       "admin",
+			role:       models.RoleAdmin,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "user",
+			role:       models.RoleUser,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "no role in context",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			request := httptest.NewRequest(http.MethodGet, "/api/admin/users", http.NoBody)
+			if test.role != nil {
+				request = request.WithContext(context.WithValue(request.Context(), common.KeyUserRole, test.role))
+			}
+			w := httptest.NewRecorder()
+			requireRole(models.RoleAdmin)(next).ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"go.uber.org/zap"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	minIdempotencyServerError = 500
+)
+
+type idempotencyResponseWriter struct {
+	http.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b) //nolint:wrapcheck // Нужно обернуть, но возврат должен остаться оригинальным
+}
+
+func (w *idempotencyResponseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func idempotencyMiddleware(settings *config.Settings, l *zap.Logger, s Storager) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+					return
+				}
+
+				w.WriteHeader(http.StatusBadRequest)
+				l.Error("failed to read request body", zap.Error(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := requestFingerprint(r, body)
+
+			stored, isNew, err := s.ReserveIdempotencyKey(r.Context(), key, fingerprint,
+				settings.Idempotency.KeyTTL, settings.Idempotency.LockTimeout)
+			if err != nil {
+				if errors.Is(err, data.ErrIdempotencyKeyBusy) {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+
+				w.WriteHeader(http.StatusInternalServerError)
+				l.Error("failed to reserve idempotency key", zap.Error(err))
+				return
+			}
+
+			if !isNew {
+				switch {
+				case stored.Fingerprint != fingerprint:
+					w.WriteHeader(http.StatusUnprocessableEntity)
+				case stored.StatusCode == 0:
+					w.WriteHeader(http.StatusConflict)
+				default:
+					replayResponse(w, l, stored.StatusCode, stored.ContentType, stored.Body)
+				}
+				return
+			}
+
+			ctx := context.WithoutCancel(r.Context())
+
+			// Паника в обработчике не должна оставлять ключ захваченным до истечения LockTimeout.
+			defer func() {
+				if rvr := recover(); rvr != nil {
+					releaseIdempotencyKey(ctx, l, s, key)
+					panic(rvr)
+				}
+			}()
+
+			iw := &idempotencyResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(iw, r)
+
+			if iw.status == 0 {
+				iw.status = http.StatusOK
+			}
+
+			if iw.status >= minIdempotencyServerError {
+				releaseIdempotencyKey(ctx, l, s, key)
+				return
+			}
+
+			contentType := iw.Header().Get(ContentTypeHeader)
+			if err := s.SaveIdempotencyResponse(ctx, key, iw.status, contentType, iw.body.Bytes()); err != nil {
+				l.Error("failed to save idempotency response", zap.Error(err))
+			}
+		})
+	}
+}
+
+func releaseIdempotencyKey(ctx context.Context, l *zap.Logger, s Storager, key string) {
+	if err := s.DeleteIdempotencyKey(ctx, key); err != nil {
+		l.Error("failed to release idempotency key", zap.Error(err))
+	}
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayResponse(w http.ResponseWriter, l *zap.Logger, statusCode int, contentType string, body []byte) {
+	if contentType != "" {
+		w.Header().Set(ContentTypeHeader, contentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(body); err != nil {
+		l.Error("failed to replay idempotent response", zap.Error(err))
+	}
+}
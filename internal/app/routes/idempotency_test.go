@@ -0,0 +1,251 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/routes/mocks"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockStorager(mockCtrl)
+	settings := &config.Settings{Idempotency: config.IdempotencySettings{KeyTTL: time.Hour, LockTimeout: time.Minute}}
+
+	const (
+		key         = "retry-1"
+		requestBody = "12345678903"
+	)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(requestBody))
+	fingerprint := requestFingerprint(request, []byte(requestBody))
+
+	type mResponse struct {
+		stored models.IdempotencyKey
+		isNew  bool
+		err    error
+	}
+
+	type want struct {
+		code         int
+		body         string
+		handlerCalls int
+		saveCalls    int
+		deleteCalls  int
+		replayed     string
+	}
+
+	tests := []struct {
+		name          string
+		handlerStatus int
+		mResponse     mResponse
+		want          want
+	}{
+		{
+			name:          "first request is stored",
+			handlerStatus: http.StatusAccepted,
+			mResponse:     mResponse{isNew: true},
+			want: want{
+				code:         http.StatusAccepted,
+				body:         "handled",
+				handlerCalls: 1,
+				saveCalls:    1,
+			},
+		},
+		{
+			name: "retry replays stored response",
+			mResponse: mResponse{
+				stored: models.IdempotencyKey{
+					Fingerprint: fingerprint,
+					StatusCode:  http.StatusAccepted,
+					Body:        []byte("handled"),
+				},
+			},
+			want: want{
+				code:     http.StatusAccepted,
+				body:     "handled",
+				replayed: "true",
+			},
+		},
+		{
+			name: "key reused with another body",
+			mResponse: mResponse{
+				stored: models.IdempotencyKey{Fingerprint: "other", StatusCode: http.StatusAccepted},
+			},
+			want: want{
+				code: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name: "key is still in progress",
+			mResponse: mResponse{
+				stored: models.IdempotencyKey{Fingerprint: fingerprint},
+			},
+			want: want{
+				code: http.StatusConflict,
+			},
+		},
+		{
+			name:      "key is busy in another transaction",
+			mResponse: mResponse{err: data.ErrIdempotencyKeyBusy},
+			want: want{
+				code: http.StatusConflict,
+			},
+		},
+		{
+			name:      "storage failed",
+			mResponse: mResponse{err: errors.New("some error")},
+			want: want{
+				code: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:          "server error releases key",
+			handlerStatus: http.StatusInternalServerError,
+			mResponse:     mResponse{isNew: true},
+			want: want{
+				code:         http.StatusInternalServerError,
+				body:         "handled",
+				handlerCalls: 1,
+				deleteCalls:  1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handlerCalls := 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerCalls++
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, requestBody, string(body))
+
+				w.WriteHeader(test.handlerStatus)
+				_, _ = w.Write([]byte("handled"))
+			})
+
+			_ = s.EXPECT().
+				ReserveIdempotencyKey(gomock.Any(), key, fingerprint, time.Hour, time.Minute).
+				Times(1).
+				Return(test.mResponse.stored, test.mResponse.isNew, test.mResponse.err)
+			_ = s.EXPECT().
+				SaveIdempotencyResponse(gomock.Any(), key, test.handlerStatus, "", []byte("handled")).
+				Times(test.want.saveCalls).
+				Return(nil)
+			_ = s.EXPECT().DeleteIdempotencyKey(gomock.Any(), key).Times(test.want.deleteCalls).Return(nil)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(requestBody))
+			request.Header.Set(IdempotencyKeyHeader, key)
+			w := httptest.NewRecorder()
+			idempotencyMiddleware(settings, zap.NewNop(), s)(next).ServeHTTP(w, request)
+
+			res := w.Result()
+			defer closeBody(t, res)
+
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want.code, res.StatusCode)
+			assert.Equal(t, test.want.body, string(resBody))
+			assert.Equal(t, test.want.replayed, res.Header.Get(IdempotentReplayedHeader))
+			assert.Equal(t, test.want.handlerCalls, handlerCalls)
+		})
+	}
+}
+
+func TestIdempotencyMiddlewarePanic(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockStorager(mockCtrl)
+	settings := &config.Settings{Idempotency: config.IdempotencySettings{KeyTTL: time.Hour, LockTimeout: time.Minute}}
+
+	_ = s.EXPECT().ReserveIdempotencyKey(gomock.Any(), "retry-1", gomock.Any(), time.Hour, time.Minute).
+		Times(1).
+		Return(models.IdempotencyKey{}, true, nil)
+	_ = s.EXPECT().SaveIdempotencyResponse(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	_ = s.EXPECT().DeleteIdempotencyKey(gomock.Any(), "retry-1").Times(1).Return(nil)
+
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader("12345678903"))
+	request.Header.Set(IdempotencyKeyHeader, "retry-1")
+	w := httptest.NewRecorder()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		idempotencyMiddleware(settings, zap.NewNop(), s)(next).ServeHTTP(w, request)
+	})
+}
+
+func TestIdempotencyMiddlewareWithoutKey(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	s := mocks.NewMockStorager(mockCtrl)
+	settings := &config.Settings{}
+
+	t.Run("request without key passes through", func(t *testing.T) {
+		_ = s.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader("12345678903"))
+		w := httptest.NewRecorder()
+		idempotencyMiddleware(settings, zap.NewNop(), s)(next).ServeHTTP(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+	})
+
+	t.Run("request with key and too large body", func(t *testing.T) {
+		_ = s.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/api/user/orders/batch", strings.NewReader("12345678903"))
+		request.Header.Set(IdempotencyKeyHeader, "retry-1")
+		w := httptest.NewRecorder()
+		middleware.RequestSize(4)(idempotencyMiddleware(settings, zap.NewNop(), s)(next)).ServeHTTP(w, request)
+
+		res := w.Result()
+		defer closeBody(t, res)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	})
+}
+
+func closeBody(t *testing.T, r *http.Response) {
+	t.Helper()
+	err := r.Body.Close()
+
+	if err != nil {
+		t.Log(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
 	"go.uber.org/zap"
 )
 
@@ -33,6 +34,11 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode
 }
 
+// Unwrap дает http.ResponseController доступ к исходному writer, например для отправки событий без буферизации.
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func requestLogging(l *zap.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -53,7 +59,10 @@ func requestLogging(l *zap.Logger) func(next http.Handler) http.Handler {
 
 			duration := time.Since(start)
 
+			requestID, _ := r.Context().Value(common.KeyRequestID).(string)
+
 			l.Info("got incoming HTTP request",
+				zap.String("request_id", requestID),
 				zap.String("uri", uri),
 				zap.String("method", method),
 				zap.String("duration", duration.String()),
@@ -0,0 +1,664 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/app/routes/routes.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/MihailSergeenkov/gophermart/internal/app/models"
+	jwt "github.com/golang-jwt/jwt/v5"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHandlerer is a mock of Handlerer interface.
+type MockHandlerer struct {
+	ctrl     *gomock.Controller
+	recorder *MockHandlererMockRecorder
+}
+
+// MockHandlererMockRecorder is the mock recorder for MockHandlerer.
+type MockHandlererMockRecorder struct {
+	mock *MockHandlerer
+}
+
+// NewMockHandlerer creates a new mock instance.
+func NewMockHandlerer(ctrl *gomock.Controller) *MockHandlerer {
+	mock := &MockHandlerer{ctrl: ctrl}
+	mock.recorder = &MockHandlererMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHandlerer) EXPECT() *MockHandlererMockRecorder {
+	return m.recorder
+}
+
+// AddOrder mocks base method.
+func (m *MockHandlerer) AddOrder() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrder")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// AddOrder indicates an expected call of AddOrder.
+func (mr *MockHandlererMockRecorder) AddOrder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockHandlerer)(nil).AddOrder))
+}
+
+// AddOrders mocks base method.
+func (m *MockHandlerer) AddOrders() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrders")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// AddOrders indicates an expected call of AddOrders.
+func (mr *MockHandlererMockRecorder) AddOrders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrders", reflect.TypeOf((*MockHandlerer)(nil).AddOrders))
+}
+
+// AddWithdraw mocks base method.
+func (m *MockHandlerer) AddWithdraw() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWithdraw")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// AddWithdraw indicates an expected call of AddWithdraw.
+func (mr *MockHandlererMockRecorder) AddWithdraw() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithdraw", reflect.TypeOf((*MockHandlerer)(nil).AddWithdraw))
+}
+
+// AdjustBalance mocks base method.
+func (m *MockHandlerer) AdjustBalance() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockHandlererMockRecorder) AdjustBalance() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockHandlerer)(nil).AdjustBalance))
+}
+
+// ChangePassword mocks base method.
+func (m *MockHandlerer) ChangePassword() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockHandlererMockRecorder) ChangePassword() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockHandlerer)(nil).ChangePassword))
+}
+
+// CompleteTwoFactorLogin mocks base method.
+func (m *MockHandlerer) CompleteTwoFactorLogin() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactorLogin")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// CompleteTwoFactorLogin indicates an expected call of CompleteTwoFactorLogin.
+func (mr *MockHandlererMockRecorder) CompleteTwoFactorLogin() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactorLogin", reflect.TypeOf((*MockHandlerer)(nil).CompleteTwoFactorLogin))
+}
+
+// ConfirmTwoFactor mocks base method.
+func (m *MockHandlerer) ConfirmTwoFactor() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTwoFactor")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// ConfirmTwoFactor indicates an expected call of ConfirmTwoFactor.
+func (mr *MockHandlererMockRecorder) ConfirmTwoFactor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTwoFactor", reflect.TypeOf((*MockHandlerer)(nil).ConfirmTwoFactor))
+}
+
+// CreateWebhook mocks base method.
+func (m *MockHandlerer) CreateWebhook() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockHandlererMockRecorder) CreateWebhook() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockHandlerer)(nil).CreateWebhook))
+}
+
+// DeleteUser mocks base method.
+func (m *MockHandlerer) DeleteUser() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockHandlererMockRecorder) DeleteUser() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockHandlerer)(nil).DeleteUser))
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockHandlerer) DeleteWebhook() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockHandlererMockRecorder) DeleteWebhook() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockHandlerer)(nil).DeleteWebhook))
+}
+
+// FindUsers mocks base method.
+func (m *MockHandlerer) FindUsers() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsers")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// FindUsers indicates an expected call of FindUsers.
+func (mr *MockHandlererMockRecorder) FindUsers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsers", reflect.TypeOf((*MockHandlerer)(nil).FindUsers))
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockHandlerer) GetAuditEvents() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockHandlererMockRecorder) GetAuditEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockHandlerer)(nil).GetAuditEvents))
+}
+
+// GetBalance mocks base method.
+func (m *MockHandlerer) GetBalance() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockHandlererMockRecorder) GetBalance() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockHandlerer)(nil).GetBalance))
+}
+
+// GetBalanceHistory mocks base method.
+func (m *MockHandlerer) GetBalanceHistory() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceHistory")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetBalanceHistory indicates an expected call of GetBalanceHistory.
+func (mr *MockHandlererMockRecorder) GetBalanceHistory() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceHistory", reflect.TypeOf((*MockHandlerer)(nil).GetBalanceHistory))
+}
+
+// GetCurrentUser mocks base method.
+func (m *MockHandlerer) GetCurrentUser() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentUser")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetCurrentUser indicates an expected call of GetCurrentUser.
+func (mr *MockHandlererMockRecorder) GetCurrentUser() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentUser", reflect.TypeOf((*MockHandlerer)(nil).GetCurrentUser))
+}
+
+// GetJWKS mocks base method.
+func (m *MockHandlerer) GetJWKS() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockHandlererMockRecorder) GetJWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockHandlerer)(nil).GetJWKS))
+}
+
+// GetOrder mocks base method.
+func (m *MockHandlerer) GetOrder() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockHandlererMockRecorder) GetOrder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockHandlerer)(nil).GetOrder))
+}
+
+// GetOrderEvents mocks base method.
+func (m *MockHandlerer) GetOrderEvents() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderEvents")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetOrderEvents indicates an expected call of GetOrderEvents.
+func (mr *MockHandlererMockRecorder) GetOrderEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderEvents", reflect.TypeOf((*MockHandlerer)(nil).GetOrderEvents))
+}
+
+// GetOrders mocks base method.
+func (m *MockHandlerer) GetOrders() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrders")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetOrders indicates an expected call of GetOrders.
+func (mr *MockHandlererMockRecorder) GetOrders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockHandlerer)(nil).GetOrders))
+}
+
+// GetUser mocks base method.
+func (m *MockHandlerer) GetUser() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockHandlererMockRecorder) GetUser() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockHandlerer)(nil).GetUser))
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockHandlerer) GetWebhookDeliveries() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockHandlererMockRecorder) GetWebhookDeliveries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockHandlerer)(nil).GetWebhookDeliveries))
+}
+
+// GetWebhooks mocks base method.
+func (m *MockHandlerer) GetWebhooks() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockHandlererMockRecorder) GetWebhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockHandlerer)(nil).GetWebhooks))
+}
+
+// GetWithdrawals mocks base method.
+func (m *MockHandlerer) GetWithdrawals() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawals")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// GetWithdrawals indicates an expected call of GetWithdrawals.
+func (mr *MockHandlererMockRecorder) GetWithdrawals() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockHandlerer)(nil).GetWithdrawals))
+}
+
+// LoginUser mocks base method.
+func (m *MockHandlerer) LoginUser() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginUser")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// LoginUser indicates an expected call of LoginUser.
+func (mr *MockHandlererMockRecorder) LoginUser() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockHandlerer)(nil).LoginUser))
+}
+
+// Logout mocks base method.
+func (m *MockHandlerer) Logout() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockHandlererMockRecorder) Logout() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockHandlerer)(nil).Logout))
+}
+
+// Ping mocks base method.
+func (m *MockHandlerer) Ping() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockHandlererMockRecorder) Ping() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockHandlerer)(nil).Ping))
+}
+
+// RecheckOrder mocks base method.
+func (m *MockHandlerer) RecheckOrder() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecheckOrder")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// RecheckOrder indicates an expected call of RecheckOrder.
+func (mr *MockHandlererMockRecorder) RecheckOrder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecheckOrder", reflect.TypeOf((*MockHandlerer)(nil).RecheckOrder))
+}
+
+// RefreshToken mocks base method.
+func (m *MockHandlerer) RefreshToken() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockHandlererMockRecorder) RefreshToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockHandlerer)(nil).RefreshToken))
+}
+
+// RegisterUser mocks base method.
+func (m *MockHandlerer) RegisterUser() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterUser")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// RegisterUser indicates an expected call of RegisterUser.
+func (mr *MockHandlererMockRecorder) RegisterUser() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockHandlerer)(nil).RegisterUser))
+}
+
+// ReplayWebhookDeliveries mocks base method.
+func (m *MockHandlerer) ReplayWebhookDeliveries() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayWebhookDeliveries")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// ReplayWebhookDeliveries indicates an expected call of ReplayWebhookDeliveries.
+func (mr *MockHandlererMockRecorder) ReplayWebhookDeliveries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayWebhookDeliveries", reflect.TypeOf((*MockHandlerer)(nil).ReplayWebhookDeliveries))
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockHandlerer) RequestPasswordReset() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockHandlererMockRecorder) RequestPasswordReset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockHandlerer)(nil).RequestPasswordReset))
+}
+
+// ResetOrder mocks base method.
+func (m *MockHandlerer) ResetOrder() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetOrder")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// ResetOrder indicates an expected call of ResetOrder.
+func (mr *MockHandlererMockRecorder) ResetOrder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetOrder", reflect.TypeOf((*MockHandlerer)(nil).ResetOrder))
+}
+
+// ResetPassword mocks base method.
+func (m *MockHandlerer) ResetPassword() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockHandlererMockRecorder) ResetPassword() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockHandlerer)(nil).ResetPassword))
+}
+
+// SetOrderStatus mocks base method.
+func (m *MockHandlerer) SetOrderStatus() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrderStatus")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// SetOrderStatus indicates an expected call of SetOrderStatus.
+func (mr *MockHandlererMockRecorder) SetOrderStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrderStatus", reflect.TypeOf((*MockHandlerer)(nil).SetOrderStatus))
+}
+
+// SetupTwoFactor mocks base method.
+func (m *MockHandlerer) SetupTwoFactor() http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetupTwoFactor")
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// SetupTwoFactor indicates an expected call of SetupTwoFactor.
+func (mr *MockHandlererMockRecorder) SetupTwoFactor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupTwoFactor", reflect.TypeOf((*MockHandlerer)(nil).SetupTwoFactor))
+}
+
+// MockStorager is a mock of Storager interface.
+type MockStorager struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoragerMockRecorder
+}
+
+// MockStoragerMockRecorder is the mock recorder for MockStorager.
+type MockStoragerMockRecorder struct {
+	mock *MockStorager
+}
+
+// NewMockStorager creates a new mock instance.
+func NewMockStorager(ctrl *gomock.Controller) *MockStorager {
+	mock := &MockStorager{ctrl: ctrl}
+	mock.recorder = &MockStoragerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorager) EXPECT() *MockStoragerMockRecorder {
+	return m.recorder
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStorager) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoragerMockRecorder) DeleteIdempotencyKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStorager)(nil).DeleteIdempotencyKey), ctx, key)
+}
+
+// GetSession mocks base method.
+func (m *MockStorager) GetSession(ctx context.Context, sessionID string) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", ctx, sessionID)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoragerMockRecorder) GetSession(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStorager)(nil).GetSession), ctx, sessionID)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStorager) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoragerMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStorager)(nil).GetUserByID), ctx, userID)
+}
+
+// ReserveIdempotencyKey mocks base method.
+func (m *MockStorager) ReserveIdempotencyKey(ctx context.Context, key, fingerprint string, ttl, lease time.Duration) (models.IdempotencyKey, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIdempotencyKey", ctx, key, fingerprint, ttl, lease)
+	ret0, _ := ret[0].(models.IdempotencyKey)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReserveIdempotencyKey indicates an expected call of ReserveIdempotencyKey.
+func (mr *MockStoragerMockRecorder) ReserveIdempotencyKey(ctx, key, fingerprint, ttl, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotencyKey", reflect.TypeOf((*MockStorager)(nil).ReserveIdempotencyKey), ctx, key, fingerprint, ttl, lease)
+}
+
+// SaveIdempotencyResponse mocks base method.
+func (m *MockStorager) SaveIdempotencyResponse(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotencyResponse", ctx, key, statusCode, contentType, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotencyResponse indicates an expected call of SaveIdempotencyResponse.
+func (mr *MockStoragerMockRecorder) SaveIdempotencyResponse(ctx, key, statusCode, contentType, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotencyResponse", reflect.TypeOf((*MockStorager)(nil).SaveIdempotencyResponse), ctx, key, statusCode, contentType, body)
+}
+
+// MockTokenVerifier is a mock of TokenVerifier interface.
+type MockTokenVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenVerifierMockRecorder
+}
+
+// MockTokenVerifierMockRecorder is the mock recorder for MockTokenVerifier.
+type MockTokenVerifierMockRecorder struct {
+	mock *MockTokenVerifier
+}
+
+// NewMockTokenVerifier creates a new mock instance.
+func NewMockTokenVerifier(ctrl *gomock.Controller) *MockTokenVerifier {
+	mock := &MockTokenVerifier{ctrl: ctrl}
+	mock.recorder = &MockTokenVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenVerifier) EXPECT() *MockTokenVerifierMockRecorder {
+	return m.recorder
+}
+
+// Keyfunc mocks base method.
+func (m *MockTokenVerifier) Keyfunc(t *jwt.Token) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Keyfunc", t)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Keyfunc indicates an expected call of Keyfunc.
+func (mr *MockTokenVerifierMockRecorder) Keyfunc(t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keyfunc", reflect.TypeOf((*MockTokenVerifier)(nil).Keyfunc), t)
+}
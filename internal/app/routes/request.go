@@ -0,0 +1,72 @@
+package routes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+)
+
+const (
+	RequestIDHeader     = "X-Request-ID"
+	maxRequestIDLength  = 64
+	requestIDRandomSize = 16
+)
+
+// requestContext кладет в контекст ID запроса и IP клиента, которые попадают в журнал аудита.
+// ID берется из заголовка X-Request-ID, если он корректен, иначе генерируется, и возвращается в ответе.
+// Если сгенерировать ID не удалось, запрос обрабатывается без него.
+func requestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		if requestID != "" {
+			w.Header().Set(RequestIDHeader, requestID)
+		}
+
+		ctx := context.WithValue(r.Context(), common.KeyRequestID, requestID)
+		ctx = context.WithValue(ctx, common.KeyClientIP, clientIP(r))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, requestIDRandomSize)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// clientIP берет адрес из соединения. Заголовкам X-Forwarded-For не доверяем, иначе ограничение попыток входа
+// по IP легко обойти.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantID    string
+		generated bool
+	}{
+		{
+			name:   "client request ID",
+			header: "req-1.a_b",
+			wantID: "req-1.a_b",
+		},
+		{
+			name:      "no request ID",
+			generated: true,
+		},
+		{
+			name:      "invalid characters",
+			header:    "req 1\n",
+			generated: true,
+		},
+		{
+			name:      "too long",
+			header:    strings.Repeat("a", maxRequestIDLength+1),
+			generated: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requestID, ip string
+			next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				requestID, _ = r.Context().Value(common.KeyRequestID).(string)
+				ip, _ = r.Context().Value(common.KeyClientIP).(string)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/api/user/balance", http.NoBody)
+			request.RemoteAddr = "192.0.2.1:12345"
+			if test.header != "" {
+				request.Header.Set(RequestIDHeader, test.header)
+			}
+			w := httptest.NewRecorder()
+
+			requestContext(next).ServeHTTP(w, request)
+
+			if test.generated {
+				assert.Len(t, requestID, 2*requestIDRandomSize)
+				assert.NotEqual(t, test.header, requestID)
+			} else {
+				assert.Equal(t, test.wantID, requestID)
+			}
+			assert.Equal(t, requestID, w.Header().Get(RequestIDHeader))
+			assert.Equal(t, "192.0.2.1", ip)
+		})
+	}
+}
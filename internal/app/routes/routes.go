@@ -2,37 +2,87 @@ package routes
 
 import (
 	"context"
+	"expvar"
 	"net/http"
+	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
+// maxOrderBatchBodySize совпадает с handlers.MaxOrderBatchBodySize.
+const maxOrderBatchBodySize = 1 << 20
+
 type Handlerer interface {
 	Ping() http.HandlerFunc
+	GetJWKS() http.HandlerFunc
 	RegisterUser() http.HandlerFunc
 	LoginUser() http.HandlerFunc
+	CompleteTwoFactorLogin() http.HandlerFunc
+	SetupTwoFactor() http.HandlerFunc
+	ConfirmTwoFactor() http.HandlerFunc
+	GetCurrentUser() http.HandlerFunc
+	ChangePassword() http.HandlerFunc
+	DeleteUser() http.HandlerFunc
+	RequestPasswordReset() http.HandlerFunc
+	ResetPassword() http.HandlerFunc
+	RefreshToken() http.HandlerFunc
+	Logout() http.HandlerFunc
+	AddOrders() http.HandlerFunc
 	GetOrders() http.HandlerFunc
+	GetOrder() http.HandlerFunc
+	GetOrderEvents() http.HandlerFunc
 	GetWithdrawals() http.HandlerFunc
 	AddOrder() http.HandlerFunc
 	GetBalance() http.HandlerFunc
+	GetBalanceHistory() http.HandlerFunc
 	AddWithdraw() http.HandlerFunc
+	FindUsers() http.HandlerFunc
+	GetUser() http.HandlerFunc
+	AdjustBalance() http.HandlerFunc
+	RecheckOrder() http.HandlerFunc
+	ResetOrder() http.HandlerFunc
+	SetOrderStatus() http.HandlerFunc
+	GetAuditEvents() http.HandlerFunc
+	CreateWebhook() http.HandlerFunc
+	GetWebhooks() http.HandlerFunc
+	DeleteWebhook() http.HandlerFunc
+	GetWebhookDeliveries() http.HandlerFunc
+	ReplayWebhookDeliveries() http.HandlerFunc
 }
 
 type Storager interface {
 	GetUserByID(ctx context.Context, userID int) (models.User, error)
+	GetSession(ctx context.Context, sessionID string) (models.Session, error)
+	ReserveIdempotencyKey(ctx context.Context,
+		key string, fingerprint string, ttl time.Duration, lease time.Duration) (models.IdempotencyKey, bool, error)
+	SaveIdempotencyResponse(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+}
+
+type TokenVerifier interface {
+	Keyfunc(t *jwt.Token) (interface{}, error)
 }
 
 var ContentTypeHeader = "Content-Type"
 var JSONContentType = "application/json"
 
-func NewRouter(h Handlerer, settings *config.Settings, l *zap.Logger, s Storager) chi.Router {
+func NewRouter(h Handlerer, settings *config.Settings, v TokenVerifier, l *zap.Logger, s Storager) chi.Router {
 	r := chi.NewRouter()
+	r.Use(requestContext)
 
 	r.Get("/ping", h.Ping())
+	r.Get("/.well-known/jwks.json", h.GetJWKS())
+
+	// Счетчики expvar (в том числе кеша пользователей) доступны только в режиме разработки:
+	// в них попадают аргументы командной строки, среди которых может быть секретный ключ.
+	if settings.DevMode {
+		r.Handle("/debug/vars", expvar.Handler())
+	}
 
 	r.Route("/api/user", func(r chi.Router) {
 		r.Use(requestLogging(l))
@@ -42,30 +92,89 @@ func NewRouter(h Handlerer, settings *config.Settings, l *zap.Logger, s Storager
 
 			r.Post("/register", h.RegisterUser())
 			r.Post("/login", h.LoginUser())
+			r.Post("/login/2fa", h.CompleteTwoFactorLogin())
+			r.Post("/password/reset-request", h.RequestPasswordReset())
+			r.Post("/password/reset", h.ResetPassword())
 		})
 
+		r.Post("/token/refresh", h.RefreshToken())
+
 		r.Group(func(r chi.Router) {
-			r.Use(authMiddleware(settings, l, s))
+			r.Use(authMiddleware(v, l, s))
+
+			r.Post("/logout", h.Logout())
+			r.Get("/me", h.GetCurrentUser())
+			r.With(middleware.AllowContentType(JSONContentType)).Put("/password", h.ChangePassword())
+			r.Delete("/", h.DeleteUser())
+
+			r.Route("/2fa", func(r chi.Router) {
+				r.Post("/setup", h.SetupTwoFactor())
+				r.With(middleware.AllowContentType(JSONContentType)).Post("/confirm", h.ConfirmTwoFactor())
+			})
 
 			r.Group(func(r chi.Router) {
 				r.Use(gzipMiddleware(l))
 
 				r.Get("/orders", h.GetOrders())
+				r.Get("/orders/{number}", h.GetOrder())
 				r.Get("/withdrawals", h.GetWithdrawals())
 			})
 
-			r.Post("/orders", h.AddOrder())
+			// Поток событий не сжимается: gzip буферизует вывод и задерживал бы события.
+			r.Get("/orders/events", h.GetOrderEvents())
+			r.With(idempotencyMiddleware(settings, l, s)).Post("/orders", h.AddOrder())
+			// Размер ограничивается после распаковки и до того, как idempotencyMiddleware прочитает тело целиком.
+			r.With(gzipMiddleware(l), middleware.RequestSize(maxOrderBatchBodySize), idempotencyMiddleware(settings, l, s)).
+				Post("/orders/batch", h.AddOrders())
 
 			r.Route("/balance", func(r chi.Router) {
 				r.Get("/", h.GetBalance())
+				r.With(gzipMiddleware(l)).Get("/history", h.GetBalanceHistory())
 
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.AllowContentType(JSONContentType))
+					r.Use(idempotencyMiddleware(settings, l, s))
 					r.Post("/withdraw", h.AddWithdraw())
 				})
 			})
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.With(middleware.AllowContentType(JSONContentType)).Post("/", h.CreateWebhook())
+				r.Get("/", h.GetWebhooks())
+				r.Delete("/{id}", h.DeleteWebhook())
+				r.Get("/{id}/deliveries", h.GetWebhookDeliveries())
+				r.Post("/{id}/replay", h.ReplayWebhookDeliveries())
+			})
 		})
 	})
 
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(requestLogging(l))
+		r.Use(authMiddleware(v, l, s))
+		r.Use(requireRole(models.RoleAdmin))
+
+		r.Route("/users", func(r chi.Router) {
+			r.Get("/", h.FindUsers())
+			r.Get("/{id}", h.GetUser())
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.AllowContentType(JSONContentType))
+				r.Use(idempotencyMiddleware(settings, l, s))
+				r.Post("/{id}/adjustments", h.AdjustBalance())
+			})
+		})
+
+		r.Route("/orders/{number}", func(r chi.Router) {
+			r.Use(middleware.AllowContentType(JSONContentType))
+			r.Use(idempotencyMiddleware(settings, l, s))
+
+			r.Post("/recheck", h.RecheckOrder())
+			r.Post("/reset", h.ResetOrder())
+			r.Post("/status", h.SetOrderStatus())
+		})
+
+		r.Get("/audit", h.GetAuditEvents())
+	})
+
 	return r
 }
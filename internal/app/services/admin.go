@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+var (
+	ErrAdminExists                = errors.New("admin already exists")
+	ErrUserNotFound               = errors.New("user not found")
+	ErrAdjustmentAmountValidation = errors.New("adjustment amount must not be zero")
+	ErrReasonValidation           = errors.New("reason is required")
+)
+
+const maxReasonLength = 500
+
+// BootstrapAdmin назначает первого администратора. Если пользователя с таким логином нет, он регистрируется
+// с переданными почтой и паролем, у существующего пользователя учетные данные не меняются.
+func (s *Services) BootstrapAdmin(ctx context.Context, req models.RegisterUserRequest) (models.User, error) {
+	exists, err := s.store.HasAdmin(ctx)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to check admins: %w", err)
+	}
+
+	if exists {
+		return models.User{}, ErrAdminExists
+	}
+
+	user, err := s.store.GetUserByLogin(ctx, req.Login)
+	if err != nil {
+		if !errors.Is(err, data.ErrUserNotFound) {
+			return models.User{}, fmt.Errorf("failed to get user from DB %w", err)
+		}
+
+		user, err = s.addUser(ctx, req)
+		if err != nil {
+			return models.User{}, err
+		}
+	}
+
+	if err := s.store.PromoteFirstAdmin(ctx, user.ID); err != nil {
+		if errors.Is(err, data.ErrAdminExists) {
+			return models.User{}, ErrAdminExists
+		}
+		return models.User{}, fmt.Errorf("failed to promote user: %w", err)
+	}
+
+	s.recordUserEvent(ctx, models.AuditRoleChanged, user.ID,
+		map[string]any{"role": user.Role}, map[string]any{"role": models.RoleAdmin})
+
+	user.Role = models.RoleAdmin
+
+	return user, nil
+}
+
+// FindUsers ищет пользователей по началу логина.
+func (s *Services) FindUsers(ctx context.Context, req models.UserSearchRequest) ([]models.UserProfile, error) {
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.store.FindUsersByLogin(ctx, req.Login, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+
+	profiles := make([]models.UserProfile, 0, len(users))
+	for _, u := range users {
+		profiles = append(profiles, userProfile(u))
+	}
+
+	return profiles, nil
+}
+
+// GetUserDetails собирает карточку пользователя с последними заказами. Методы хранилища читают пользователя
+// из контекста, поэтому запросы выполняются от имени просматриваемого пользователя.
+func (s *Services) GetUserDetails(ctx context.Context, userID int) (models.AdminUserDetails, error) {
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, data.ErrUserNotFound) {
+			return models.AdminUserDetails{}, ErrUserNotFound
+		}
+		return models.AdminUserDetails{}, fmt.Errorf("failed to get user from DB %w", err)
+	}
+
+	userCtx := context.WithValue(ctx, common.KeyUserID, user.ID)
+
+	balance, err := s.store.GetBalance(userCtx)
+	if err != nil {
+		return models.AdminUserDetails{}, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	orders, err := s.store.GetOrdersByUserID(userCtx, models.OrderQuery{Limit: defaultPageLimit, Desc: true})
+	if err != nil {
+		return models.AdminUserDetails{}, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	withdrawals, err := s.store.GetWithdrawals(userCtx)
+	if err != nil {
+		return models.AdminUserDetails{}, fmt.Errorf("failed to get withdrawals: %w", err)
+	}
+
+	return models.AdminUserDetails{
+		User:        userProfile(user),
+		Balance:     balance,
+		Orders:      orders,
+		Withdrawals: withdrawals,
+	}, nil
+}
+
+// AdjustBalance начисляет или списывает баллы пользователя от имени администратора из контекста.
+func (s *Services) AdjustBalance(ctx context.Context,
+	userID int, req models.BalanceAdjustmentRequest) (models.BalanceAdjustment, error) {
+	if req.Amount == 0 {
+		return models.BalanceAdjustment{}, ErrAdjustmentAmountValidation
+	}
+
+	reason, err := adminReason(req.Reason)
+	if err != nil {
+		return models.BalanceAdjustment{}, err
+	}
+
+	adj, err := s.store.AdjustBalance(ctx, userID, req.Amount, reason)
+	if err != nil {
+		if errors.Is(err, data.ErrUserNotFound) {
+			return models.BalanceAdjustment{}, ErrUserNotFound
+		}
+
+		if errors.Is(err, data.ErrUserInsufficientFunds) {
+			return models.BalanceAdjustment{}, ErrInsufficientFunds
+		}
+		return models.BalanceAdjustment{}, fmt.Errorf("failed to adjust balance: %w", err)
+	}
+
+	s.recordUserEvent(ctx, models.AuditBalanceAdjustment, userID,
+		map[string]any{"balance": adj.BalanceAfter - adj.Amount},
+		map[string]any{"balance": adj.BalanceAfter, "amount": adj.Amount, "reason": adj.Reason, "adjustment_id": adj.ID})
+
+	return adj, nil
+}
+
+// adminReason проверяет обязательную причину ручного изменения, которая сохраняется в аудите.
+func adminReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxReasonLength {
+		return "", fmt.Errorf("reason must be 1 to %d characters: %w", maxReasonLength, ErrReasonValidation)
+	}
+
+	return reason, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/clients"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+var (
+	ErrOrderNotFound            = errors.New("order not found")
+	ErrOrderStatusValidation    = errors.New("order status or accrual has not been validated")
+	ErrAccrualOrderUnregistered = errors.New("order is not registered in accrual system")
+	ErrAccrualUnavailable       = errors.New("accrual system is unavailable")
+)
+
+// RecheckOrder запрашивает актуальный статус заказа в системе начислений и применяет его независимо
+// от текущего статуса, в том числе к заказам с итоговым статусом.
+func (s *Services) RecheckOrder(ctx context.Context,
+	number string, req models.OrderOverrideRequest) (models.OrderOverride, error) {
+	reason, err := adminReason(req.Reason)
+	if err != nil {
+		return models.OrderOverride{}, err
+	}
+
+	status, accrual, err := s.accrual.GetOrderAccrual(number)
+	if err != nil {
+		if errors.Is(err, clients.ErrOrderRegistered) {
+			return models.OrderOverride{}, ErrAccrualOrderUnregistered
+		}
+		return models.OrderOverride{}, fmt.Errorf("%w: %w", ErrAccrualUnavailable, err)
+	}
+
+	orderStatus, ok := clients.OrderStatuses[status]
+	if !ok {
+		return models.OrderOverride{}, fmt.Errorf("%w: unknown status %q", ErrAccrualUnavailable, status)
+	}
+
+	return s.overrideOrder(ctx, models.OrderOverride{
+		OrderNumber: number,
+		Action:      models.OrderActionRecheck,
+		NewStatus:   orderStatus,
+		NewAccrual:  accrual,
+		Reason:      reason,
+	})
+}
+
+// ResetOrder возвращает заказ в статус NEW, чтобы его заново обработала фоновая задача.
+// Начисленные за заказ баллы списываются.
+func (s *Services) ResetOrder(ctx context.Context,
+	number string, req models.OrderOverrideRequest) (models.OrderOverride, error) {
+	reason, err := adminReason(req.Reason)
+	if err != nil {
+		return models.OrderOverride{}, err
+	}
+
+	return s.overrideOrder(ctx, models.OrderOverride{
+		OrderNumber: number,
+		Action:      models.OrderActionReset,
+		NewStatus:   "NEW",
+		Reason:      reason,
+	})
+}
+
+// SetOrderStatus устанавливает заказу итоговый статус PROCESSED или INVALID с указанным начислением.
+func (s *Services) SetOrderStatus(ctx context.Context,
+	number string, req models.OrderStatusRequest) (models.OrderOverride, error) {
+	switch {
+	case req.Status != "PROCESSED" && req.Status != "INVALID":
+		return models.OrderOverride{}, fmt.Errorf("status must be PROCESSED or INVALID: %w", ErrOrderStatusValidation)
+	case req.Accrual < 0:
+		return models.OrderOverride{}, fmt.Errorf("negative accrual: %w", ErrOrderStatusValidation)
+	case req.Status == "INVALID" && req.Accrual != 0:
+		return models.OrderOverride{}, fmt.Errorf("invalid order with accrual: %w", ErrOrderStatusValidation)
+	}
+
+	reason, err := adminReason(req.Reason)
+	if err != nil {
+		return models.OrderOverride{}, err
+	}
+
+	return s.overrideOrder(ctx, models.OrderOverride{
+		OrderNumber: number,
+		Action:      models.OrderActionSetStatus,
+		NewStatus:   req.Status,
+		NewAccrual:  req.Accrual,
+		Reason:      reason,
+	})
+}
+
+func (s *Services) overrideOrder(ctx context.Context, override models.OrderOverride) (models.OrderOverride, error) {
+	res, err := s.store.OverrideOrder(ctx, override)
+	if err != nil {
+		if errors.Is(err, data.ErrOrderNotFound) {
+			return models.OrderOverride{}, ErrOrderNotFound
+		}
+
+		if errors.Is(err, data.ErrUserInsufficientFunds) {
+			return models.OrderOverride{}, ErrInsufficientFunds
+		}
+		return models.OrderOverride{}, fmt.Errorf("failed to override order: %w", err)
+	}
+
+	s.auditor.Record(ctx, models.AuditEvent{
+		Action:     models.AuditOrderOverride,
+		TargetType: models.AuditTargetOrder,
+		TargetID:   res.OrderNumber,
+		UserID:     res.UserID,
+		Before:     map[string]any{"status": res.OldStatus, "accrual": res.OldAccrual},
+		After: map[string]any{
+			"status":        res.NewStatus,
+			"accrual":       res.NewAccrual,
+			"action":        res.Action,
+			"reason":        res.Reason,
+			"balance_delta": res.BalanceDelta,
+		},
+	})
+
+	return res, nil
+}
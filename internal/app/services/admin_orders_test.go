@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/clients"
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecheckOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	accrual := mocks.NewMockAccrualChecker(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), accrual, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	errSome := errors.New("some error")
+
+	type accrualResponse struct {
+		err     error
+		status  string
+		accrual models.Points
+	}
+
+	tests := []struct {
+		wantErr         error
+		override        *models.OrderOverride
+		name            string
+		reason          string
+		accrualResponse accrualResponse
+		accrualTimes    int
+	}{
+		{
+			name:            "recheck processed order",
+			reason:          "stuck order",
+			accrualResponse: accrualResponse{status: "PROCESSED", accrual: 500},
+			accrualTimes:    1,
+			override: &models.OrderOverride{
+				OrderNumber: "12345678903",
+				Action:      models.OrderActionRecheck,
+				NewStatus:   "PROCESSED",
+				NewAccrual:  500,
+				Reason:      "stuck order",
+			},
+		},
+		{
+			name:            "registered order becomes processing",
+			reason:          "stuck order",
+			accrualResponse: accrualResponse{status: "REGISTERED"},
+			accrualTimes:    1,
+			override: &models.OrderOverride{
+				OrderNumber: "12345678903",
+				Action:      models.OrderActionRecheck,
+				NewStatus:   "PROCESSING",
+				Reason:      "stuck order",
+			},
+		},
+		{
+			name:    "empty reason",
+			wantErr: ErrReasonValidation,
+		},
+		{
+			name:            "order is not registered",
+			reason:          "stuck order",
+			accrualResponse: accrualResponse{err: clients.ErrOrderRegistered},
+			accrualTimes:    1,
+			wantErr:         ErrAccrualOrderUnregistered,
+		},
+		{
+			name:            "accrual system failed",
+			reason:          "stuck order",
+			accrualResponse: accrualResponse{err: errSome},
+			accrualTimes:    1,
+			wantErr:         ErrAccrualUnavailable,
+		},
+		{
+			name:            "unknown accrual status",
+			reason:          "stuck order",
+			accrualResponse: accrualResponse{status: "UNKNOWN"},
+			accrualTimes:    1,
+			wantErr:         ErrAccrualUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = accrual.EXPECT().
+				GetOrderAccrual("12345678903").
+				Times(test.accrualTimes).
+				Return(test.accrualResponse.status, test.accrualResponse.accrual, test.accrualResponse.err)
+
+			if test.override != nil {
+				_ = store.EXPECT().OverrideOrder(ctx, *test.override).Times(1).Return(*test.override, nil)
+			}
+
+			res, err := s.RecheckOrder(ctx, "12345678903", models.OrderOverrideRequest{Reason: test.reason})
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, *test.override, res)
+		})
+	}
+}
+
+func TestResetOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	override := models.OrderOverride{
+		OrderNumber: "12345678903",
+		Action:      models.OrderActionReset,
+		NewStatus:   "NEW",
+		Reason:      "wrongly invalid",
+	}
+
+	tests := []struct {
+		storeErr error
+		wantErr  error
+		name     string
+	}{
+		{
+			name: "reset success",
+		},
+		{
+			name:     "order not found",
+			storeErr: data.ErrOrderNotFound,
+			wantErr:  ErrOrderNotFound,
+		},
+		{
+			name:     "accrual already spent",
+			storeErr: data.ErrUserInsufficientFunds,
+			wantErr:  ErrInsufficientFunds,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().OverrideOrder(ctx, override).Times(1).Return(override, test.storeErr)
+
+			_, err := s.ResetOrder(ctx, "12345678903", models.OrderOverrideRequest{Reason: " wrongly invalid "})
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSetOrderStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+
+	tests := []struct {
+		wantErr    error
+		name       string
+		req        models.OrderStatusRequest
+		storeTimes int
+	}{
+		{
+			name:       "set processed",
+			req:        models.OrderStatusRequest{Status: "PROCESSED", Accrual: 700, Reason: "manual check"},
+			storeTimes: 1,
+		},
+		{
+			name:       "set invalid",
+			req:        models.OrderStatusRequest{Status: "INVALID", Reason: "manual check"},
+			storeTimes: 1,
+		},
+		{
+			name:    "status is not final",
+			req:     models.OrderStatusRequest{Status: "PROCESSING", Reason: "manual check"},
+			wantErr: ErrOrderStatusValidation,
+		},
+		{
+			name:    "negative accrual",
+			req:     models.OrderStatusRequest{Status: "PROCESSED", Accrual: -1, Reason: "manual check"},
+			wantErr: ErrOrderStatusValidation,
+		},
+		{
+			name:    "invalid order with accrual",
+			req:     models.OrderStatusRequest{Status: "INVALID", Accrual: 100, Reason: "manual check"},
+			wantErr: ErrOrderStatusValidation,
+		},
+		{
+			name:    "empty reason",
+			req:     models.OrderStatusRequest{Status: "PROCESSED", Accrual: 700},
+			wantErr: ErrReasonValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			override := models.OrderOverride{
+				OrderNumber: "12345678903",
+				Action:      models.OrderActionSetStatus,
+				NewStatus:   test.req.Status,
+				NewAccrual:  test.req.Accrual,
+				Reason:      test.req.Reason,
+			}
+			_ = store.EXPECT().OverrideOrder(ctx, override).Times(test.storeTimes).Return(override, nil)
+
+			res, err := s.SetOrderStatus(ctx, "12345678903", test.req)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.req.Status, res.NewStatus)
+		})
+	}
+}
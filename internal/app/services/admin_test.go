@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapAdmin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.Credentials.PasswordMinLength = 8
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	errSome := errors.New("some error")
+	existing := models.User{ID: 1, Login: "admin", Role: models.RoleUser}
+
+	type mResponse struct {
+		userErr      error
+		promoteErr   error
+		user         models.User
+		hasAdmin     bool
+		userTimes    int
+		addTimes     int
+		promoteTimes int
+	}
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		password  string
+		mResponse mResponse
+	}{
+		{
+			name:      "promote existing user",
+			mResponse: mResponse{user: existing, userTimes: 1, promoteTimes: 1},
+		},
+		{
+			name:      "create new admin",
+			password:  "Secret123",
+			mResponse: mResponse{userErr: data.ErrUserNotFound, userTimes: 1, addTimes: 1, promoteTimes: 1},
+		},
+		{
+			name:      "new admin with weak password",
+			password:  "short",
+			mResponse: mResponse{userErr: data.ErrUserNotFound, userTimes: 1},
+			wantErr:   ErrUserValidationFields,
+		},
+		{
+			name:      "admin already exists",
+			mResponse: mResponse{hasAdmin: true},
+			wantErr:   ErrAdminExists,
+		},
+		{
+			name: "admin promoted concurrently",
+			mResponse: mResponse{
+				user: existing, userTimes: 1, promoteTimes: 1, promoteErr: data.ErrAdminExists,
+			},
+			wantErr: ErrAdminExists,
+		},
+		{
+			name:      "storage failed",
+			mResponse: mResponse{userErr: errSome, userTimes: 1},
+			wantErr:   errSome,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().HasAdmin(ctx).Times(1).Return(test.mResponse.hasAdmin, nil)
+			_ = store.EXPECT().
+				GetUserByLogin(ctx, "admin").
+				Times(test.mResponse.userTimes).
+				Return(test.mResponse.user, test.mResponse.userErr)
+			_ = store.EXPECT().
+				AddUser(ctx, "admin", "", gomock.Any()).
+				Times(test.mResponse.addTimes).
+				Return(models.User{ID: 1, Login: "admin", Role: models.RoleUser}, nil)
+			_ = store.EXPECT().PromoteFirstAdmin(ctx, 1).Times(test.mResponse.promoteTimes).Return(test.mResponse.promoteErr)
+
+			user, err := s.BootstrapAdmin(ctx, models.RegisterUserRequest{Login: "admin", Password: test.password})
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, user.ID)
+			assert.Equal(t, models.RoleAdmin, user.Role)
+		})
+	}
+}
+
+func TestFindUsers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+
+	t.Run("default limit", func(t *testing.T) {
+		_ = store.EXPECT().
+			FindUsersByLogin(ctx, "ali", defaultPageLimit).
+			Times(1).
+			Return([]models.User{{ID: 1, Login: "alice", Role: models.RoleUser, Password: []byte("hash")}}, nil)
+
+		users, err := s.FindUsers(ctx, models.UserSearchRequest{Login: "ali"})
+		require.NoError(t, err)
+		assert.Equal(t, []models.UserProfile{{ID: 1, Login: "alice", Role: models.RoleUser}}, users)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := s.FindUsers(ctx, models.UserSearchRequest{Login: "ali", Limit: maxPageLimit + 1})
+		assert.ErrorIs(t, err, ErrPaginationValidation)
+	})
+}
+
+func TestGetUserDetails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+
+	t.Run("details success", func(t *testing.T) {
+		userCtx := gomock.AssignableToTypeOf(ctx)
+		balance := models.Balance{Current: 500, Withdrawn: 100}
+		orders := []models.Order{{Number: "12345678903", Status: "PROCESSED", UserID: 2}}
+		withdrawals := []models.Withdraw{{OrderNumber: "2377225624", Sum: 100}}
+
+		_ = store.EXPECT().GetUserByID(ctx, 2).Times(1).Return(models.User{ID: 2, Login: "bob"}, nil)
+		_ = store.EXPECT().GetBalance(userCtx).Times(1).DoAndReturn(
+			func(c context.Context) (models.Balance, error) {
+				assert.Equal(t, 2, c.Value(common.KeyUserID))
+				return balance, nil
+			})
+		_ = store.EXPECT().
+			GetOrdersByUserID(userCtx, models.OrderQuery{Limit: defaultPageLimit, Desc: true}).
+			Times(1).
+			Return(orders, nil)
+		_ = store.EXPECT().GetWithdrawals(userCtx).Times(1).Return(withdrawals, nil)
+
+		res, err := s.GetUserDetails(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, models.AdminUserDetails{
+			User:        models.UserProfile{ID: 2, Login: "bob"},
+			Balance:     balance,
+			Orders:      orders,
+			Withdrawals: withdrawals,
+		}, res)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		_ = store.EXPECT().GetUserByID(ctx, 3).Times(1).Return(models.User{}, data.ErrUserNotFound)
+
+		_, err := s.GetUserDetails(ctx, 3)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestAdjustBalance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	errSome := errors.New("some error")
+
+	tests := []struct {
+		storeErr   error
+		wantErr    error
+		name       string
+		req        models.BalanceAdjustmentRequest
+		storeTimes int
+	}{
+		{
+			name:       "credit success",
+			req:        models.BalanceAdjustmentRequest{Amount: 1000, Reason: "  goodwill  "},
+			storeTimes: 1,
+		},
+		{
+			name:    "zero amount",
+			req:     models.BalanceAdjustmentRequest{Amount: 0, Reason: "goodwill"},
+			wantErr: ErrAdjustmentAmountValidation,
+		},
+		{
+			name:    "empty reason",
+			req:     models.BalanceAdjustmentRequest{Amount: 1000, Reason: "   "},
+			wantErr: ErrReasonValidation,
+		},
+		{
+			name:    "too long reason",
+			req:     models.BalanceAdjustmentRequest{Amount: 1000, Reason: strings.Repeat("я", maxReasonLength+1)},
+			wantErr: ErrReasonValidation,
+		},
+		{
+			name:       "user not found",
+			req:        models.BalanceAdjustmentRequest{Amount: 1000, Reason: "goodwill"},
+			storeErr:   data.ErrUserNotFound,
+			storeTimes: 1,
+			wantErr:    ErrUserNotFound,
+		},
+		{
+			name:       "debit more than balance",
+			req:        models.BalanceAdjustmentRequest{Amount: -1000, Reason: "goodwill"},
+			storeErr:   data.ErrUserInsufficientFunds,
+			storeTimes: 1,
+			wantErr:    ErrInsufficientFunds,
+		},
+		{
+			name:       "storage failed",
+			req:        models.BalanceAdjustmentRequest{Amount: 1000, Reason: "goodwill"},
+			storeErr:   errSome,
+			storeTimes: 1,
+			wantErr:    errSome,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().
+				AdjustBalance(ctx, 2, test.req.Amount, "goodwill").
+				Times(test.storeTimes).
+				Return(models.BalanceAdjustment{ID: 1, UserID: 2, AdminID: 1, Amount: test.req.Amount}, test.storeErr)
+
+			res, err := s.AdjustBalance(ctx, 2, test.req)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, res.AdminID)
+			assert.Equal(t, test.req.Amount, res.Amount)
+		})
+	}
+}
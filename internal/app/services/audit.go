@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+var ErrAuditRangeValidation = errors.New("audit time range has not been validated")
+
+// GetAuditEvents возвращает события журнала аудита. Без верхней границы периода выбираются события
+// до текущего момента, без нижней — с самого начала.
+func (s *Services) GetAuditEvents(ctx context.Context, q models.AuditQuery) ([]models.AuditEvent, error) {
+	limit, err := pageLimit(q.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.AfterID < 0 || q.UserID < 0 {
+		return nil, fmt.Errorf("negative cursor or user ID: %w", ErrPaginationValidation)
+	}
+
+	q.Limit = limit
+
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrAuditRangeValidation)
+	}
+
+	events, err := s.store.GetAuditEvents(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// recordUserEvent записывает в журнал аудита событие, затронувшее учетную запись пользователя.
+// Нулевой userID означает, что пользователь неизвестен, например при входе с несуществующим логином.
+func (s *Services) recordUserEvent(ctx context.Context, action string, userID int, before any, after any) {
+	e := models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		UserID:     userID,
+		Before:     before,
+		After:      after,
+	}
+
+	if userID != 0 {
+		e.TargetID = strconv.Itoa(userID)
+	}
+
+	s.auditor.Record(ctx, e)
+}
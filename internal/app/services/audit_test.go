@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAuditEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		wantErr    error
+		name       string
+		q          models.AuditQuery
+		storeTimes int
+	}{
+		{
+			name:       "range and user",
+			q:          models.AuditQuery{UserID: 2, From: from, To: to, Limit: 10},
+			storeTimes: 1,
+		},
+		{
+			name:       "no range",
+			q:          models.AuditQuery{},
+			storeTimes: 1,
+		},
+		{
+			name:    "from after to",
+			q:       models.AuditQuery{From: to, To: from},
+			wantErr: ErrAuditRangeValidation,
+		},
+		{
+			name:    "negative cursor",
+			q:       models.AuditQuery{AfterID: -1},
+			wantErr: ErrPaginationValidation,
+		},
+		{
+			name:    "limit out of range",
+			q:       models.AuditQuery{Limit: maxPageLimit + 1},
+			wantErr: ErrPaginationValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got models.AuditQuery
+			_ = store.EXPECT().GetAuditEvents(ctx, gomock.Any()).Times(test.storeTimes).
+				DoAndReturn(func(_ context.Context, q models.AuditQuery) ([]models.AuditEvent, error) {
+					got = q
+					return []models.AuditEvent{}, nil
+				})
+
+			_, err := s.GetAuditEvents(ctx, test.q)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.q.UserID, got.UserID)
+			assert.Equal(t, test.q.From, got.From)
+			assert.Positive(t, got.Limit)
+			assert.False(t, got.To.IsZero())
+		})
+	}
+}
+
+func TestAuditRecords(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	auditor := mocks.NewMockAuditor(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, auditor, nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+
+	t.Run("balance adjustment", func(t *testing.T) {
+		adj := models.BalanceAdjustment{ID: 7, UserID: 2, Amount: 300, BalanceAfter: 1300, Reason: "goodwill"}
+		_ = store.EXPECT().AdjustBalance(ctx, 2, models.Points(300), "goodwill").Times(1).Return(adj, nil)
+		_ = auditor.EXPECT().Record(ctx, models.AuditEvent{
+			Action:     models.AuditBalanceAdjustment,
+			TargetType: models.AuditTargetUser,
+			TargetID:   "2",
+			UserID:     2,
+			Before:     map[string]any{"balance": models.Points(1000)},
+			After: map[string]any{
+				"balance": models.Points(1300), "amount": models.Points(300), "reason": "goodwill", "adjustment_id": int64(7),
+			},
+		}).Times(1)
+
+		_, err := s.AdjustBalance(ctx, 2, models.BalanceAdjustmentRequest{Amount: 300, Reason: "goodwill"})
+		require.NoError(t, err)
+	})
+
+	t.Run("user deleted", func(t *testing.T) {
+		_ = store.EXPECT().AnonymizeUser(ctx).Times(1).Return(nil)
+		_ = auditor.EXPECT().Record(ctx, models.AuditEvent{
+			Action:     models.AuditUserDeleted,
+			TargetType: models.AuditTargetUser,
+			TargetID:   "1",
+			UserID:     1,
+		}).Times(1)
+
+		require.NoError(t, s.DeleteUser(ctx))
+	})
+}
@@ -2,11 +2,19 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 )
 
+var ErrPaginationValidation = errors.New("pagination params have not been validated")
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
 func (s *Services) GetBalance(ctx context.Context) (models.Balance, error) {
 	balance, err := s.store.GetBalance(ctx)
 	if err != nil {
@@ -15,3 +23,34 @@ func (s *Services) GetBalance(ctx context.Context) (models.Balance, error) {
 
 	return balance, nil
 }
+
+func (s *Services) GetBalanceHistory(ctx context.Context,
+	req models.BalanceHistoryRequest) ([]models.LedgerEntry, error) {
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AfterID < 0 {
+		return nil, fmt.Errorf("negative cursor: %w", ErrPaginationValidation)
+	}
+
+	entries, err := s.store.GetLedgerEntries(ctx, req.AfterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func pageLimit(limit int) (int, error) {
+	if limit == 0 {
+		return defaultPageLimit, nil
+	}
+
+	if limit < 0 || limit > maxPageLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d: %w", maxPageLimit, ErrPaginationValidation)
+	}
+
+	return limit, nil
+}
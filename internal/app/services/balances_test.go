@@ -18,7 +18,7 @@ func TestSuccessGetBalance(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	balance := models.Balance{
@@ -41,7 +41,7 @@ func TestFailedGetBalance(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	balance := models.Balance{}
@@ -55,3 +55,95 @@ func TestFailedGetBalance(t *testing.T) {
 		assert.ErrorContains(t, err, "failed to get balance", "some error")
 	})
 }
+
+func TestGetBalanceHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	entries := []models.LedgerEntry{
+		{
+			ID:           1,
+			Kind:         models.LedgerKindAccrual,
+			Amount:       50000,
+			BalanceAfter: 50000,
+			OrderNumber:  "12345678903",
+		},
+	}
+
+	type mCall struct {
+		afterID int64
+		limit   int
+		times   int
+	}
+
+	tests := []struct {
+		name  string
+		req   models.BalanceHistoryRequest
+		mCall mCall
+		err   error
+	}{
+		{
+			name:  "default limit",
+			req:   models.BalanceHistoryRequest{},
+			mCall: mCall{afterID: 0, limit: defaultPageLimit, times: 1},
+		},
+		{
+			name:  "custom limit and cursor",
+			req:   models.BalanceHistoryRequest{AfterID: 10, Limit: 5},
+			mCall: mCall{afterID: 10, limit: 5, times: 1},
+		},
+		{
+			name: "too large limit",
+			req:  models.BalanceHistoryRequest{Limit: maxPageLimit + 1},
+			err:  ErrPaginationValidation,
+		},
+		{
+			name: "negative cursor",
+			req:  models.BalanceHistoryRequest{AfterID: -1},
+			err:  ErrPaginationValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().
+				GetLedgerEntries(ctx, test.mCall.afterID, test.mCall.limit).
+				Times(test.mCall.times).
+				Return(entries, nil)
+
+			result, err := s.GetBalanceHistory(ctx, test.req)
+
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, entries, result)
+		})
+	}
+}
+
+func TestFailedGetBalanceHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	errSome := errors.New("some error")
+
+	_ = store.EXPECT().GetLedgerEntries(ctx, int64(0), defaultPageLimit).Times(1).Return(nil, errSome)
+
+	t.Run("get balance history failed", func(t *testing.T) {
+		_, err := s.GetBalanceHistory(ctx, models.BalanceHistoryRequest{})
+		assert.ErrorContains(t, err, "failed to get ledger entries")
+	})
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+// maxPasswordBytes — ограничение bcrypt, более длинные пароли он молча обрезает.
+const maxPasswordBytes = 72
+
+// maxEmailLength совпадает с размером колонки users.email.
+const maxEmailLength = 254
+
+const (
+	RuleRequired     = "required"
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleCharset      = "charset"
+	RuleCharClasses  = "char_classes"
+	RuleSameAsLogin  = "same_as_login"
+	RuleFormat       = "format"
+	fieldLogin       = "login"
+	fieldEmail       = "email"
+	fieldPassword    = "password"
+	fieldNewPassword = "new_password"
+	loginCharsetDesc = "latin letters, digits and . _ - @ +"
+)
+
+var loginCharset = regexp.MustCompile(`^[A-Za-z0-9._@+-]+$`)
+
+// ValidationError перечисляет все нарушенные правила, чтобы клиент мог показать их разом.
+type ValidationError struct {
+	Violations []models.Violation
+}
+
+func (e *ValidationError) Error() string {
+	rules := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		rules = append(rules, v.Field+":"+v.Rule)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrUserValidationFields, strings.Join(rules, ", "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrUserValidationFields
+}
+
+func validateCredentials(policy config.CredentialsSettings, login string, email string, password string) error {
+	var violations []models.Violation
+
+	violations = append(violations, validateLogin(policy, login)...)
+	violations = append(violations, validateEmail(email)...)
+	violations = append(violations, validatePassword(policy, fieldPassword, login, password)...)
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
+
+func validateLogin(policy config.CredentialsSettings, login string) []models.Violation {
+	if login == "" {
+		return []models.Violation{{Field: fieldLogin, Rule: RuleRequired, Message: "login is required"}}
+	}
+
+	var violations []models.Violation
+
+	maxLength := policy.LoginMaxLength
+	if maxLength <= 0 || maxLength > config.MaxLoginLength {
+		maxLength = config.MaxLoginLength
+	}
+
+	length := len([]rune(login))
+	if length < policy.LoginMinLength {
+		violations = append(violations, models.Violation{
+			Field:   fieldLogin,
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("login must be at least %d characters", policy.LoginMinLength),
+		})
+	}
+
+	if length > maxLength {
+		violations = append(violations, models.Violation{
+			Field:   fieldLogin,
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("login must be at most %d characters", maxLength),
+		})
+	}
+
+	if !loginCharset.MatchString(login) {
+		violations = append(violations, models.Violation{
+			Field:   fieldLogin,
+			Rule:    RuleCharset,
+			Message: "login may contain only " + loginCharsetDesc,
+		})
+	}
+
+	return violations
+}
+
+// validateEmail проверяет необязательный адрес почты. Допускается только сам адрес, без имени получателя.
+func validateEmail(email string) []models.Violation {
+	if email == "" {
+		return nil
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > maxEmailLength {
+		return []models.Violation{{Field: fieldEmail, Rule: RuleFormat, Message: "email must be a valid address"}}
+	}
+
+	return nil
+}
+
+func validatePassword(policy config.CredentialsSettings,
+	field string, login string, password string) []models.Violation {
+	if password == "" {
+		return []models.Violation{{Field: field, Rule: RuleRequired, Message: "password is required"}}
+	}
+
+	var violations []models.Violation
+
+	if len([]rune(password)) < policy.PasswordMinLength {
+		violations = append(violations, models.Violation{
+			Field:   field,
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("password must be at least %d characters", policy.PasswordMinLength),
+		})
+	}
+
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, models.Violation{
+			Field:   field,
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("password must be at most %d bytes", maxPasswordBytes),
+		})
+	}
+
+	if charClasses(password) < policy.PasswordMinClasses {
+		violations = append(violations, models.Violation{
+			Field: field,
+			Rule:  RuleCharClasses,
+			Message: fmt.Sprintf("password must contain at least %d of: lowercase letters, uppercase letters, digits, symbols",
+				policy.PasswordMinClasses),
+		})
+	}
+
+	if strings.EqualFold(password, login) {
+		violations = append(violations, models.Violation{
+			Field:   field,
+			Rule:    RuleSameAsLogin,
+			Message: "password must not match login",
+		})
+	}
+
+	return violations
+}
+
+// charClasses считает, сколько групп символов (строчные, заглавные, цифры, прочие) встречается в пароле.
+func charClasses(password string) int {
+	var lower, upper, digit, other int
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+
+	return lower + upper + digit + other
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCredentials(t *testing.T) {
+	policy := config.CredentialsSettings{
+		LoginMinLength:     3,
+		LoginMaxLength:     20,
+		PasswordMinLength:  8,
+		PasswordMinClasses: 3,
+	}
+
+	type violation struct {
+		field string
+		rule  string
+	}
+
+	tests := []struct {
+		name     string
+		login    string
+		email    string
+		password string
+		want     []violation
+	}{
+		{
+			name:     "valid credentials",
+			login:    "user.name@example",
+			password: "Secret123",
+		},
+		{
+			name: "empty fields",
+			want: []violation{{fieldLogin, RuleRequired}, {fieldPassword, RuleRequired}},
+		},
+		{
+			name:     "short login with invalid characters",
+			login:    "a!",
+			password: "Secret123",
+			want:     []violation{{fieldLogin, RuleMinLength}, {fieldLogin, RuleCharset}},
+		},
+		{
+			name:     "long login",
+			login:    strings.Repeat("a", 21),
+			password: "Secret123",
+			want:     []violation{{fieldLogin, RuleMaxLength}},
+		},
+		{
+			name:     "weak password",
+			login:    "user",
+			password: "secret",
+			want:     []violation{{fieldPassword, RuleMinLength}, {fieldPassword, RuleCharClasses}},
+		},
+		{
+			name:     "password over bcrypt limit",
+			login:    "user",
+			password: strings.Repeat("Aa1", 25),
+			want:     []violation{{fieldPassword, RuleMaxLength}},
+		},
+		{
+			name:     "valid email",
+			login:    "user",
+			email:    "user@example.com",
+			password: "Secret123",
+		},
+		{
+			name:     "email with display name",
+			login:    "user",
+			email:    "User <user@example.com>",
+			password: "Secret123",
+			want:     []violation{{fieldEmail, RuleFormat}},
+		},
+		{
+			name:     "email without domain",
+			login:    "user",
+			email:    "user",
+			password: "Secret123",
+			want:     []violation{{fieldEmail, RuleFormat}},
+		},
+		{
+			name:     "password equals login",
+			login:    "User123abc",
+			password: "user123ABC",
+			want:     []violation{{fieldPassword, RuleSameAsLogin}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateCredentials(policy, test.login, test.email, test.password)
+
+			if len(test.want) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.ErrorIs(t, err, ErrUserValidationFields)
+
+			got := make([]violation, 0, len(validationErr.Violations))
+			for _, v := range validationErr.Violations {
+				got = append(got, violation{v.Field, v.Rule})
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestValidateLoginMaxLengthCap(t *testing.T) {
+	err := validateCredentials(config.CredentialsSettings{LoginMaxLength: 1000}, strings.Repeat("a", 201), "", "Secret123")
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, RuleMaxLength, validationErr.Violations[0].Rule)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+var ErrUserLoginLocked = errors.New("login is temporarily locked")
+
+// maxLockoutShift ограничивает показатель степени, чтобы удвоение длительности блокировки не переполнялось.
+const maxLockoutShift = 30
+
+// LoginLockedError сообщает, до какого момента вход заблокирован.
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrUserLoginLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrUserLoginLocked
+}
+
+// RetryAfter возвращает время до снятия блокировки.
+func (e *LoginLockedError) RetryAfter() time.Duration {
+	return time.Until(e.Until)
+}
+
+func (s *Services) checkLoginLock(ctx context.Context, req models.LoginUserRequest) error {
+	if !s.settings.LoginThrottle.Enabled() {
+		return nil
+	}
+
+	ip, _ := ctx.Value(common.KeyClientIP).(string)
+
+	lockedUntil, err := s.store.GetLoginLockedUntil(ctx, req.Login, ip)
+	if err != nil {
+		return fmt.Errorf("failed to get login lock: %w", err)
+	}
+
+	if time.Now().Before(lockedUntil) {
+		return &LoginLockedError{Until: lockedUntil}
+	}
+
+	return nil
+}
+
+// registerLoginFailure учитывает неудачную попытку по логину и по IP и при превышении порога блокирует вход.
+// Возвращает ошибку, которую нужно отдать клиенту.
+func (s *Services) registerLoginFailure(ctx context.Context, req models.LoginUserRequest) error {
+	policy := s.settings.LoginThrottle
+	if !policy.Enabled() {
+		return ErrUserLoginCreds
+	}
+
+	ip, _ := ctx.Value(common.KeyClientIP).(string)
+
+	subjects := []struct {
+		scope       string
+		subject     string
+		maxFailures int
+	}{
+		{scope: models.LoginScopeLogin, subject: req.Login, maxFailures: policy.MaxLoginFailures},
+		{scope: models.LoginScopeIP, subject: ip, maxFailures: policy.MaxIPFailures},
+	}
+
+	windowStart := time.Now().Add(-policy.FailureWindow)
+	var lockedUntil time.Time
+
+	for _, sub := range subjects {
+		if sub.subject == "" || sub.maxFailures <= 0 {
+			continue
+		}
+
+		failures, err := s.store.AddLoginFailure(ctx, sub.scope, sub.subject, windowStart)
+		if err != nil {
+			return fmt.Errorf("failed to add login failure: %w", err)
+		}
+
+		lockout := lockoutDuration(failures, sub.maxFailures, policy.BaseLockout, policy.MaxLockout)
+		if lockout == 0 {
+			continue
+		}
+
+		until := time.Now().Add(lockout)
+		if err := s.store.LockLogin(ctx, sub.scope, sub.subject, failures, until); err != nil {
+			return fmt.Errorf("failed to lock login: %w", err)
+		}
+
+		if until.After(lockedUntil) {
+			lockedUntil = until
+		}
+	}
+
+	if !lockedUntil.IsZero() {
+		return &LoginLockedError{Until: lockedUntil}
+	}
+
+	return ErrUserLoginCreds
+}
+
+func (s *Services) resetLoginFailures(ctx context.Context, req models.LoginUserRequest) error {
+	if !s.settings.LoginThrottle.Enabled() {
+		return nil
+	}
+
+	if err := s.store.ResetLoginFailures(ctx, req.Login); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+// checkTOTPLock проверяет блокировку ввода кода второго фактора при списании.
+func (s *Services) checkTOTPLock(ctx context.Context, userID int) error {
+	if s.settings.TwoFactor.MaxWithdrawFailures <= 0 {
+		return nil
+	}
+
+	lockedUntil, err := s.store.GetThrottleLockedUntil(ctx, models.LoginScopeTOTP, strconv.Itoa(userID))
+	if err != nil {
+		return fmt.Errorf("failed to get TOTP lock: %w", err)
+	}
+
+	if time.Now().Before(lockedUntil) {
+		return &TOTPLockedError{Until: lockedUntil}
+	}
+
+	return nil
+}
+
+// registerTOTPFailure учитывает неверный код второго фактора при списании и при превышении порога блокирует
+// ввод кода. Окно и длительность блокировки берутся из настроек блокировки входа.
+// Возвращает ошибку, которую нужно отдать клиенту.
+func (s *Services) registerTOTPFailure(ctx context.Context, userID int) error {
+	maxFailures := s.settings.TwoFactor.MaxWithdrawFailures
+	if maxFailures <= 0 {
+		return ErrTOTPInvalid
+	}
+
+	policy := s.settings.LoginThrottle
+	subject := strconv.Itoa(userID)
+
+	failures, err := s.store.AddLoginFailure(ctx, models.LoginScopeTOTP, subject, time.Now().Add(-policy.FailureWindow))
+	if err != nil {
+		return fmt.Errorf("failed to add TOTP failure: %w", err)
+	}
+
+	lockout := lockoutDuration(failures, maxFailures, policy.BaseLockout, policy.MaxLockout)
+	if lockout == 0 {
+		return ErrTOTPInvalid
+	}
+
+	until := time.Now().Add(lockout)
+	if err := s.store.LockLogin(ctx, models.LoginScopeTOTP, subject, failures, until); err != nil {
+		return fmt.Errorf("failed to lock TOTP: %w", err)
+	}
+
+	return &TOTPLockedError{Until: until}
+}
+
+// lockoutDuration удваивает блокировку за каждую неудачную попытку сверх порога, но не больше maxLockout.
+func lockoutDuration(failures int, maxFailures int, base time.Duration, maxLockout time.Duration) time.Duration {
+	if failures < maxFailures {
+		return 0
+	}
+
+	shift := failures - maxFailures
+	if shift > maxLockoutShift {
+		return maxLockout
+	}
+
+	lockout := base << shift
+	if lockout <= 0 || lockout > maxLockout {
+		return maxLockout
+	}
+
+	return lockout
+}
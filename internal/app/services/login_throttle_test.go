@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginUserThrottle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.LoginThrottle = config.LoginThrottleSettings{
+		FailureWindow:    15 * time.Minute,
+		BaseLockout:      time.Minute,
+		MaxLockout:       time.Hour,
+		MaxLoginFailures: 3,
+		MaxIPFailures:    10,
+	}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyClientIP, "192.0.2.1")
+	user := models.User{
+		ID:       1,
+		Login:    "test",
+		Password: []byte("$2a$10$eqoHdZljD4bk/zPKKGAPre6Mmq2mj8XxSrjF4SpavRy.pT/uxijYa"),
+	}
+
+	t.Run("too long login is rejected without throttle", func(t *testing.T) {
+		login := strings.Repeat("a", config.MaxLoginLength+1)
+		_ = store.EXPECT().GetLoginLockedUntil(ctx, gomock.Any(), gomock.Any()).Times(0)
+		_ = store.EXPECT().GetUserByLogin(ctx, gomock.Any()).Times(0)
+		_ = store.EXPECT().AddLoginFailure(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := s.LoginUser(ctx, models.LoginUserRequest{Login: login, Password: "test"})
+
+		assert.ErrorIs(t, err, ErrUserLoginCreds)
+	})
+
+	t.Run("locked login is rejected without password check", func(t *testing.T) {
+		lockedUntil := time.Now().Add(time.Minute)
+		_ = store.EXPECT().GetLoginLockedUntil(ctx, "test", "192.0.2.1").Times(1).Return(lockedUntil, nil)
+		_ = store.EXPECT().GetUserByLogin(ctx, gomock.Any()).Times(0)
+
+		_, err := s.LoginUser(ctx, models.LoginUserRequest{Login: "test", Password: "test"})
+
+		var lockedErr *LoginLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.ErrorIs(t, err, ErrUserLoginLocked)
+		assert.Equal(t, lockedUntil, lockedErr.Until)
+	})
+
+	t.Run("failure below threshold", func(t *testing.T) {
+		_ = store.EXPECT().GetLoginLockedUntil(ctx, "test", "192.0.2.1").Times(1).Return(time.Time{}, nil)
+		_ = store.EXPECT().GetUserByLogin(ctx, "test").Times(1).Return(user, nil)
+		_ = store.EXPECT().AddLoginFailure(ctx, models.LoginScopeLogin, "test", gomock.Any()).Times(1).Return(2, nil)
+		_ = store.EXPECT().AddLoginFailure(ctx, models.LoginScopeIP, "192.0.2.1", gomock.Any()).Times(1).Return(2, nil)
+		_ = store.EXPECT().LockLogin(ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := s.LoginUser(ctx, models.LoginUserRequest{Login: "test", Password: "wrong"})
+
+		assert.ErrorIs(t, err, ErrUserLoginCreds)
+	})
+
+	t.Run("failure reaching threshold locks login", func(t *testing.T) {
+		_ = store.EXPECT().GetLoginLockedUntil(ctx, "test", "192.0.2.1").Times(1).Return(time.Time{}, nil)
+		_ = store.EXPECT().GetUserByLogin(ctx, "test").Times(1).Return(user, nil)
+		_ = store.EXPECT().AddLoginFailure(ctx, models.LoginScopeLogin, "test", gomock.Any()).Times(1).Return(4, nil)
+		_ = store.EXPECT().AddLoginFailure(ctx, models.LoginScopeIP, "192.0.2.1", gomock.Any()).Times(1).Return(4, nil)
+		_ = store.EXPECT().LockLogin(ctx, models.LoginScopeLogin, "test", 4, gomock.Any()).Times(1).Return(nil)
+
+		start := time.Now()
+		_, err := s.LoginUser(ctx, models.LoginUserRequest{Login: "test", Password: "wrong"})
+
+		var lockedErr *LoginLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.WithinDuration(t, start.Add(2*time.Minute), lockedErr.Until, time.Second)
+	})
+
+	t.Run("storage error while recording failure", func(t *testing.T) {
+		errSome := errors.New("some error")
+		// Без адреса клиента попытка учитывается только по логину.
+		noIPCtx := context.Background()
+		_ = store.EXPECT().GetLoginLockedUntil(noIPCtx, "test", "").Times(1).Return(time.Time{}, nil)
+		_ = store.EXPECT().GetUserByLogin(noIPCtx, "test").Times(1).Return(user, nil)
+		_ = store.EXPECT().AddLoginFailure(noIPCtx, models.LoginScopeLogin, "test", gomock.Any()).Times(1).
+			Return(0, errSome)
+
+		_, err := s.LoginUser(noIPCtx, models.LoginUserRequest{Login: "test", Password: "wrong"})
+
+		assert.ErrorIs(t, err, errSome)
+	})
+
+	t.Run("success resets failures", func(t *testing.T) {
+		_ = store.EXPECT().GetLoginLockedUntil(ctx, "test", "192.0.2.1").Times(1).Return(time.Now().Add(-time.Minute), nil)
+		_ = store.EXPECT().GetUserByLogin(ctx, "test").Times(1).Return(user, nil)
+		_ = store.EXPECT().ResetLoginFailures(ctx, "test").Times(1).Return(nil)
+		_ = store.EXPECT().RehashPassword(ctx, user.ID, user.Password, gomock.Any()).Times(1).Return(nil)
+		_ = store.EXPECT().GetTOTP(ctx, user.ID).Times(1).Return(models.TOTP{}, data.ErrTOTPNotFound)
+		_ = store.EXPECT().AddSession(ctx, gomock.Any()).Times(1).Return(nil)
+
+		result, err := s.LoginUser(ctx, models.LoginUserRequest{Login: "test", Password: "test"})
+
+		require.NoError(t, err)
+		assertAuthToken(t, result.AuthToken, user.ID)
+	})
+}
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "below threshold", failures: 2, want: 0},
+		{name: "at threshold", failures: 3, want: time.Minute},
+		{name: "doubles after threshold", failures: 5, want: 4 * time.Minute},
+		{name: "capped", failures: 20, want: time.Hour},
+		{name: "overflow is capped", failures: 200, want: time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, lockoutDuration(test.failures, 3, time.Minute, time.Hour))
+		})
+	}
+}
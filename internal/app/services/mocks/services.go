@@ -6,12 +6,253 @@ package mocks
 
 import (
 	context "context"
+	netip "net/netip"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/MihailSergeenkov/gophermart/internal/app/models"
+	jwt "github.com/golang-jwt/jwt/v5"
 	gomock "github.com/golang/mock/gomock"
 )
 
+// MockTokenSigner is a mock of TokenSigner interface.
+type MockTokenSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenSignerMockRecorder
+}
+
+// MockTokenSignerMockRecorder is the mock recorder for MockTokenSigner.
+type MockTokenSignerMockRecorder struct {
+	mock *MockTokenSigner
+}
+
+// NewMockTokenSigner creates a new mock instance.
+func NewMockTokenSigner(ctrl *gomock.Controller) *MockTokenSigner {
+	mock := &MockTokenSigner{ctrl: ctrl}
+	mock.recorder = &MockTokenSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenSigner) EXPECT() *MockTokenSignerMockRecorder {
+	return m.recorder
+}
+
+// JWKS mocks base method.
+func (m *MockTokenSigner) JWKS() models.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(models.JWKSet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockTokenSignerMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockTokenSigner)(nil).JWKS))
+}
+
+// Sign mocks base method.
+func (m *MockTokenSigner) Sign(claims jwt.Claims) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", claims)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockTokenSignerMockRecorder) Sign(claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockTokenSigner)(nil).Sign), claims)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockNotifier) Notify(ctx context.Context, n models.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockNotifierMockRecorder) Notify(ctx, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), ctx, n)
+}
+
+// MockAccrualChecker is a mock of AccrualChecker interface.
+type MockAccrualChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualCheckerMockRecorder
+}
+
+// MockAccrualCheckerMockRecorder is the mock recorder for MockAccrualChecker.
+type MockAccrualCheckerMockRecorder struct {
+	mock *MockAccrualChecker
+}
+
+// NewMockAccrualChecker creates a new mock instance.
+func NewMockAccrualChecker(ctrl *gomock.Controller) *MockAccrualChecker {
+	mock := &MockAccrualChecker{ctrl: ctrl}
+	mock.recorder = &MockAccrualCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualChecker) EXPECT() *MockAccrualCheckerMockRecorder {
+	return m.recorder
+}
+
+// GetOrderAccrual mocks base method.
+func (m *MockAccrualChecker) GetOrderAccrual(number string) (string, models.Points, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderAccrual", number)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(models.Points)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOrderAccrual indicates an expected call of GetOrderAccrual.
+func (mr *MockAccrualCheckerMockRecorder) GetOrderAccrual(number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderAccrual", reflect.TypeOf((*MockAccrualChecker)(nil).GetOrderAccrual), number)
+}
+
+// MockAuditor is a mock of Auditor interface.
+type MockAuditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditorMockRecorder
+}
+
+// MockAuditorMockRecorder is the mock recorder for MockAuditor.
+type MockAuditorMockRecorder struct {
+	mock *MockAuditor
+}
+
+// NewMockAuditor creates a new mock instance.
+func NewMockAuditor(ctrl *gomock.Controller) *MockAuditor {
+	mock := &MockAuditor{ctrl: ctrl}
+	mock.recorder = &MockAuditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditor) EXPECT() *MockAuditorMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditor) Record(ctx context.Context, e models.AuditEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, e)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditorMockRecorder) Record(ctx, e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditor)(nil).Record), ctx, e)
+}
+
+// MockhostResolver is a mock of hostResolver interface.
+type MockhostResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockhostResolverMockRecorder
+}
+
+// MockhostResolverMockRecorder is the mock recorder for MockhostResolver.
+type MockhostResolverMockRecorder struct {
+	mock *MockhostResolver
+}
+
+// NewMockhostResolver creates a new mock instance.
+func NewMockhostResolver(ctrl *gomock.Controller) *MockhostResolver {
+	mock := &MockhostResolver{ctrl: ctrl}
+	mock.recorder = &MockhostResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhostResolver) EXPECT() *MockhostResolverMockRecorder {
+	return m.recorder
+}
+
+// LookupNetIP mocks base method.
+func (m *MockhostResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupNetIP", ctx, network, host)
+	ret0, _ := ret[0].([]netip.Addr)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupNetIP indicates an expected call of LookupNetIP.
+func (mr *MockhostResolverMockRecorder) LookupNetIP(ctx, network, host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupNetIP", reflect.TypeOf((*MockhostResolver)(nil).LookupNetIP), ctx, network, host)
+}
+
+// MockOrderUpdatesSubscriber is a mock of OrderUpdatesSubscriber interface.
+type MockOrderUpdatesSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderUpdatesSubscriberMockRecorder
+}
+
+// MockOrderUpdatesSubscriberMockRecorder is the mock recorder for MockOrderUpdatesSubscriber.
+type MockOrderUpdatesSubscriberMockRecorder struct {
+	mock *MockOrderUpdatesSubscriber
+}
+
+// NewMockOrderUpdatesSubscriber creates a new mock instance.
+func NewMockOrderUpdatesSubscriber(ctrl *gomock.Controller) *MockOrderUpdatesSubscriber {
+	mock := &MockOrderUpdatesSubscriber{ctrl: ctrl}
+	mock.recorder = &MockOrderUpdatesSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderUpdatesSubscriber) EXPECT() *MockOrderUpdatesSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockOrderUpdatesSubscriber) Subscribe(ctx context.Context, userID int) <-chan models.OrderUpdate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, userID)
+	ret0, _ := ret[0].(<-chan models.OrderUpdate)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockOrderUpdatesSubscriberMockRecorder) Subscribe(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockOrderUpdatesSubscriber)(nil).Subscribe), ctx, userID)
+}
+
 // MockStorager is a mock of Storager interface.
 type MockStorager struct {
 	ctrl     *gomock.Controller
@@ -35,6 +276,35 @@ func (m *MockStorager) EXPECT() *MockStoragerMockRecorder {
 	return m.recorder
 }
 
+// AddLoginChallenge mocks base method.
+func (m *MockStorager) AddLoginChallenge(ctx context.Context, challenge models.LoginChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLoginChallenge", ctx, challenge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddLoginChallenge indicates an expected call of AddLoginChallenge.
+func (mr *MockStoragerMockRecorder) AddLoginChallenge(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoginChallenge", reflect.TypeOf((*MockStorager)(nil).AddLoginChallenge), ctx, challenge)
+}
+
+// AddLoginFailure mocks base method.
+func (m *MockStorager) AddLoginFailure(ctx context.Context, scope, subject string, windowStart time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLoginFailure", ctx, scope, subject, windowStart)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddLoginFailure indicates an expected call of AddLoginFailure.
+func (mr *MockStoragerMockRecorder) AddLoginFailure(ctx, scope, subject, windowStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLoginFailure", reflect.TypeOf((*MockStorager)(nil).AddLoginFailure), ctx, scope, subject, windowStart)
+}
+
 // AddOrder mocks base method.
 func (m *MockStorager) AddOrder(ctx context.Context, number string) (models.Order, bool, error) {
 	m.ctrl.T.Helper()
@@ -51,33 +321,121 @@ func (mr *MockStoragerMockRecorder) AddOrder(ctx, number interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockStorager)(nil).AddOrder), ctx, number)
 }
 
+// AddOrders mocks base method.
+func (m *MockStorager) AddOrders(ctx context.Context, numbers []string) ([]models.BatchOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrders", ctx, numbers)
+	ret0, _ := ret[0].([]models.BatchOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOrders indicates an expected call of AddOrders.
+func (mr *MockStoragerMockRecorder) AddOrders(ctx, numbers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrders", reflect.TypeOf((*MockStorager)(nil).AddOrders), ctx, numbers)
+}
+
+// AddPasswordResetToken mocks base method.
+func (m *MockStorager) AddPasswordResetToken(ctx context.Context, token models.PasswordResetToken, since time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPasswordResetToken", ctx, token, since)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPasswordResetToken indicates an expected call of AddPasswordResetToken.
+func (mr *MockStoragerMockRecorder) AddPasswordResetToken(ctx, token, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPasswordResetToken", reflect.TypeOf((*MockStorager)(nil).AddPasswordResetToken), ctx, token, since)
+}
+
+// AddSession mocks base method.
+func (m *MockStorager) AddSession(ctx context.Context, session models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSession indicates an expected call of AddSession.
+func (mr *MockStoragerMockRecorder) AddSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSession", reflect.TypeOf((*MockStorager)(nil).AddSession), ctx, session)
+}
+
 // AddUser mocks base method.
-func (m *MockStorager) AddUser(ctx context.Context, userLogin string, userPassword []byte) (models.User, error) {
+func (m *MockStorager) AddUser(ctx context.Context, userLogin, userEmail string, userPassword []byte) (models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddUser", ctx, userLogin, userPassword)
+	ret := m.ctrl.Call(m, "AddUser", ctx, userLogin, userEmail, userPassword)
 	ret0, _ := ret[0].(models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddUser indicates an expected call of AddUser.
-func (mr *MockStoragerMockRecorder) AddUser(ctx, userLogin, userPassword interface{}) *gomock.Call {
+func (mr *MockStoragerMockRecorder) AddUser(ctx, userLogin, userEmail, userPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockStorager)(nil).AddUser), ctx, userLogin, userEmail, userPassword)
+}
+
+// AddWebhook mocks base method.
+func (m *MockStorager) AddWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWebhook", ctx, w)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWebhook indicates an expected call of AddWebhook.
+func (mr *MockStoragerMockRecorder) AddWebhook(ctx, w interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockStorager)(nil).AddUser), ctx, userLogin, userPassword)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWebhook", reflect.TypeOf((*MockStorager)(nil).AddWebhook), ctx, w)
 }
 
 // AddWithdraw mocks base method.
-func (m *MockStorager) AddWithdraw(ctx context.Context, orderNumber string, sum float32) error {
+func (m *MockStorager) AddWithdraw(ctx context.Context, orderNumber string, sum models.Points, totpStep int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddWithdraw", ctx, orderNumber, sum)
+	ret := m.ctrl.Call(m, "AddWithdraw", ctx, orderNumber, sum, totpStep)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddWithdraw indicates an expected call of AddWithdraw.
-func (mr *MockStoragerMockRecorder) AddWithdraw(ctx, orderNumber, sum interface{}) *gomock.Call {
+func (mr *MockStoragerMockRecorder) AddWithdraw(ctx, orderNumber, sum, totpStep interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithdraw", reflect.TypeOf((*MockStorager)(nil).AddWithdraw), ctx, orderNumber, sum)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithdraw", reflect.TypeOf((*MockStorager)(nil).AddWithdraw), ctx, orderNumber, sum, totpStep)
+}
+
+// AdjustBalance mocks base method.
+func (m *MockStorager) AdjustBalance(ctx context.Context, userID int, amount models.Points, reason string) (models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, userID, amount, reason)
+	ret0, _ := ret[0].(models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockStoragerMockRecorder) AdjustBalance(ctx, userID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockStorager)(nil).AdjustBalance), ctx, userID, amount, reason)
+}
+
+// AnonymizeUser mocks base method.
+func (m *MockStorager) AnonymizeUser(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUser", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnonymizeUser indicates an expected call of AnonymizeUser.
+func (mr *MockStoragerMockRecorder) AnonymizeUser(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUser", reflect.TypeOf((*MockStorager)(nil).AnonymizeUser), ctx)
 }
 
 // Close mocks base method.
@@ -94,6 +452,78 @@ func (mr *MockStoragerMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStorager)(nil).Close))
 }
 
+// CompleteLoginChallenge mocks base method.
+func (m *MockStorager) CompleteLoginChallenge(ctx context.Context, tokenHash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLoginChallenge", ctx, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteLoginChallenge indicates an expected call of CompleteLoginChallenge.
+func (mr *MockStoragerMockRecorder) CompleteLoginChallenge(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLoginChallenge", reflect.TypeOf((*MockStorager)(nil).CompleteLoginChallenge), ctx, tokenHash)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockStorager) ConfirmTOTP(ctx context.Context, userID int, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockStoragerMockRecorder) ConfirmTOTP(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockStorager)(nil).ConfirmTOTP), ctx, userID, step)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockStorager) DeleteWebhook(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockStoragerMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStorager)(nil).DeleteWebhook), ctx, id)
+}
+
+// FindUsersByLogin mocks base method.
+func (m *MockStorager) FindUsersByLogin(ctx context.Context, login string, limit int) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsersByLogin", ctx, login, limit)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUsersByLogin indicates an expected call of FindUsersByLogin.
+func (mr *MockStoragerMockRecorder) FindUsersByLogin(ctx, login, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsersByLogin", reflect.TypeOf((*MockStorager)(nil).FindUsersByLogin), ctx, login, limit)
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockStorager) GetAuditEvents(ctx context.Context, q models.AuditQuery) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, q)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockStoragerMockRecorder) GetAuditEvents(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockStorager)(nil).GetAuditEvents), ctx, q)
+}
+
 // GetBalance mocks base method.
 func (m *MockStorager) GetBalance(ctx context.Context) (models.Balance, error) {
 	m.ctrl.T.Helper()
@@ -109,19 +539,169 @@ func (mr *MockStoragerMockRecorder) GetBalance(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockStorager)(nil).GetBalance), ctx)
 }
 
+// GetCurrentUser mocks base method.
+func (m *MockStorager) GetCurrentUser(ctx context.Context) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentUser", ctx)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentUser indicates an expected call of GetCurrentUser.
+func (mr *MockStoragerMockRecorder) GetCurrentUser(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentUser", reflect.TypeOf((*MockStorager)(nil).GetCurrentUser), ctx)
+}
+
+// GetLedgerEntries mocks base method.
+func (m *MockStorager) GetLedgerEntries(ctx context.Context, afterID int64, limit int) ([]models.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerEntries", ctx, afterID, limit)
+	ret0, _ := ret[0].([]models.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerEntries indicates an expected call of GetLedgerEntries.
+func (mr *MockStoragerMockRecorder) GetLedgerEntries(ctx, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerEntries", reflect.TypeOf((*MockStorager)(nil).GetLedgerEntries), ctx, afterID, limit)
+}
+
+// GetLoginLockedUntil mocks base method.
+func (m *MockStorager) GetLoginLockedUntil(ctx context.Context, login, ip string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginLockedUntil", ctx, login, ip)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginLockedUntil indicates an expected call of GetLoginLockedUntil.
+func (mr *MockStoragerMockRecorder) GetLoginLockedUntil(ctx, login, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginLockedUntil", reflect.TypeOf((*MockStorager)(nil).GetLoginLockedUntil), ctx, login, ip)
+}
+
+// GetOrderByNumber mocks base method.
+func (m *MockStorager) GetOrderByNumber(ctx context.Context, number string) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByNumber", ctx, number)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByNumber indicates an expected call of GetOrderByNumber.
+func (mr *MockStoragerMockRecorder) GetOrderByNumber(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByNumber", reflect.TypeOf((*MockStorager)(nil).GetOrderByNumber), ctx, number)
+}
+
+// GetOrderStatusEvents mocks base method.
+func (m *MockStorager) GetOrderStatusEvents(ctx context.Context, number string) ([]models.OrderStatusEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStatusEvents", ctx, number)
+	ret0, _ := ret[0].([]models.OrderStatusEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStatusEvents indicates an expected call of GetOrderStatusEvents.
+func (mr *MockStoragerMockRecorder) GetOrderStatusEvents(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatusEvents", reflect.TypeOf((*MockStorager)(nil).GetOrderStatusEvents), ctx, number)
+}
+
 // GetOrdersByUserID mocks base method.
-func (m *MockStorager) GetOrdersByUserID(ctx context.Context) ([]models.Order, error) {
+func (m *MockStorager) GetOrdersByUserID(ctx context.Context, q models.OrderQuery) ([]models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrdersByUserID", ctx)
+	ret := m.ctrl.Call(m, "GetOrdersByUserID", ctx, q)
 	ret0, _ := ret[0].([]models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrdersByUserID indicates an expected call of GetOrdersByUserID.
-func (mr *MockStoragerMockRecorder) GetOrdersByUserID(ctx interface{}) *gomock.Call {
+func (mr *MockStoragerMockRecorder) GetOrdersByUserID(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByUserID", reflect.TypeOf((*MockStorager)(nil).GetOrdersByUserID), ctx, q)
+}
+
+// GetPasswordResetUser mocks base method.
+func (m *MockStorager) GetPasswordResetUser(ctx context.Context, tokenHash []byte) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetUser", ctx, tokenHash)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetUser indicates an expected call of GetPasswordResetUser.
+func (mr *MockStoragerMockRecorder) GetPasswordResetUser(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetUser", reflect.TypeOf((*MockStorager)(nil).GetPasswordResetUser), ctx, tokenHash)
+}
+
+// GetSession mocks base method.
+func (m *MockStorager) GetSession(ctx context.Context, sessionID string) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", ctx, sessionID)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoragerMockRecorder) GetSession(ctx, sessionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByUserID", reflect.TypeOf((*MockStorager)(nil).GetOrdersByUserID), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStorager)(nil).GetSession), ctx, sessionID)
+}
+
+// GetTOTP mocks base method.
+func (m *MockStorager) GetTOTP(ctx context.Context, userID int) (models.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(models.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockStoragerMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockStorager)(nil).GetTOTP), ctx, userID)
+}
+
+// GetThrottleLockedUntil mocks base method.
+func (m *MockStorager) GetThrottleLockedUntil(ctx context.Context, scope, subject string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThrottleLockedUntil", ctx, scope, subject)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThrottleLockedUntil indicates an expected call of GetThrottleLockedUntil.
+func (mr *MockStoragerMockRecorder) GetThrottleLockedUntil(ctx, scope, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThrottleLockedUntil", reflect.TypeOf((*MockStorager)(nil).GetThrottleLockedUntil), ctx, scope, subject)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStorager) GetUserByID(ctx context.Context, userID int) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoragerMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStorager)(nil).GetUserByID), ctx, userID)
 }
 
 // GetUserByLogin mocks base method.
@@ -139,6 +719,51 @@ func (mr *MockStoragerMockRecorder) GetUserByLogin(ctx, userLogin interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockStorager)(nil).GetUserByLogin), ctx, userLogin)
 }
 
+// GetWebhook mocks base method.
+func (m *MockStorager) GetWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockStoragerMockRecorder) GetWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockStorager)(nil).GetWebhook), ctx, id)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockStorager) GetWebhookDeliveries(ctx context.Context, webhookID, afterID int64, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, webhookID, afterID, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockStoragerMockRecorder) GetWebhookDeliveries(ctx, webhookID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockStorager)(nil).GetWebhookDeliveries), ctx, webhookID, afterID, limit)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockStorager) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockStoragerMockRecorder) GetWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockStorager)(nil).GetWebhooks), ctx)
+}
+
 // GetWithdrawals mocks base method.
 func (m *MockStorager) GetWithdrawals(ctx context.Context) ([]models.Withdraw, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +779,50 @@ func (mr *MockStoragerMockRecorder) GetWithdrawals(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockStorager)(nil).GetWithdrawals), ctx)
 }
 
+// HasAdmin mocks base method.
+func (m *MockStorager) HasAdmin(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAdmin", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasAdmin indicates an expected call of HasAdmin.
+func (mr *MockStoragerMockRecorder) HasAdmin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAdmin", reflect.TypeOf((*MockStorager)(nil).HasAdmin), ctx)
+}
+
+// LockLogin mocks base method.
+func (m *MockStorager) LockLogin(ctx context.Context, scope, subject string, failures int, lockedUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLogin", ctx, scope, subject, failures, lockedUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockStoragerMockRecorder) LockLogin(ctx, scope, subject, failures, lockedUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockStorager)(nil).LockLogin), ctx, scope, subject, failures, lockedUntil)
+}
+
+// OverrideOrder mocks base method.
+func (m *MockStorager) OverrideOrder(ctx context.Context, override models.OrderOverride) (models.OrderOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OverrideOrder", ctx, override)
+	ret0, _ := ret[0].(models.OrderOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OverrideOrder indicates an expected call of OverrideOrder.
+func (mr *MockStoragerMockRecorder) OverrideOrder(ctx, override interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OverrideOrder", reflect.TypeOf((*MockStorager)(nil).OverrideOrder), ctx, override)
+}
+
 // Ping mocks base method.
 func (m *MockStorager) Ping(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -167,3 +836,175 @@ func (mr *MockStoragerMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorager)(nil).Ping), ctx)
 }
+
+// PromoteFirstAdmin mocks base method.
+func (m *MockStorager) PromoteFirstAdmin(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteFirstAdmin", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PromoteFirstAdmin indicates an expected call of PromoteFirstAdmin.
+func (mr *MockStoragerMockRecorder) PromoteFirstAdmin(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteFirstAdmin", reflect.TypeOf((*MockStorager)(nil).PromoteFirstAdmin), ctx, userID)
+}
+
+// RehashPassword mocks base method.
+func (m *MockStorager) RehashPassword(ctx context.Context, userID int, oldHash, newHash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashPassword", ctx, userID, oldHash, newHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RehashPassword indicates an expected call of RehashPassword.
+func (mr *MockStoragerMockRecorder) RehashPassword(ctx, userID, oldHash, newHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashPassword", reflect.TypeOf((*MockStorager)(nil).RehashPassword), ctx, userID, oldHash, newHash)
+}
+
+// ReplayWebhookDeliveries mocks base method.
+func (m *MockStorager) ReplayWebhookDeliveries(ctx context.Context, webhookID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayWebhookDeliveries", ctx, webhookID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayWebhookDeliveries indicates an expected call of ReplayWebhookDeliveries.
+func (mr *MockStoragerMockRecorder) ReplayWebhookDeliveries(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayWebhookDeliveries", reflect.TypeOf((*MockStorager)(nil).ReplayWebhookDeliveries), ctx, webhookID)
+}
+
+// ResetLoginFailures mocks base method.
+func (m *MockStorager) ResetLoginFailures(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures.
+func (mr *MockStoragerMockRecorder) ResetLoginFailures(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockStorager)(nil).ResetLoginFailures), ctx, login)
+}
+
+// ResetPassword mocks base method.
+func (m *MockStorager) ResetPassword(ctx context.Context, tokenHash, password []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, tokenHash, password)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockStoragerMockRecorder) ResetPassword(ctx, tokenHash, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockStorager)(nil).ResetPassword), ctx, tokenHash, password)
+}
+
+// RevokeSession mocks base method.
+func (m *MockStorager) RevokeSession(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockStoragerMockRecorder) RevokeSession(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockStorager)(nil).RevokeSession), ctx)
+}
+
+// RotateSession mocks base method.
+func (m *MockStorager) RotateSession(ctx context.Context, oldHash, newHash []byte, expiresAt time.Time) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", ctx, oldHash, newHash, expiresAt)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockStoragerMockRecorder) RotateSession(ctx, oldHash, newHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockStorager)(nil).RotateSession), ctx, oldHash, newHash, expiresAt)
+}
+
+// SaveTOTPSetup mocks base method.
+func (m *MockStorager) SaveTOTPSetup(ctx context.Context, userID int, secret string, recoveryHashes [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPSetup", ctx, userID, secret, recoveryHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTPSetup indicates an expected call of SaveTOTPSetup.
+func (mr *MockStoragerMockRecorder) SaveTOTPSetup(ctx, userID, secret, recoveryHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPSetup", reflect.TypeOf((*MockStorager)(nil).SaveTOTPSetup), ctx, userID, secret, recoveryHashes)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockStorager) UpdatePassword(ctx context.Context, password []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockStoragerMockRecorder) UpdatePassword(ctx, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockStorager)(nil).UpdatePassword), ctx, password)
+}
+
+// UseLoginChallengeAttempt mocks base method.
+func (m *MockStorager) UseLoginChallengeAttempt(ctx context.Context, tokenHash []byte, maxAttempts int) (models.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLoginChallengeAttempt", ctx, tokenHash, maxAttempts)
+	ret0, _ := ret[0].(models.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLoginChallengeAttempt indicates an expected call of UseLoginChallengeAttempt.
+func (mr *MockStoragerMockRecorder) UseLoginChallengeAttempt(ctx, tokenHash, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginChallengeAttempt", reflect.TypeOf((*MockStorager)(nil).UseLoginChallengeAttempt), ctx, tokenHash, maxAttempts)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStorager) UseRecoveryCode(ctx context.Context, userID int, codeHash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoragerMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStorager)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockStorager) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockStoragerMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockStorager)(nil).UseTOTPStep), ctx, userID, step)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+)
+
+const (
+	// maxOrderNumberLength совпадает с размером колонки orders.number.
+	maxOrderNumberLength = 200
+	iso7812MinLength     = 8
+	iso7812MaxLength     = 19
+	baseNumber           = 10
+	maxNumber            = 9
+)
+
+// OrderNumberValidator проверяет формат номера заказа. Номер проверяется как строка, поэтому его длина
+// не ограничена размером целого числа, а ведущие нули значимы.
+type OrderNumberValidator interface {
+	Validate(number string) error
+}
+
+// LuhnValidator принимает номера из цифр с верной контрольной суммой по алгоритму Луна.
+type LuhnValidator struct{}
+
+func (LuhnValidator) Validate(number string) error {
+	if !luhnValid(number) {
+		return fmt.Errorf("bad check sum for order number: %w", ErrOrderNumberValidation)
+	}
+
+	return nil
+}
+
+// ISO7812Validator принимает номера карт по ISO/IEC 7812: от 8 до 19 цифр с контрольной суммой по алгоритму Луна.
+type ISO7812Validator struct{}
+
+func (ISO7812Validator) Validate(number string) error {
+	if len(number) < iso7812MinLength || len(number) > iso7812MaxLength {
+		return fmt.Errorf("order number must have from %d to %d digits: %w",
+			iso7812MinLength, iso7812MaxLength, ErrOrderNumberValidation)
+	}
+
+	return LuhnValidator{}.Validate(number)
+}
+
+// PatternValidator принимает номера, целиком совпадающие с регулярным выражением, например форматы партнеров.
+type PatternValidator struct {
+	Pattern *regexp.Regexp
+}
+
+// NewPatternValidator компилирует шаблон так, чтобы он совпадал со всем номером, а не с его частью.
+func NewPatternValidator(pattern string) (*PatternValidator, error) {
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile order number pattern: %w", err)
+	}
+
+	return &PatternValidator{Pattern: re}, nil
+}
+
+func (v *PatternValidator) Validate(number string) error {
+	if !v.Pattern.MatchString(number) {
+		return fmt.Errorf("order number does not match pattern: %w", ErrOrderNumberValidation)
+	}
+
+	return nil
+}
+
+// newOrderNumberValidator выбирает проверку по настройкам. Шаблон проверяется при загрузке конфигурации,
+// без нее используется проверка по алгоритму Луна.
+func newOrderNumberValidator(settings config.OrderNumberSettings) OrderNumberValidator {
+	switch settings.Validator {
+	case config.OrderNumberISO7812:
+		return ISO7812Validator{}
+	case config.OrderNumberPattern:
+		if v, err := NewPatternValidator(settings.Pattern); err == nil {
+			return v
+		}
+	}
+
+	return LuhnValidator{}
+}
+
+// checkOrderNumber проверяет длину номера и его формат выбранным валидатором.
+func (s *Services) checkOrderNumber(number string) error {
+	if number == "" || len(number) > maxOrderNumberLength {
+		return fmt.Errorf("order number must have from 1 to %d characters: %w",
+			maxOrderNumberLength, ErrOrderNumberValidation)
+	}
+
+	if err := s.orderNumbers.Validate(number); err != nil {
+		return fmt.Errorf("invalid order number: %w", err)
+	}
+
+	return nil
+}
+
+func luhnValid(number string) bool {
+	if number == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > maxNumber {
+				digit -= maxNumber
+			}
+		}
+
+		sum += digit
+		double = !double
+	}
+
+	return sum%baseNumber == 0
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOrderNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings config.OrderNumberSettings
+		number   string
+		valid    bool
+	}{
+		{
+			name:   "luhn",
+			number: "12345678903",
+			valid:  true,
+		},
+		{
+			name:   "luhn longer than int64",
+			number: "12345678901234567890123456789012345678901234567895",
+			valid:  true,
+		},
+		{
+			name:   "leading zeros are significant",
+			number: "0079927398713",
+			valid:  true,
+		},
+		{
+			name:   "bad check sum",
+			number: "12345678904",
+		},
+		{
+			name:   "not digits",
+			number: "1234-5678-903",
+		},
+		{
+			name:   "signed number",
+			number: "-12345678903",
+		},
+		{
+			name: "empty",
+		},
+		{
+			name:   "longer than column",
+			number: strings.Repeat("0", maxOrderNumberLength+1),
+		},
+		{
+			name:     "iso 7812 card number",
+			settings: config.OrderNumberSettings{Validator: config.OrderNumberISO7812},
+			number:   "4111111111111111",
+			valid:    true,
+		},
+		{
+			name:     "iso 7812 too short",
+			settings: config.OrderNumberSettings{Validator: config.OrderNumberISO7812},
+			number:   "18",
+		},
+		{
+			name:     "iso 7812 too long",
+			settings: config.OrderNumberSettings{Validator: config.OrderNumberISO7812},
+			number:   "12345678901234567894",
+		},
+		{
+			name:     "merchant pattern",
+			settings: config.OrderNumberSettings{Validator: config.OrderNumberPattern, Pattern: `POS-[0-9]{6}`},
+			number:   "POS-000123",
+			valid:    true,
+		},
+		{
+			name:     "pattern must match whole number",
+			settings: config.OrderNumberSettings{Validator: config.OrderNumberPattern, Pattern: `POS-[0-9]{6}`},
+			number:   "POS-0001234",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Services{orderNumbers: newOrderNumberValidator(test.settings)}
+
+			err := s.checkOrderNumber(test.number)
+
+			if test.valid {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrOrderNumberValidation)
+		})
+	}
+}
+
+func TestNewPatternValidator(t *testing.T) {
+	_, err := NewPatternValidator(`[0-9`)
+	require.Error(t, err)
+
+	v, err := NewPatternValidator(`A|B`)
+	require.NoError(t, err)
+	assert.NoError(t, v.Validate("B"))
+	assert.ErrorIs(t, v.Validate("AB"), ErrOrderNumberValidation)
+}
@@ -2,20 +2,31 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 )
 
 var (
 	ErrAnotherUserOrderExist = errors.New("order has another user")
 	ErrUserOrderExist        = errors.New("user order already exist")
+	ErrOrderFilterValidation = errors.New("order filters have not been validated")
+	ErrOrderBatchValidation  = errors.New("order batch has not been validated")
+	ErrOrderUpdatesDisabled  = errors.New("order updates are not available")
 )
 
+const maxOrderBatchSize = 1000
+
+var orderStatuses = map[string]bool{"NEW": true, "PROCESSING": true, "INVALID": true, "PROCESSED": true}
+
 func (s *Services) AddOrder(ctx context.Context, number string) error {
-	if err := checkOrderNumber(number); err != nil {
+	if err := s.checkOrderNumber(number); err != nil {
 		return fmt.Errorf("failed check order number: %w", err)
 	}
 
@@ -35,11 +46,240 @@ func (s *Services) AddOrder(ctx context.Context, number string) error {
 	return ErrUserOrderExist
 }
 
-func (s *Services) GetOrders(ctx context.Context) ([]models.Order, error) {
-	orders, err := s.store.GetOrdersByUserID(ctx)
+// AddOrders загружает пачку номеров заказов и возвращает результат для каждого переданного номера в том же порядке.
+// Номера с неверной контрольной суммой пропускаются, повтор номера внутри пачки считается дубликатом.
+func (s *Services) AddOrders(ctx context.Context, numbers []string) ([]models.OrderUploadResult, error) {
+	if len(numbers) == 0 || len(numbers) > maxOrderBatchSize {
+		return nil, fmt.Errorf("batch must contain from 1 to %d numbers: %w", maxOrderBatchSize, ErrOrderBatchValidation)
+	}
+
+	results := make([]models.OrderUploadResult, len(numbers))
+	seen := make(map[string]bool, len(numbers))
+	valid := make([]string, 0, len(numbers))
+
+	for i, number := range numbers {
+		results[i].Number = number
+
+		switch {
+		case s.checkOrderNumber(number) != nil:
+			results[i].Status = models.OrderUploadInvalid
+		case seen[number]:
+			results[i].Status = models.OrderUploadDuplicate
+		default:
+			seen[number] = true
+			valid = append(valid, number)
+		}
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	orders, err := s.store.AddOrders(ctx, valid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add orders: %w", err)
+	}
+
+	statuses := make(map[string]string, len(orders))
+	userID, _ := ctx.Value(common.KeyUserID).(int)
+
+	for _, o := range orders {
+		switch {
+		case o.Created:
+			statuses[o.Number] = models.OrderUploadAccepted
+		case o.UserID == userID:
+			statuses[o.Number] = models.OrderUploadDuplicate
+		default:
+			statuses[o.Number] = models.OrderUploadConflict
+		}
+	}
+
+	for i := range results {
+		if results[i].Status == "" {
+			results[i].Status = statuses[results[i].Number]
+		}
+	}
+
+	return results, nil
+}
+
+// GetOrders возвращает страницу заказов текущего пользователя. Если за страницей есть еще заказы,
+// в ответе возвращается курсор следующей страницы. Если не переданы ни limit, ни after, возвращаются все заказы,
+// как до появления пагинации. Нулевой limit, как и в истории баланса, означает страницу по умолчанию.
+func (s *Services) GetOrders(ctx context.Context, req models.OrdersRequest) (models.OrdersPage, error) {
+	q, err := orderQuery(req)
+	if err != nil {
+		return models.OrdersPage{}, err
+	}
+
+	limit := q.Limit
+	if limit > 0 {
+		q.Limit++
+	}
+
+	orders, err := s.store.GetOrdersByUserID(ctx, q)
+	if err != nil {
+		return models.OrdersPage{}, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	page := models.OrdersPage{Orders: orders}
+
+	if limit > 0 && len(orders) > limit {
+		page.Orders = orders[:limit]
+		page.NextCursor = encodeOrderCursor(page.Orders[limit-1])
+	}
+
+	return page, nil
+}
+
+// GetOrder возвращает заказ текущего пользователя с историей статусов.
+func (s *Services) GetOrder(ctx context.Context, number string) (models.OrderDetails, error) {
+	order, err := s.store.GetOrderByNumber(ctx, number)
+	if err != nil {
+		if errors.Is(err, data.ErrOrderNotFound) {
+			return models.OrderDetails{}, ErrOrderNotFound
+		}
+		return models.OrderDetails{}, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	history, err := s.store.GetOrderStatusEvents(ctx, number)
+	if err != nil {
+		return models.OrderDetails{}, fmt.Errorf("failed to get order history: %w", err)
+	}
+
+	return models.OrderDetails{Order: order, History: history}, nil
+}
+
+func orderQuery(req models.OrdersRequest) (models.OrderQuery, error) {
+	var (
+		limit int
+		err   error
+	)
+
+	if req.Limit != nil || req.After != "" {
+		var requested int
+		if req.Limit != nil {
+			requested = *req.Limit
+		}
+
+		limit, err = pageLimit(requested)
+		if err != nil {
+			return models.OrderQuery{}, err
+		}
+	}
+
+	q := models.OrderQuery{From: req.From, To: req.To, Status: req.Status, Limit: limit}
+
+	if req.Status != "" && !orderStatuses[req.Status] {
+		return q, fmt.Errorf("unknown status %q: %w", req.Status, ErrOrderFilterValidation)
+	}
+
+	switch req.Sort {
+	case "", models.SortAsc:
+	case models.SortDesc:
+		q.Desc = true
+	default:
+		return q, fmt.Errorf("unknown sort %q: %w", req.Sort, ErrOrderFilterValidation)
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return q, fmt.Errorf("from must be before to: %w", ErrOrderFilterValidation)
+	}
+
+	if req.After != "" {
+		q.AfterUploadedAt, q.AfterNumber, err = decodeOrderCursor(req.After)
+		if err != nil {
+			return q, err
+		}
+	}
+
+	return q, nil
+}
+
+// encodeOrderCursor кодирует позицию заказа в списке: время загрузки и номер.
+func encodeOrderCursor(o models.Order) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(o.UploadedAt.Format(time.RFC3339Nano) + "|" + o.Number))
+}
+
+func decodeOrderCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("failed to decode cursor: %w", ErrPaginationValidation)
+	}
+
+	ts, number, ok := strings.Cut(string(raw), "|")
+	if !ok || number == "" {
+		return time.Time{}, "", fmt.Errorf("malformed cursor: %w", ErrPaginationValidation)
+	}
+
+	uploadedAt, err := time.Parse(time.RFC3339Nano, ts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get orders: %w", err)
+		return time.Time{}, "", fmt.Errorf("failed to parse cursor time: %w", ErrPaginationValidation)
 	}
 
-	return orders, nil
+	return uploadedAt, number, nil
+}
+
+// SubscribeOrderUpdates подписывает текущего пользователя на смены статусов его заказов до отмены ctx.
+// Подписка закрывается, когда истекает токен доступа или сессия перестает проходить проверку авторизации:
+// отозвана, истекла или у пользователя сменилась роль.
+func (s *Services) SubscribeOrderUpdates(ctx context.Context) (<-chan models.OrderUpdate, error) {
+	if s.updates == nil {
+		return nil, ErrOrderUpdatesDisabled
+	}
+
+	userID, _ := ctx.Value(common.KeyUserID).(int)
+
+	var cancel context.CancelFunc
+	if expiresAt, ok := ctx.Value(common.KeyTokenExpiresAt).(time.Time); ok {
+		ctx, cancel = context.WithDeadline(ctx, expiresAt)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	updates := s.updates.Subscribe(ctx, userID)
+	go s.watchSubscription(ctx, cancel)
+
+	return updates, nil
+}
+
+// watchSubscription периодически проверяет сессию подписки и отменяет ее, когда проверка не проходит.
+func (s *Services) watchSubscription(ctx context.Context, cancel context.CancelFunc) {
+	defer cancel()
+
+	var tick <-chan time.Time
+	if period := s.settings.OrderEvents.SessionCheck; period > 0 {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			if !s.sessionActive(ctx) {
+				return
+			}
+		}
+	}
+}
+
+// sessionActive повторяет проверки authMiddleware для текущей сессии. Ошибка хранилища тоже закрывает поток:
+// клиент переподключится и пройдет полную проверку.
+func (s *Services) sessionActive(ctx context.Context) bool {
+	userID, _ := ctx.Value(common.KeyUserID).(int)
+	sessionID, _ := ctx.Value(common.KeySessionID).(string)
+	role, _ := ctx.Value(common.KeyUserRole).(string)
+
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil || session.Revoked || session.UserID != userID || time.Now().After(session.ExpiresAt) {
+		return false
+	}
+
+	user, err := s.store.GetUserByID(ctx, userID)
+
+	return err == nil && user.Role == role
 }
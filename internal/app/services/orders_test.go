@@ -3,15 +3,18 @@ package services
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/common"
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAddOrder(t *testing.T) {
@@ -20,7 +23,7 @@ func TestAddOrder(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	currentUserID := 1
 	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
@@ -144,7 +147,7 @@ func TestValidationFailedAddOrder(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	currentUserID := 1
 	ctx := context.WithValue(context.Background(), common.KeyUserID, currentUserID)
@@ -167,46 +170,311 @@ func TestSuccessGetOrders(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
-	orders := []models.Order{
-		{
-			Number:     "12345678",
+	orders := make([]models.Order, defaultPageLimit+10)
+	for i := range orders {
+		orders[i] = models.Order{
+			Number:     strconv.Itoa(12345678 + i),
 			Status:     "NEW",
 			Accrual:    0,
 			UploadedAt: time.Now(),
 			UserID:     1,
-		},
+		}
 	}
 
-	_ = store.EXPECT().GetOrdersByUserID(ctx).Times(1).Return(orders, nil)
+	// Без limit и after список не обрезается.
+	_ = store.EXPECT().GetOrdersByUserID(ctx, models.OrderQuery{}).Times(1).Return(orders, nil)
 
 	t.Run("get orders success", func(t *testing.T) {
-		result, err := s.GetOrders(ctx)
-		assert.Equal(t, orders, result)
+		result, err := s.GetOrders(ctx, models.OrdersRequest{})
+		assert.Equal(t, models.OrdersPage{Orders: orders}, result)
 		assert.NoError(t, err)
 	})
 }
 
+func TestGetOrdersPagination(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	one, zero, tooLarge := 1, 0, maxPageLimit+1
+	first := models.Order{Number: "12345678903", Status: "PROCESSED", UploadedAt: from.Add(time.Microsecond)}
+	second := models.Order{Number: "9278923470", Status: "PROCESSED", UploadedAt: from.Add(time.Hour)}
+
+	_ = store.EXPECT().
+		GetOrdersByUserID(ctx, models.OrderQuery{From: from, Status: "PROCESSED", Limit: 2, Desc: true}).
+		Times(1).
+		Return([]models.Order{first, second}, nil)
+
+	page, err := s.GetOrders(ctx,
+		models.OrdersRequest{From: from, Status: "PROCESSED", Sort: models.SortDesc, Limit: &one})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Order{first}, page.Orders)
+	require.NotEmpty(t, page.NextCursor)
+
+	_ = store.EXPECT().
+		GetOrdersByUserID(ctx, models.OrderQuery{
+			AfterUploadedAt: first.UploadedAt, AfterNumber: first.Number, Limit: 2,
+		}).
+		Times(1).
+		Return([]models.Order{second}, nil)
+
+	page, err = s.GetOrders(ctx, models.OrdersRequest{After: page.NextCursor, Limit: &one})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Order{second}, page.Orders)
+	assert.Empty(t, page.NextCursor)
+
+	// Переданный нулевой limit, как и в истории баланса, означает страницу по умолчанию.
+	_ = store.EXPECT().GetOrdersByUserID(ctx, models.OrderQuery{Limit: defaultPageLimit + 1}).
+		Times(1).
+		Return([]models.Order{first, second}, nil)
+
+	page, err = s.GetOrders(ctx, models.OrdersRequest{Limit: &zero})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Order{first, second}, page.Orders)
+
+	tests := []struct {
+		wantErr error
+		name    string
+		req     models.OrdersRequest
+	}{
+		{
+			name:    "unknown status",
+			req:     models.OrdersRequest{Status: "DONE"},
+			wantErr: ErrOrderFilterValidation,
+		},
+		{
+			name:    "unknown sort",
+			req:     models.OrdersRequest{Sort: "random"},
+			wantErr: ErrOrderFilterValidation,
+		},
+		{
+			name:    "from after to",
+			req:     models.OrdersRequest{From: from, To: from.Add(-time.Hour)},
+			wantErr: ErrOrderFilterValidation,
+		},
+		{
+			name:    "malformed cursor",
+			req:     models.OrdersRequest{After: "not a cursor"},
+			wantErr: ErrPaginationValidation,
+		},
+		{
+			name:    "limit out of range",
+			req:     models.OrdersRequest{Limit: &tooLarge},
+			wantErr: ErrPaginationValidation,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := s.GetOrders(ctx, test.req)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
 func TestFailedGetOrders(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	orders := []models.Order{}
 	errSome := errors.New("some error")
 
-	_ = store.EXPECT().GetOrdersByUserID(ctx).Times(1).Return(orders, errSome)
+	_ = store.EXPECT().GetOrdersByUserID(ctx, gomock.Any()).Times(1).Return(orders, errSome)
 
 	t.Run("get orders failed", func(t *testing.T) {
-		_, err := s.GetOrders(ctx)
+		_, err := s.GetOrders(ctx, models.OrdersRequest{})
 		if assert.Error(t, err) {
 			assert.ErrorContains(t, err, "failed to get orders", "some error")
 		}
 	})
 }
+
+func TestGetOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	order := models.Order{Number: "12345678903", Status: "PROCESSED", Accrual: 500, UserID: 1}
+	history := []models.OrderStatusEvent{{Status: "NEW"}, {Status: "PROCESSED", Accrual: 500}}
+
+	t.Run("order with history", func(t *testing.T) {
+		_ = store.EXPECT().GetOrderByNumber(ctx, "12345678903").Times(1).Return(order, nil)
+		_ = store.EXPECT().GetOrderStatusEvents(ctx, "12345678903").Times(1).Return(history, nil)
+
+		res, err := s.GetOrder(ctx, "12345678903")
+		require.NoError(t, err)
+		assert.Equal(t, models.OrderDetails{Order: order, History: history}, res)
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		_ = store.EXPECT().GetOrderByNumber(ctx, "79927398713").Times(1).Return(models.Order{}, data.ErrOrderNotFound)
+
+		_, err := s.GetOrder(ctx, "79927398713")
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+	})
+}
+
+func TestAddOrders(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+
+	t.Run("mixed batch", func(t *testing.T) {
+		_ = store.EXPECT().
+			AddOrders(ctx, []string{"12345678903", "9278923470", "79927398713"}).
+			Times(1).
+			Return([]models.BatchOrder{
+				{Order: models.Order{Number: "12345678903", UserID: 1}, Created: true},
+				{Order: models.Order{Number: "9278923470", UserID: 1}},
+				{Order: models.Order{Number: "79927398713", UserID: 2}},
+			}, nil)
+
+		res, err := s.AddOrders(ctx, []string{"12345678903", "9278923470", "79927398713", "12345", "12345678903"})
+		require.NoError(t, err)
+		assert.Equal(t, []models.OrderUploadResult{
+			{Number: "12345678903", Status: models.OrderUploadAccepted},
+			{Number: "9278923470", Status: models.OrderUploadDuplicate},
+			{Number: "79927398713", Status: models.OrderUploadConflict},
+			{Number: "12345", Status: models.OrderUploadInvalid},
+			{Number: "12345678903", Status: models.OrderUploadDuplicate},
+		}, res)
+	})
+
+	t.Run("only invalid numbers", func(t *testing.T) {
+		res, err := s.AddOrders(ctx, []string{"abc"})
+		require.NoError(t, err)
+		assert.Equal(t, []models.OrderUploadResult{{Number: "abc", Status: models.OrderUploadInvalid}}, res)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		_, err := s.AddOrders(ctx, []string{})
+		assert.ErrorIs(t, err, ErrOrderBatchValidation)
+	})
+
+	t.Run("too big batch", func(t *testing.T) {
+		_, err := s.AddOrders(ctx, make([]string, maxOrderBatchSize+1))
+		assert.ErrorIs(t, err, ErrOrderBatchValidation)
+	})
+
+	t.Run("store failed", func(t *testing.T) {
+		_ = store.EXPECT().AddOrders(ctx, []string{"12345678903"}).Times(1).Return(nil, errors.New("some error"))
+
+		_, err := s.AddOrders(ctx, []string{"12345678903"})
+		assert.ErrorContains(t, err, "failed to add orders")
+	})
+}
+
+func TestSubscribeOrderUpdates(t *testing.T) {
+	settings := config.Settings{OrderEvents: config.OrderEventsSettings{SessionCheck: 10 * time.Millisecond}}
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	ctx = context.WithValue(ctx, common.KeySessionID, "s1")
+	ctx = context.WithValue(ctx, common.KeyUserRole, models.RoleUser)
+
+	session := models.Session{ID: "s1", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	user := models.User{ID: 1, Role: models.RoleUser}
+
+	// subscribe подписывается с хранилищем, которое отдает session и user, и возвращает контекст подписки.
+	subscribe := func(t *testing.T, ctx context.Context,
+		session models.Session, sessionErr error, user models.User) context.Context {
+		t.Helper()
+
+		mockCtrl := gomock.NewController(t)
+		store := mocks.NewMockStorager(mockCtrl)
+		updates := mocks.NewMockOrderUpdatesSubscriber(mockCtrl)
+		s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl),
+			updates, &settings)
+
+		_ = store.EXPECT().GetSession(gomock.Any(), "s1").AnyTimes().Return(session, sessionErr)
+		_ = store.EXPECT().GetUserByID(gomock.Any(), 1).AnyTimes().Return(user, nil)
+
+		ch := make(chan models.OrderUpdate)
+		var subCtx context.Context
+		_ = updates.EXPECT().Subscribe(gomock.Any(), 1).Times(1).
+			DoAndReturn(func(ctx context.Context, _ int) <-chan models.OrderUpdate {
+				subCtx = ctx
+				return ch
+			})
+
+		res, err := s.SubscribeOrderUpdates(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, (<-chan models.OrderUpdate)(ch), res)
+
+		return subCtx
+	}
+
+	t.Run("active session keeps subscription", func(t *testing.T) {
+		reqCtx, cancel := context.WithCancel(ctx)
+		subCtx := subscribe(t, reqCtx, session, nil, user)
+
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, subCtx.Err())
+
+		cancel()
+		<-subCtx.Done()
+	})
+
+	t.Run("token expiry closes subscription", func(t *testing.T) {
+		expiring := context.WithValue(ctx, common.KeyTokenExpiresAt, time.Now().Add(20*time.Millisecond))
+		subCtx := subscribe(t, expiring, session, nil, user)
+
+		<-subCtx.Done()
+		assert.ErrorIs(t, subCtx.Err(), context.DeadlineExceeded)
+	})
+
+	revoked := session
+	revoked.Revoked = true
+	admin := user
+	admin.Role = models.RoleAdmin
+
+	tests := []struct {
+		sessionErr error
+		name       string
+		session    models.Session
+		user       models.User
+	}{
+		{name: "revoked session closes subscription", session: revoked, user: user},
+		{name: "deleted session closes subscription", sessionErr: data.ErrSessionNotFound, user: user},
+		{name: "role change closes subscription", session: session, user: admin},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subCtx := subscribe(t, ctx, test.session, test.sessionErr, test.user)
+
+			<-subCtx.Done()
+			assert.ErrorIs(t, subCtx.Err(), context.Canceled)
+		})
+	}
+
+	t.Run("updates are disabled", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		s := NewServices(mocks.NewMockStorager(mockCtrl), testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil,
+			nopAuditor(mockCtrl), nil, &settings)
+
+		_, err := s.SubscribeOrderUpdates(ctx)
+		assert.ErrorIs(t, err, ErrOrderUpdatesDisabled)
+	})
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+var ErrInvalidResetToken = errors.New("password reset token is invalid or expired")
+
+const (
+	resetTokenSize     = 32
+	resetTokenParam    = "token"
+	resetPasswordTitle = "Сброс пароля Gophermart"
+)
+
+// RequestPasswordReset отправляет токен сброса пароля на почту пользователя. Чтобы по ответу нельзя было
+// узнать, зарегистрирован ли логин, для неизвестного логина или пользователя без почты ошибка не возвращается,
+// а письмо ставится в очередь notify.Queue и не задерживает ответ.
+func (s *Services) RequestPasswordReset(ctx context.Context, req models.PasswordResetRequest) error {
+	user, err := s.store.GetUserByLogin(ctx, req.Login)
+	if err != nil {
+		if errors.Is(err, data.ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user from DB %w", err)
+	}
+
+	if user.Email == "" {
+		return nil
+	}
+
+	token, err := randomToken(resetTokenSize)
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	now := time.Now()
+	created, err := s.store.AddPasswordResetToken(ctx, models.PasswordResetToken{
+		TokenHash: hashRefreshToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.settings.PasswordReset.TokenTTL),
+	}, now.Add(-s.settings.PasswordReset.RequestInterval))
+	if err != nil {
+		return fmt.Errorf("failed to add password reset token: %w", err)
+	}
+
+	// Токен уже отправлялся недавно, повторное письмо не шлем, чтобы не засыпать почту.
+	if !created {
+		return nil
+	}
+
+	if err := s.notifier.Notify(ctx, s.passwordResetNotification(user, token)); err != nil {
+		return fmt.Errorf("failed to send password reset notification: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword меняет пароль по токену из письма и завершает все сессии пользователя.
+func (s *Services) ResetPassword(ctx context.Context, req models.ResetPasswordRequest) error {
+	if req.Token == "" {
+		return ErrInvalidResetToken
+	}
+
+	tokenHash := hashRefreshToken(req.Token)
+
+	user, err := s.store.GetPasswordResetUser(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, data.ErrPasswordResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to get user from DB %w", err)
+	}
+
+	if violations := validatePassword(s.settings.Credentials,
+		fieldNewPassword, user.Login, req.NewPassword); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	hashedPassword, err := s.passwords.hash(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash passwords %w", err)
+	}
+
+	if _, err := s.store.ResetPassword(ctx, tokenHash, hashedPassword); err != nil {
+		if errors.Is(err, data.ErrPasswordResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to reset password %w", err)
+	}
+
+	s.recordUserEvent(context.WithValue(ctx, common.KeyUserID, user.ID), models.AuditPasswordReset, user.ID, nil, nil)
+
+	return nil
+}
+
+func (s *Services) passwordResetNotification(user models.User, token string) models.Notification {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Для пользователя %s запрошен сброс пароля.\n\n", user.Login)
+
+	if base := s.settings.PasswordReset.URL; base != "" {
+		fmt.Fprintf(&b, "Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\n", resetURL(base, token))
+	} else {
+		fmt.Fprintf(&b, "Токен для сброса пароля:\n%s\n\n", token)
+	}
+
+	fmt.Fprintf(&b, "Срок действия — %s. Если вы не запрашивали сброс, просто проигнорируйте это письмо.",
+		s.settings.PasswordReset.TokenTTL)
+
+	return models.Notification{
+		To:      user.Email,
+		Subject: resetPasswordTitle,
+		Body:    b.String(),
+	}
+}
+
+func resetURL(base string, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?" + resetTokenParam + "=" + url.QueryEscape(token)
+	}
+
+	q := u.Query()
+	q.Set(resetTokenParam, token)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestPasswordReset(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	notifier := mocks.NewMockNotifier(mockCtrl)
+	settings := testSettings()
+	settings.PasswordReset.URL = "https://example.com/reset?lang=ru"
+	settings.PasswordReset.TokenTTL = time.Hour
+	settings.PasswordReset.RequestInterval = time.Minute
+	s := NewServices(store, testKeyset(t), notifier, nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	errSome := errors.New("some error")
+	user := models.User{ID: 1, Login: "test", Email: "test@example.com"}
+
+	type mResponse struct {
+		userErr     error
+		addErr      error
+		notifyErr   error
+		user        models.User
+		created     bool
+		addTimes    int
+		notifyTimes int
+	}
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		mResponse mResponse
+	}{
+		{
+			name:      "reset requested",
+			mResponse: mResponse{user: user, created: true, addTimes: 1, notifyTimes: 1},
+		},
+		{
+			name:      "unknown login",
+			mResponse: mResponse{userErr: data.ErrUserNotFound},
+		},
+		{
+			name:      "user without email",
+			mResponse: mResponse{user: models.User{ID: 1, Login: "test"}},
+		},
+		{
+			name:      "token requested recently",
+			mResponse: mResponse{user: user, addTimes: 1},
+		},
+		{
+			name:      "storage failed",
+			mResponse: mResponse{user: user, addErr: errSome, addTimes: 1},
+			wantErr:   errSome,
+		},
+		{
+			name:      "delivery failed",
+			mResponse: mResponse{user: user, created: true, addTimes: 1, notifyTimes: 1, notifyErr: errSome},
+			wantErr:   errSome,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tokenHash []byte
+
+			_ = store.EXPECT().GetUserByLogin(ctx, "test").Times(1).Return(test.mResponse.user, test.mResponse.userErr)
+			_ = store.EXPECT().
+				AddPasswordResetToken(ctx, gomock.Any(), gomock.Any()).
+				Times(test.mResponse.addTimes).
+				DoAndReturn(func(_ context.Context, token models.PasswordResetToken, since time.Time) (bool, error) {
+					tokenHash = token.TokenHash
+					assert.Equal(t, user.ID, token.UserID)
+					assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Second)
+					assert.WithinDuration(t, time.Now().Add(-time.Minute), since, time.Second)
+					return test.mResponse.created, test.mResponse.addErr
+				})
+			_ = notifier.EXPECT().
+				Notify(ctx, gomock.Any()).
+				Times(test.mResponse.notifyTimes).
+				DoAndReturn(func(_ context.Context, n models.Notification) error {
+					assert.Equal(t, user.Email, n.To)
+
+					_, query, ok := strings.Cut(n.Body, "https://example.com/reset?")
+					require.True(t, ok)
+					token, _, _ := strings.Cut(strings.TrimPrefix(query, "lang=ru&token="), "\n")
+					assert.Equal(t, tokenHash, hashRefreshToken(token))
+
+					return test.mResponse.notifyErr
+				})
+
+			err := s.RequestPasswordReset(ctx, models.PasswordResetRequest{Login: "test"})
+
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.Credentials.PasswordMinLength = 8
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	errSome := errors.New("some error")
+	user := models.User{ID: 1, Login: "test"}
+	tokenHash := hashRefreshToken("token")
+
+	type mResponse struct {
+		userErr    error
+		resetErr   error
+		userTimes  int
+		resetTimes int
+	}
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		req       models.ResetPasswordRequest
+		mResponse mResponse
+	}{
+		{
+			name:      "reset success",
+			req:       models.ResetPasswordRequest{Token: "token", NewPassword: "NewSecret1"},
+			mResponse: mResponse{userTimes: 1, resetTimes: 1},
+		},
+		{
+			name:    "empty token",
+			req:     models.ResetPasswordRequest{NewPassword: "NewSecret1"},
+			wantErr: ErrInvalidResetToken,
+		},
+		{
+			name:      "unknown token",
+			req:       models.ResetPasswordRequest{Token: "token", NewPassword: "NewSecret1"},
+			mResponse: mResponse{userTimes: 1, userErr: data.ErrPasswordResetTokenNotFound},
+			wantErr:   ErrInvalidResetToken,
+		},
+		{
+			name:      "weak password",
+			req:       models.ResetPasswordRequest{Token: "token", NewPassword: "short"},
+			mResponse: mResponse{userTimes: 1},
+			wantErr:   ErrUserValidationFields,
+		},
+		{
+			name:      "token used concurrently",
+			req:       models.ResetPasswordRequest{Token: "token", NewPassword: "NewSecret1"},
+			mResponse: mResponse{userTimes: 1, resetTimes: 1, resetErr: data.ErrPasswordResetTokenNotFound},
+			wantErr:   ErrInvalidResetToken,
+		},
+		{
+			name:      "storage failed",
+			req:       models.ResetPasswordRequest{Token: "token", NewPassword: "NewSecret1"},
+			mResponse: mResponse{userTimes: 1, resetTimes: 1, resetErr: errSome},
+			wantErr:   errSome,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().
+				GetPasswordResetUser(ctx, tokenHash).
+				Times(test.mResponse.userTimes).
+				Return(user, test.mResponse.userErr)
+			_ = store.EXPECT().
+				ResetPassword(ctx, tokenHash, gomock.Any()).
+				Times(test.mResponse.resetTimes).
+				DoAndReturn(func(_ context.Context, _ []byte, password []byte) (int, error) {
+					_, err := s.passwords.verify(password, test.req.NewPassword)
+					assert.NoError(t, err)
+					return user.ID, test.mResponse.resetErr
+				})
+
+			err := s.ResetPassword(ctx, test.req)
+
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
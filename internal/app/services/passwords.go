@@ -0,0 +1,234 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordMismatch    = errors.New("password does not match")
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+const (
+	argon2idPrefix     = "$argon2id$"
+	argon2SaltLength   = 16
+	argon2KeyLength    = 32
+	argon2HashParts    = 6
+	defaultArgon2Mem   = 64 * 1024
+	defaultArgon2Time  = 3
+	defaultArgon2Procs = 2
+)
+
+var bcryptPrefixes = [][]byte{[]byte("$2a$"), []byte("$2b$"), []byte("$2y$")}
+
+// PasswordHasher хеширует пароли в самоописываемом формате: по хешу можно определить алгоритм и его параметры.
+type PasswordHasher interface {
+	Hash(password string) ([]byte, error)
+	Verify(encoded []byte, password string) error
+	// Recognizes сообщает, что хеш получен этим алгоритмом.
+	Recognizes(encoded []byte) bool
+	// NeedsRehash сообщает, что хеш получен с параметрами, отличными от текущих.
+	NeedsRehash(encoded []byte) bool
+}
+
+// Argon2idHasher кодирует хеши в формате PHC: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+type Argon2idHasher struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+func NewArgon2idHasher(memory uint32, time uint32, threads uint8) *Argon2idHasher {
+	h := &Argon2idHasher{Memory: memory, Time: time, Threads: threads}
+
+	if h.Memory == 0 {
+		h.Memory = defaultArgon2Mem
+	}
+	if h.Time == 0 {
+		h.Time = defaultArgon2Time
+	}
+	if h.Threads == 0 {
+		h.Threads = defaultArgon2Procs
+	}
+
+	return h
+}
+
+func (h *Argon2idHasher) Hash(password string) ([]byte, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, argon2KeyLength)
+
+	return []byte(fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))), nil
+}
+
+func (h *Argon2idHasher) Verify(encoded []byte, password string) error {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // Длина ключа ограничена форматом хеша и не переполняет uint32
+	candidate := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+func (h *Argon2idHasher) Recognizes(encoded []byte) bool {
+	return bytes.HasPrefix(encoded, []byte(argon2idPrefix))
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded []byte) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params != *h || len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+}
+
+func decodeArgon2id(encoded []byte) (Argon2idHasher, []byte, []byte, error) {
+	var params Argon2idHasher
+
+	parts := strings.Split(string(encoded), "$")
+	if len(parts) != argon2HashParts {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads)
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	return params, salt, key, nil
+}
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) ([]byte, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash password %w", err)
+	}
+
+	return hashedPassword, nil
+}
+
+func (h *BcryptHasher) Verify(encoded []byte, password string) error {
+	err := bcrypt.CompareHashAndPassword(encoded, []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrPasswordMismatch
+		}
+
+		return fmt.Errorf("failed to compare: %w", err)
+	}
+
+	return nil
+}
+
+func (h *BcryptHasher) Recognizes(encoded []byte) bool {
+	for _, prefix := range bcryptPrefixes {
+		if bytes.HasPrefix(encoded, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded []byte) bool {
+	cost, err := bcrypt.Cost(encoded)
+	return err != nil || cost != h.Cost
+}
+
+// passwordHashers хеширует пароли текущим алгоритмом и проверяет хеши любого из известных.
+type passwordHashers struct {
+	current PasswordHasher
+	known   []PasswordHasher
+}
+
+func newPasswordHashers(settings config.PasswordHashSettings) *passwordHashers {
+	argon := NewArgon2idHasher(settings.Argon2Memory, settings.Argon2Time, settings.Argon2Threads)
+	bcryptHasher := NewBcryptHasher(settings.BcryptCost)
+
+	hashers := &passwordHashers{
+		current: argon,
+		known:   []PasswordHasher{argon, bcryptHasher},
+	}
+
+	if settings.Algorithm == config.PasswordHashBcrypt {
+		hashers.current = bcryptHasher
+	}
+
+	return hashers
+}
+
+func (p *passwordHashers) hash(password string) ([]byte, error) {
+	hashedPassword, err := p.current.Hash(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return hashedPassword, nil
+}
+
+// verify проверяет пароль и сообщает, нужно ли пересчитать хеш текущим алгоритмом и параметрами.
+func (p *passwordHashers) verify(encoded []byte, password string) (bool, error) {
+	for _, h := range p.known {
+		if !h.Recognizes(encoded) {
+			continue
+		}
+
+		if err := h.Verify(encoded, password); err != nil {
+			return false, err //nolint:wrapcheck // Ошибки хешеров уже содержат контекст
+		}
+
+		return !p.current.Recognizes(encoded) || p.current.NeedsRehash(encoded), nil
+	}
+
+	return false, ErrUnknownPasswordHash
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestArgon2idHasher(t *testing.T) {
+	h := NewArgon2idHasher(1024, 1, 1)
+
+	encoded, err := h.Hash("Secret123")
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=1024,t=1,p=1\$[A-Za-z0-9+/]+\$[A-Za-z0-9+/]+$`, string(encoded))
+	assert.True(t, h.Recognizes(encoded))
+	assert.False(t, h.NeedsRehash(encoded))
+	assert.NoError(t, h.Verify(encoded, "Secret123"))
+	assert.ErrorIs(t, h.Verify(encoded, "Secret124"), ErrPasswordMismatch)
+
+	stronger := NewArgon2idHasher(2048, 1, 1)
+	assert.True(t, stronger.NeedsRehash(encoded))
+	assert.NoError(t, stronger.Verify(encoded, "Secret123"))
+
+	assert.ErrorIs(t, h.Verify([]byte("$argon2id$v=19$broken"), "Secret123"), ErrUnknownPasswordHash)
+}
+
+func TestBcryptHasher(t *testing.T) {
+	h := NewBcryptHasher(bcrypt.MinCost)
+
+	encoded, err := h.Hash("Secret123")
+	require.NoError(t, err)
+
+	assert.True(t, h.Recognizes(encoded))
+	assert.False(t, h.NeedsRehash(encoded))
+	assert.NoError(t, h.Verify(encoded, "Secret123"))
+	assert.ErrorIs(t, h.Verify(encoded, "Secret124"), ErrPasswordMismatch)
+	assert.True(t, NewBcryptHasher(bcrypt.MinCost+1).NeedsRehash(encoded))
+}
+
+func TestPasswordHashersVerify(t *testing.T) {
+	settings := testPasswordHashSettings()
+	hashers := newPasswordHashers(settings)
+
+	argonHash, err := hashers.hash("Secret123")
+	require.NoError(t, err)
+
+	bcryptHash, err := NewBcryptHasher(bcrypt.MinCost).Hash("Secret123")
+	require.NoError(t, err)
+
+	outdatedArgonHash, err := NewArgon2idHasher(512, 1, 1).Hash("Secret123")
+	require.NoError(t, err)
+
+	tests := []struct {
+		wantErr     error
+		name        string
+		encoded     []byte
+		password    string
+		needsRehash bool
+	}{
+		{
+			name:     "current hash",
+			encoded:  argonHash,
+			password: "Secret123",
+		},
+		{
+			name:        "legacy bcrypt hash",
+			encoded:     bcryptHash,
+			password:    "Secret123",
+			needsRehash: true,
+		},
+		{
+			name:        "outdated argon2id parameters",
+			encoded:     outdatedArgonHash,
+			password:    "Secret123",
+			needsRehash: true,
+		},
+		{
+			name:     "wrong password",
+			encoded:  bcryptHash,
+			password: "wrong",
+			wantErr:  ErrPasswordMismatch,
+		},
+		{
+			name:     "unknown format",
+			encoded:  []byte("plain"),
+			password: "plain",
+			wantErr:  ErrUnknownPasswordHash,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			needsRehash, err := hashers.verify(test.encoded, test.password)
+
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.needsRehash, needsRehash)
+		})
+	}
+
+	t.Run("bcrypt as current algorithm", func(t *testing.T) {
+		settings.Algorithm = config.PasswordHashBcrypt
+		bcryptHashers := newPasswordHashers(settings)
+
+		encoded, err := bcryptHashers.hash("Secret123")
+		require.NoError(t, err)
+		assert.True(t, NewBcryptHasher(bcrypt.MinCost).Recognizes(encoded))
+
+		needsRehash, err := bcryptHashers.verify(argonHash, "Secret123")
+		require.NoError(t, err)
+		assert.True(t, needsRehash)
+	})
+}
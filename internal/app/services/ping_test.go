@@ -17,7 +17,7 @@ func TestSuccessPing(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 
@@ -35,7 +35,7 @@ func TestFailedPing(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	errSome := errors.New("some error")
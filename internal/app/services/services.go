@@ -3,69 +3,122 @@ package services
 import (
 	"context"
 	"errors"
-	"fmt"
-	"strconv"
+	"net"
+	"net/netip"
+	"time"
 
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 var ErrOrderNumberValidation = errors.New("order number has not been validated")
 
-const (
-	baseNumber = 10
-	maxNumber  = 9
-)
-
 type Services struct {
-	store    Storager
-	settings *config.Settings
+	store        Storager
+	signer       TokenSigner
+	notifier     Notifier
+	accrual      AccrualChecker
+	auditor      Auditor
+	updates      OrderUpdatesSubscriber
+	settings     *config.Settings
+	passwords    *passwordHashers
+	orderNumbers OrderNumberValidator
+	resolver     hostResolver
+}
+
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+	JWKS() models.JWKSet
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n models.Notification) error
+}
+
+type AccrualChecker interface {
+	GetOrderAccrual(number string) (string, models.Points, error)
+}
+
+type Auditor interface {
+	Record(ctx context.Context, e models.AuditEvent)
+}
+
+// hostResolver разрешает имя хоста адреса вебхука. В тестах подменяется, чтобы не обращаться к DNS.
+type hostResolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+type OrderUpdatesSubscriber interface {
+	Subscribe(ctx context.Context, userID int) <-chan models.OrderUpdate
 }
 
 type Storager interface {
+	GetUserByID(ctx context.Context, userID int) (models.User, error)
+	GetSession(ctx context.Context, sessionID string) (models.Session, error)
 	GetUserByLogin(ctx context.Context, userLogin string) (models.User, error)
-	AddUser(ctx context.Context, userLogin string, userPassword []byte) (models.User, error)
-	GetOrdersByUserID(ctx context.Context) ([]models.Order, error)
+	AddUser(ctx context.Context, userLogin string, userEmail string, userPassword []byte) (models.User, error)
+	GetCurrentUser(ctx context.Context) (models.User, error)
+	UpdatePassword(ctx context.Context, password []byte) error
+	RehashPassword(ctx context.Context, userID int, oldHash []byte, newHash []byte) error
+	AnonymizeUser(ctx context.Context) error
+	HasAdmin(ctx context.Context) (bool, error)
+	PromoteFirstAdmin(ctx context.Context, userID int) error
+	FindUsersByLogin(ctx context.Context, login string, limit int) ([]models.User, error)
+	AddPasswordResetToken(ctx context.Context, token models.PasswordResetToken, since time.Time) (bool, error)
+	GetPasswordResetUser(ctx context.Context, tokenHash []byte) (models.User, error)
+	ResetPassword(ctx context.Context, tokenHash []byte, password []byte) (int, error)
+	GetLoginLockedUntil(ctx context.Context, login string, ip string) (time.Time, error)
+	GetThrottleLockedUntil(ctx context.Context, scope string, subject string) (time.Time, error)
+	AddLoginFailure(ctx context.Context, scope string, subject string, windowStart time.Time) (int, error)
+	LockLogin(ctx context.Context, scope string, subject string, failures int, lockedUntil time.Time) error
+	ResetLoginFailures(ctx context.Context, login string) error
+	GetTOTP(ctx context.Context, userID int) (models.TOTP, error)
+	SaveTOTPSetup(ctx context.Context, userID int, secret string, recoveryHashes [][]byte) error
+	ConfirmTOTP(ctx context.Context, userID int, step int64) error
+	UseTOTPStep(ctx context.Context, userID int, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash []byte) error
+	AddLoginChallenge(ctx context.Context, challenge models.LoginChallenge) error
+	UseLoginChallengeAttempt(ctx context.Context, tokenHash []byte, maxAttempts int) (models.LoginChallenge, error)
+	CompleteLoginChallenge(ctx context.Context, tokenHash []byte) error
+	AddSession(ctx context.Context, session models.Session) error
+	RotateSession(ctx context.Context, oldHash []byte, newHash []byte, expiresAt time.Time) (models.Session, error)
+	RevokeSession(ctx context.Context) error
+	GetOrdersByUserID(ctx context.Context, q models.OrderQuery) ([]models.Order, error)
+	AddOrders(ctx context.Context, numbers []string) ([]models.BatchOrder, error)
+	GetOrderByNumber(ctx context.Context, number string) (models.Order, error)
+	GetOrderStatusEvents(ctx context.Context, number string) ([]models.OrderStatusEvent, error)
 	AddOrder(ctx context.Context, number string) (models.Order, bool, error)
 	GetWithdrawals(ctx context.Context) ([]models.Withdraw, error)
-	AddWithdraw(ctx context.Context, orderNumber string, sum float32) error
+	AddWithdraw(ctx context.Context, orderNumber string, sum models.Points, totpStep int64) error
 	GetBalance(ctx context.Context) (models.Balance, error)
+	AdjustBalance(ctx context.Context, userID int, amount models.Points, reason string) (models.BalanceAdjustment, error)
+	OverrideOrder(ctx context.Context, override models.OrderOverride) (models.OrderOverride, error)
+	GetLedgerEntries(ctx context.Context, afterID int64, limit int) ([]models.LedgerEntry, error)
+	GetAuditEvents(ctx context.Context, q models.AuditQuery) ([]models.AuditEvent, error)
+	AddWebhook(ctx context.Context, w models.Webhook) (models.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	GetWebhookDeliveries(ctx context.Context, webhookID int64, afterID int64, limit int) ([]models.WebhookDelivery, error)
+	ReplayWebhookDeliveries(ctx context.Context, webhookID int64) (int64, error)
 	Ping(ctx context.Context) error
 	Close() error
 }
 
-func NewServices(store Storager, settings *config.Settings) *Services {
+func NewServices(store Storager, signer TokenSigner,
+	notifier Notifier, accrual AccrualChecker, auditor Auditor, updates OrderUpdatesSubscriber,
+	settings *config.Settings) *Services {
 	return &Services{
-		store:    store,
-		settings: settings,
-	}
-}
-
-func checkOrderNumber(s string) error {
-	number, err := strconv.Atoi(s)
-	if err != nil {
-		return fmt.Errorf("failed to parse order number: %w", ErrOrderNumberValidation)
-	}
-
-	sum := 0
-
-	for i := 1; number > 0; i++ {
-		digit := number % baseNumber
-
-		if i%2 == 0 {
-			digit *= 2
-			if digit > maxNumber {
-				digit = digit%baseNumber + digit/baseNumber
-			}
-		}
-
-		sum += digit
-		number /= baseNumber
+		store:        store,
+		signer:       signer,
+		notifier:     notifier,
+		accrual:      accrual,
+		auditor:      auditor,
+		updates:      updates,
+		settings:     settings,
+		passwords:    newPasswordHashers(settings.PasswordHash),
+		orderNumbers: newOrderNumberValidator(settings.OrderNumber),
+		resolver:     net.DefaultResolver,
 	}
-
-	if sum%baseNumber != 0 {
-		return fmt.Errorf("bad check sum for order number: %w", ErrOrderNumberValidation)
-	}
-
-	return nil
 }
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+
+const (
+	sessionIDSize    = 16
+	tokenIDSize      = 16
+	refreshTokenSize = 32
+)
+
+func (s *Services) RefreshToken(ctx context.Context, refreshToken string) (models.RefreshTokenResponse, error) {
+	resp := models.RefreshTokenResponse{}
+
+	if refreshToken == "" {
+		return resp, ErrInvalidRefreshToken
+	}
+
+	newRefreshToken, err := randomToken(refreshTokenSize)
+	if err != nil {
+		return resp, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.settings.Auth.RefreshTokenTTL)
+	session, err := s.store.RotateSession(ctx,
+		hashRefreshToken(refreshToken), hashRefreshToken(newRefreshToken), expiresAt)
+	if err != nil {
+		if errors.Is(err, data.ErrSessionNotFound) {
+			return resp, ErrInvalidRefreshToken
+		}
+		return resp, fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	user, err := s.store.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, data.ErrUserNotFound) {
+			return resp, ErrInvalidRefreshToken
+		}
+		return resp, fmt.Errorf("failed to get user from DB %w", err)
+	}
+
+	authToken, err := s.buildJWTString(user.ID, user.Role, session.ID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to build auth token: %w", err)
+	}
+
+	resp.AuthToken = authToken
+	resp.RefreshToken = newRefreshToken
+
+	return resp, nil
+}
+
+func (s *Services) Logout(ctx context.Context) error {
+	if err := s.store.RevokeSession(ctx); err != nil {
+		return fmt.Errorf("failed to logout: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Services) GetJWKS() models.JWKSet {
+	return s.signer.JWKS()
+}
+
+func (s *Services) createSession(ctx context.Context, userID int, role string) (string, string, error) {
+	sessionID, err := randomToken(sessionIDSize)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	refreshToken, err := randomToken(refreshTokenSize)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	session := models.Session{
+		ID:               sessionID,
+		UserID:           userID,
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt:        time.Now().Add(s.settings.Auth.RefreshTokenTTL),
+	}
+
+	if err := s.store.AddSession(ctx, session); err != nil {
+		return "", "", fmt.Errorf("failed to add session: %w", err)
+	}
+
+	authToken, err := s.buildJWTString(userID, role, sessionID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build auth token: %w", err)
+	}
+
+	return authToken, refreshToken, nil
+}
+
+func (s *Services) buildJWTString(userID int, role string, sessionID string) (string, error) {
+	tokenID, err := randomToken(tokenIDSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	now := time.Now()
+	tokenString, err := s.signer.Sign(models.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.settings.Auth.AccessTokenTTL)),
+		},
+		SessionID: sessionID,
+		Role:      role,
+		UserID:    userID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to signed token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
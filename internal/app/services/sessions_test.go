@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/tokens"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRefreshToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	errSome := errors.New("some error")
+
+	type mResponse struct {
+		session   models.Session
+		err       error
+		userErr   error
+		userTimes int
+	}
+
+	type want struct {
+		err    error
+		userID int
+	}
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		mResponse    mResponse
+		mTimes       int
+		want         want
+	}{
+		{
+			name:         "refresh token success",
+			refreshToken: "refresh",
+			mResponse: mResponse{
+				session:   models.Session{ID: "session", UserID: 1},
+				userTimes: 1,
+			},
+			mTimes: 1,
+			want: want{
+				userID: 1,
+			},
+		},
+		{
+			name:         "user deleted",
+			refreshToken: "refresh",
+			mResponse: mResponse{
+				session:   models.Session{ID: "session", UserID: 1},
+				userErr:   data.ErrUserNotFound,
+				userTimes: 1,
+			},
+			mTimes: 1,
+			want: want{
+				err: ErrInvalidRefreshToken,
+			},
+		},
+		{
+			name:         "session not found",
+			refreshToken: "refresh",
+			mResponse: mResponse{
+				err: data.ErrSessionNotFound,
+			},
+			mTimes: 1,
+			want: want{
+				err: ErrInvalidRefreshToken,
+			},
+		},
+		{
+			name:         "rotate session failed",
+			refreshToken: "refresh",
+			mResponse: mResponse{
+				err: errSome,
+			},
+			mTimes: 1,
+			want: want{
+				err: errSome,
+			},
+		},
+		{
+			name:         "empty refresh token",
+			refreshToken: "",
+			mTimes:       0,
+			want: want{
+				err: ErrInvalidRefreshToken,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().
+				RotateSession(ctx, hashRefreshToken(test.refreshToken), gomock.Any(), gomock.Any()).
+				Times(test.mTimes).
+				Return(test.mResponse.session, test.mResponse.err)
+			_ = store.EXPECT().
+				GetUserByID(ctx, test.mResponse.session.UserID).
+				Times(test.mResponse.userTimes).
+				Return(models.User{ID: 1, Role: models.RoleAdmin}, test.mResponse.userErr)
+
+			result, err := s.RefreshToken(ctx, test.refreshToken)
+
+			if test.want.err != nil {
+				assert.ErrorIs(t, err, test.want.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assertAuthToken(t, result.AuthToken, test.want.userID)
+			assertRefreshToken(t, result.RefreshToken, test.want.userID)
+
+			if test.want.userID != 0 {
+				claims := &models.Claims{}
+				_, err := jwt.ParseWithClaims(result.AuthToken, claims, testKeyset(t).Keyfunc)
+				require.NoError(t, err)
+				assert.Equal(t, models.RoleAdmin, claims.Role)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeySessionID, "session")
+	errSome := errors.New("some error")
+
+	t.Run("logout success", func(t *testing.T) {
+		_ = store.EXPECT().RevokeSession(ctx).Times(1).Return(nil)
+
+		assert.NoError(t, s.Logout(ctx))
+	})
+
+	t.Run("logout failed", func(t *testing.T) {
+		_ = store.EXPECT().RevokeSession(ctx).Times(1).Return(errSome)
+
+		assert.ErrorIs(t, s.Logout(ctx), errSome)
+	})
+}
+
+func testSettings() config.Settings {
+	return config.Settings{
+		Auth: config.AuthSettings{
+			AccessTokenTTL:  time.Minute,
+			RefreshTokenTTL: time.Hour,
+		},
+		PasswordHash: testPasswordHashSettings(),
+	}
+}
+
+func testPasswordHashSettings() config.PasswordHashSettings {
+	return config.PasswordHashSettings{
+		Algorithm:     config.PasswordHashArgon2id,
+		Argon2Memory:  1024,
+		Argon2Time:    1,
+		Argon2Threads: 1,
+		BcryptCost:    bcrypt.MinCost,
+	}
+}
+
+func sessionTimes(userID int) int {
+	if userID == 0 {
+		return 0
+	}
+
+	return 1
+}
+
+// nopAuditor принимает любые события аудита. Тесты, проверяющие аудит, создают собственный мок.
+func nopAuditor(mockCtrl *gomock.Controller) *mocks.MockAuditor {
+	a := mocks.NewMockAuditor(mockCtrl)
+	a.EXPECT().Record(gomock.Any(), gomock.Any()).AnyTimes()
+
+	return a
+}
+
+func testKeyset(t *testing.T) *tokens.Keyset {
+	t.Helper()
+
+	keys, err := tokens.NewKeyset("test", tokens.Key{ID: "test", Secret: "secret"})
+	require.NoError(t, err)
+
+	return keys
+}
+
+func assertAuthToken(t *testing.T, token string, userID int) {
+	t.Helper()
+
+	if userID == 0 {
+		assert.Empty(t, token)
+		return
+	}
+
+	claims := &models.Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, testKeyset(t).Keyfunc, jwt.WithExpirationRequired())
+	require.NoError(t, err)
+
+	assert.Equal(t, "test", parsed.Header[tokens.KeyIDHeader])
+	assert.Equal(t, userID, claims.UserID)
+	assert.NotEmpty(t, claims.SessionID)
+	assert.NotEmpty(t, claims.ID)
+	assert.NotNil(t, claims.IssuedAt)
+}
+
+func assertRefreshToken(t *testing.T, token string, userID int) {
+	t.Helper()
+
+	if userID == 0 {
+		assert.Empty(t, token)
+		return
+	}
+
+	assert.NotEmpty(t, token)
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/totp"
+)
+
+var (
+	ErrTwoFactorEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotSetup     = errors.New("two-factor authentication is not set up")
+	ErrTOTPInvalid           = errors.New("TOTP code is invalid")
+	ErrTOTPRequired          = errors.New("TOTP code is required")
+	ErrInvalidLoginChallenge = errors.New("login challenge is invalid or expired")
+	ErrTOTPLocked            = errors.New("TOTP code input is temporarily locked")
+)
+
+// TOTPLockedError сообщает, до какого момента списания с кодом второго фактора заблокированы.
+type TOTPLockedError struct {
+	Until time.Time
+}
+
+func (e *TOTPLockedError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrTOTPLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *TOTPLockedError) Unwrap() error {
+	return ErrTOTPLocked
+}
+
+// RetryAfter возвращает время до снятия блокировки.
+func (e *TOTPLockedError) RetryAfter() time.Duration {
+	return time.Until(e.Until)
+}
+
+const (
+	recoveryCodesCount = 10
+	recoveryCodeSize   = 8
+	challengeTokenSize = 32
+)
+
+func (s *Services) SetupTwoFactor(ctx context.Context) (models.TwoFactorSetupResponse, error) {
+	resp := models.TwoFactorSetupResponse{}
+
+	user, err := s.store.GetCurrentUser(ctx)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get user from DB %w", err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return resp, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([][]byte, 0, recoveryCodesCount)
+
+	for range recoveryCodesCount {
+		code, err := randomToken(recoveryCodeSize)
+		if err != nil {
+			return resp, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.store.SaveTOTPSetup(ctx, user.ID, secret, hashes); err != nil {
+		if errors.Is(err, data.ErrTOTPAlreadyEnabled) {
+			return resp, ErrTwoFactorEnabled
+		}
+		return resp, fmt.Errorf("failed to save TOTP setup: %w", err)
+	}
+
+	resp.OTPAuthURI = totp.URI(s.settings.TwoFactor.Issuer, user.Login, secret)
+	resp.Secret = secret
+	resp.RecoveryCodes = codes
+
+	return resp, nil
+}
+
+func (s *Services) ConfirmTwoFactor(ctx context.Context, req models.TwoFactorCodeRequest) error {
+	userID, _ := ctx.Value(common.KeyUserID).(int)
+
+	t, err := s.store.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, data.ErrTOTPNotFound) {
+			return ErrTwoFactorNotSetup
+		}
+		return fmt.Errorf("failed to get TOTP: %w", err)
+	}
+
+	if t.ConfirmedAt != nil {
+		return ErrTwoFactorEnabled
+	}
+
+	step, ok, err := totp.Validate(t.Secret, req.Code, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to validate TOTP code: %w", err)
+	}
+
+	if !ok || step <= t.LastUsedStep {
+		return ErrTOTPInvalid
+	}
+
+	if err := s.store.ConfirmTOTP(ctx, userID, step); err != nil {
+		if errors.Is(err, data.ErrTOTPNotFound) {
+			return ErrTOTPInvalid
+		}
+		return fmt.Errorf("failed to confirm TOTP: %w", err)
+	}
+
+	s.recordUserEvent(ctx, models.AuditTwoFactorEnabled, userID, nil, nil)
+
+	return nil
+}
+
+// CompleteTwoFactorLogin завершает вход по токену, выданному LoginUser, и коду TOTP или коду восстановления.
+func (s *Services) CompleteTwoFactorLogin(ctx context.Context,
+	req models.TwoFactorLoginRequest) (models.LoginUserResponse, error) {
+	resp := models.LoginUserResponse{}
+
+	if req.ChallengeToken == "" {
+		return resp, ErrInvalidLoginChallenge
+	}
+
+	tokenHash := hashRefreshToken(req.ChallengeToken)
+
+	challenge, err := s.store.UseLoginChallengeAttempt(ctx, tokenHash, s.settings.TwoFactor.MaxChallengeAttempts)
+	if err != nil {
+		if errors.Is(err, data.ErrLoginChallengeNotFound) {
+			return resp, ErrInvalidLoginChallenge
+		}
+		return resp, fmt.Errorf("failed to get login challenge: %w", err)
+	}
+
+	t, err := s.store.GetTOTP(ctx, challenge.UserID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+
+	if err := s.verifySecondFactor(ctx, t, req.Code, true); err != nil {
+		if errors.Is(err, ErrTOTPInvalid) {
+			s.recordLoginFailure(ctx, challenge.UserID, "", "second_factor")
+		}
+		return resp, err
+	}
+
+	if err := s.store.CompleteLoginChallenge(ctx, tokenHash); err != nil {
+		if errors.Is(err, data.ErrLoginChallengeNotFound) {
+			return resp, ErrInvalidLoginChallenge
+		}
+		return resp, fmt.Errorf("failed to complete login challenge: %w", err)
+	}
+
+	user, err := s.store.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, data.ErrUserNotFound) {
+			return resp, ErrInvalidLoginChallenge
+		}
+		return resp, fmt.Errorf("failed to get user from DB %w", err)
+	}
+
+	authToken, refreshToken, err := s.createSession(ctx, user.ID, user.Role)
+	if err != nil {
+		return resp, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	s.recordLogin(ctx, user.ID, true)
+
+	resp.AuthToken = authToken
+	resp.RefreshToken = refreshToken
+
+	return resp, nil
+}
+
+// twoFactorEnabled возвращает подтвержденный TOTP пользователя, если он есть.
+func (s *Services) twoFactorEnabled(ctx context.Context, userID int) (models.TOTP, bool, error) {
+	t, err := s.store.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, data.ErrTOTPNotFound) {
+			return t, false, nil
+		}
+		return t, false, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+
+	return t, t.ConfirmedAt != nil, nil
+}
+
+func (s *Services) createLoginChallenge(ctx context.Context, userID int) (string, error) {
+	token, err := randomToken(challengeTokenSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+
+	challenge := models.LoginChallenge{
+		TokenHash: hashRefreshToken(token),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.settings.TwoFactor.ChallengeTTL),
+	}
+
+	if err := s.store.AddLoginChallenge(ctx, challenge); err != nil {
+		return "", fmt.Errorf("failed to add login challenge: %w", err)
+	}
+
+	return token, nil
+}
+
+// checkWithdrawTOTP требует свежий код TOTP для списаний больше порога, если у пользователя включен второй фактор,
+// и возвращает интервал кода. Код расходуется в транзакции списания, неверные коды учитываются для блокировки.
+func (s *Services) checkWithdrawTOTP(ctx context.Context, req models.AddWithdrawRequest) (int64, error) {
+	threshold := s.settings.TwoFactor.WithdrawThreshold
+	if threshold <= 0 || req.Sum <= threshold {
+		return 0, nil
+	}
+
+	userID, _ := ctx.Value(common.KeyUserID).(int)
+
+	t, enabled, err := s.twoFactorEnabled(ctx, userID)
+	if err != nil || !enabled {
+		return 0, err
+	}
+
+	if err := s.checkTOTPLock(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	if req.TOTPCode == "" {
+		return 0, ErrTOTPRequired
+	}
+
+	step, err := validateTOTPCode(t, req.TOTPCode)
+	if err != nil {
+		if errors.Is(err, ErrTOTPInvalid) {
+			return 0, s.registerTOTPFailure(ctx, userID)
+		}
+		return 0, err
+	}
+
+	return step, nil
+}
+
+// verifySecondFactor проверяет код TOTP, а если allowRecovery — и код восстановления.
+// Каждый код принимается только один раз.
+func (s *Services) verifySecondFactor(ctx context.Context, t models.TOTP, code string, allowRecovery bool) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) != totp.Digits && allowRecovery {
+		if err := s.store.UseRecoveryCode(ctx, t.UserID, hashRecoveryCode(code)); err != nil {
+			if errors.Is(err, data.ErrRecoveryCodeNotFound) {
+				return ErrTOTPInvalid
+			}
+			return fmt.Errorf("failed to use recovery code: %w", err)
+		}
+
+		return nil
+	}
+
+	step, err := validateTOTPCode(t, code)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.UseTOTPStep(ctx, t.UserID, step); err != nil {
+		if errors.Is(err, data.ErrTOTPStepUsed) {
+			return ErrTOTPInvalid
+		}
+		return fmt.Errorf("failed to use TOTP code: %w", err)
+	}
+
+	return nil
+}
+
+// validateTOTPCode проверяет код TOTP и возвращает его интервал, не отмечая код использованным.
+func validateTOTPCode(t models.TOTP, code string) (int64, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) != totp.Digits {
+		return 0, ErrTOTPInvalid
+	}
+
+	step, ok, err := totp.Validate(t.Secret, code, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to validate TOTP code: %w", err)
+	}
+
+	if !ok {
+		return 0, ErrTOTPInvalid
+	}
+
+	return step, nil
+}
+
+func hashRecoveryCode(code string) []byte {
+	h := sha256.Sum256([]byte(code))
+	return h[:]
+}
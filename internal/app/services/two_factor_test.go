@@ -0,0 +1,442 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/MihailSergeenkov/gophermart/internal/app/totp"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTOTP(t *testing.T, userID int) (models.TOTP, string) {
+	t.Helper()
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	confirmedAt := time.Now()
+
+	return models.TOTP{UserID: userID, Secret: secret, ConfirmedAt: &confirmedAt}, code
+}
+
+func TestSetupTwoFactor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.TwoFactor.Issuer = "Gophermart"
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	user := models.User{ID: 1, Login: "test"}
+
+	t.Run("setup success", func(t *testing.T) {
+		var savedHashes [][]byte
+
+		_ = store.EXPECT().GetCurrentUser(ctx).Times(1).Return(user, nil)
+		_ = store.EXPECT().
+			SaveTOTPSetup(ctx, user.ID, gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, _ int, _ string, hashes [][]byte) error {
+				savedHashes = hashes
+				return nil
+			})
+
+		res, err := s.SetupTwoFactor(ctx)
+		require.NoError(t, err)
+
+		assert.Contains(t, res.OTPAuthURI, "otpauth://totp/Gophermart:test?")
+		assert.Contains(t, res.OTPAuthURI, "secret="+res.Secret)
+		require.Len(t, res.RecoveryCodes, recoveryCodesCount)
+		require.Len(t, savedHashes, recoveryCodesCount)
+		for i, code := range res.RecoveryCodes {
+			assert.Equal(t, hashRecoveryCode(code), savedHashes[i])
+		}
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		_ = store.EXPECT().GetCurrentUser(ctx).Times(1).Return(user, nil)
+		_ = store.EXPECT().
+			SaveTOTPSetup(ctx, user.ID, gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(data.ErrTOTPAlreadyEnabled)
+
+		_, err := s.SetupTwoFactor(ctx)
+
+		assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+	})
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+
+	pending, code := testTOTP(t, 1)
+	pending.ConfirmedAt = nil
+	enabled, _ := testTOTP(t, 1)
+
+	type mResponse struct {
+		getErr       error
+		totp         models.TOTP
+		confirmTimes int
+	}
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		code      string
+		mResponse mResponse
+	}{
+		{
+			name:      "confirm success",
+			code:      code,
+			mResponse: mResponse{totp: pending, confirmTimes: 1},
+		},
+		{
+			name:      "invalid code",
+			code:      "000000x",
+			mResponse: mResponse{totp: pending},
+			wantErr:   ErrTOTPInvalid,
+		},
+		{
+			name:      "not set up",
+			code:      code,
+			mResponse: mResponse{getErr: data.ErrTOTPNotFound},
+			wantErr:   ErrTwoFactorNotSetup,
+		},
+		{
+			name:      "already confirmed",
+			code:      code,
+			mResponse: mResponse{totp: enabled},
+			wantErr:   ErrTwoFactorEnabled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().GetTOTP(ctx, 1).Times(1).Return(test.mResponse.totp, test.mResponse.getErr)
+			_ = store.EXPECT().ConfirmTOTP(ctx, 1, gomock.Any()).Times(test.mResponse.confirmTimes).Return(nil)
+
+			err := s.ConfirmTwoFactor(ctx, models.TwoFactorCodeRequest{Code: test.code})
+
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestLoginUserWithTwoFactor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.TwoFactor.ChallengeTTL = time.Minute
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+
+	password, err := s.passwords.hash("test")
+	require.NoError(t, err)
+
+	user := models.User{ID: 1, Login: "test", Password: password}
+	enabled, _ := testTOTP(t, user.ID)
+
+	_ = store.EXPECT().GetUserByLogin(ctx, "test").Times(1).Return(user, nil)
+	_ = store.EXPECT().GetTOTP(ctx, user.ID).Times(1).Return(enabled, nil)
+	_ = store.EXPECT().AddSession(ctx, gomock.Any()).Times(0)
+	_ = store.EXPECT().
+		AddLoginChallenge(ctx, gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, c models.LoginChallenge) error {
+			assert.Equal(t, user.ID, c.UserID)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), c.ExpiresAt, time.Second)
+			return nil
+		})
+
+	res, err := s.LoginUser(ctx, models.LoginUserRequest{Login: "test", Password: "test"})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, res.ChallengeToken)
+	assert.Empty(t, res.AuthToken)
+	assert.Empty(t, res.RefreshToken)
+}
+
+func TestCompleteTwoFactorLogin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.TwoFactor.MaxChallengeAttempts = 5
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+	errSome := errors.New("some error")
+	enabled, code := testTOTP(t, 1)
+	challenge := models.LoginChallenge{TokenHash: hashRefreshToken("challenge"), UserID: 1}
+
+	type mResponse struct {
+		challengeErr  error
+		stepErr       error
+		recoveryErr   error
+		stepTimes     int
+		recoveryTimes int
+		completeTimes int
+	}
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		code      string
+		mResponse mResponse
+		userID    int
+	}{
+		{
+			name:      "totp code",
+			code:      code,
+			mResponse: mResponse{stepTimes: 1, completeTimes: 1},
+			userID:    1,
+		},
+		{
+			name:      "recovery code",
+			code:      "recovery-code",
+			mResponse: mResponse{recoveryTimes: 1, completeTimes: 1},
+			userID:    1,
+		},
+		{
+			name:      "unknown recovery code",
+			code:      "recovery-code",
+			mResponse: mResponse{recoveryTimes: 1, recoveryErr: data.ErrRecoveryCodeNotFound},
+			wantErr:   ErrTOTPInvalid,
+		},
+		{
+			name:      "reused totp code",
+			code:      code,
+			mResponse: mResponse{stepTimes: 1, stepErr: data.ErrTOTPStepUsed},
+			wantErr:   ErrTOTPInvalid,
+		},
+		{
+			name:      "wrong totp code",
+			code:      "00000a",
+			mResponse: mResponse{},
+			wantErr:   ErrTOTPInvalid,
+		},
+		{
+			name:      "expired challenge",
+			code:      code,
+			mResponse: mResponse{challengeErr: data.ErrLoginChallengeNotFound},
+			wantErr:   ErrInvalidLoginChallenge,
+		},
+		{
+			name:      "storage failed",
+			code:      code,
+			mResponse: mResponse{challengeErr: errSome},
+			wantErr:   errSome,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getTimes := 1
+			if test.mResponse.challengeErr != nil {
+				getTimes = 0
+			}
+
+			_ = store.EXPECT().
+				UseLoginChallengeAttempt(ctx, challenge.TokenHash, 5).
+				Times(1).
+				Return(challenge, test.mResponse.challengeErr)
+			_ = store.EXPECT().GetTOTP(ctx, 1).Times(getTimes).Return(enabled, nil)
+			_ = store.EXPECT().UseTOTPStep(ctx, 1, gomock.Any()).Times(test.mResponse.stepTimes).Return(test.mResponse.stepErr)
+			_ = store.EXPECT().
+				UseRecoveryCode(ctx, 1, hashRecoveryCode("recovery-code")).
+				Times(test.mResponse.recoveryTimes).
+				Return(test.mResponse.recoveryErr)
+			_ = store.EXPECT().CompleteLoginChallenge(ctx, challenge.TokenHash).Times(test.mResponse.completeTimes).Return(nil)
+			_ = store.EXPECT().
+				GetUserByID(ctx, 1).
+				Times(sessionTimes(test.userID)).
+				Return(models.User{ID: 1, Role: models.RoleUser}, nil)
+			_ = store.EXPECT().AddSession(ctx, gomock.Any()).Times(sessionTimes(test.userID)).Return(nil)
+
+			res, err := s.CompleteTwoFactorLogin(ctx, models.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: test.code})
+
+			assertAuthToken(t, res.AuthToken, test.userID)
+
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestAddWithdrawWithTwoFactor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.TwoFactor.WithdrawThreshold = 100000
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	enabled, code := testTOTP(t, 1)
+
+	type mResponse struct {
+		totpErr       error
+		totp          models.TOTP
+		getTimes      int
+		withdrawTimes int
+		withStep      bool
+	}
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		req       models.AddWithdrawRequest
+		mResponse mResponse
+	}{
+		{
+			name:      "below threshold",
+			req:       models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100000},
+			mResponse: mResponse{withdrawTimes: 1},
+		},
+		{
+			name:      "above threshold without two-factor",
+			req:       models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100001},
+			mResponse: mResponse{totpErr: data.ErrTOTPNotFound, getTimes: 1, withdrawTimes: 1},
+		},
+		{
+			name:      "above threshold without code",
+			req:       models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100001},
+			mResponse: mResponse{totp: enabled, getTimes: 1},
+			wantErr:   ErrTOTPRequired,
+		},
+		{
+			name:      "above threshold with recovery code",
+			req:       models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100001, TOTPCode: "recovery-code"},
+			mResponse: mResponse{totp: enabled, getTimes: 1},
+			wantErr:   ErrTOTPInvalid,
+		},
+		{
+			name:      "above threshold with code",
+			req:       models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100001, TOTPCode: code},
+			mResponse: mResponse{totp: enabled, getTimes: 1, withdrawTimes: 1, withStep: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Код расходуется только в транзакции списания.
+			var step any = int64(0)
+			if test.mResponse.withStep {
+				step = gomock.Not(int64(0))
+			}
+
+			_ = store.EXPECT().GetTOTP(ctx, 1).Times(test.mResponse.getTimes).Return(test.mResponse.totp, test.mResponse.totpErr)
+			_ = store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			_ = store.EXPECT().
+				AddWithdraw(ctx, test.req.OrderNumber, test.req.Sum, step).
+				Times(test.mResponse.withdrawTimes).
+				Return(nil)
+
+			err := s.AddWithdraw(ctx, test.req)
+
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestAddWithdrawTOTPLockout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := testSettings()
+	settings.TwoFactor.WithdrawThreshold = 100000
+	settings.TwoFactor.MaxWithdrawFailures = 2
+	settings.LoginThrottle = config.LoginThrottleSettings{
+		FailureWindow: 15 * time.Minute,
+		BaseLockout:   time.Minute,
+		MaxLockout:    time.Hour,
+	}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	enabled, code := testTOTP(t, 1)
+	wrong := models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100001, TOTPCode: "000000"}
+	if code == wrong.TOTPCode {
+		wrong.TOTPCode = "111111"
+	}
+
+	_ = store.EXPECT().GetTOTP(ctx, 1).AnyTimes().Return(enabled, nil)
+
+	t.Run("first wrong code", func(t *testing.T) {
+		_ = store.EXPECT().GetThrottleLockedUntil(ctx, models.LoginScopeTOTP, "1").Times(1).Return(time.Time{}, nil)
+		_ = store.EXPECT().AddLoginFailure(ctx, models.LoginScopeTOTP, "1", gomock.Any()).Times(1).Return(1, nil)
+
+		assert.ErrorIs(t, s.AddWithdraw(ctx, wrong), ErrTOTPInvalid)
+	})
+
+	t.Run("wrong code over limit locks input", func(t *testing.T) {
+		_ = store.EXPECT().GetThrottleLockedUntil(ctx, models.LoginScopeTOTP, "1").Times(1).Return(time.Time{}, nil)
+		_ = store.EXPECT().AddLoginFailure(ctx, models.LoginScopeTOTP, "1", gomock.Any()).Times(1).Return(2, nil)
+		_ = store.EXPECT().LockLogin(ctx, models.LoginScopeTOTP, "1", 2, gomock.Any()).Times(1).Return(nil)
+
+		err := s.AddWithdraw(ctx, wrong)
+
+		var lockedErr *TOTPLockedError
+		require.ErrorAs(t, err, &lockedErr)
+		assert.InDelta(t, time.Minute.Seconds(), lockedErr.RetryAfter().Seconds(), 5)
+	})
+
+	t.Run("locked input rejects valid code", func(t *testing.T) {
+		_ = store.EXPECT().GetThrottleLockedUntil(ctx, models.LoginScopeTOTP, "1").Times(1).
+			Return(time.Now().Add(time.Minute), nil)
+
+		err := s.AddWithdraw(ctx, models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100001, TOTPCode: code})
+		assert.ErrorIs(t, err, ErrTOTPLocked)
+	})
+
+	t.Run("code used by concurrent withdrawal", func(t *testing.T) {
+		_ = store.EXPECT().GetThrottleLockedUntil(ctx, models.LoginScopeTOTP, "1").Times(1).Return(time.Time{}, nil)
+		_ = store.EXPECT().AddWithdraw(ctx, "2377225624", models.Points(100001), gomock.Not(int64(0))).Times(1).
+			Return(data.ErrTOTPStepUsed)
+		_ = store.EXPECT().AddLoginFailure(ctx, models.LoginScopeTOTP, "1", gomock.Any()).Times(1).Return(1, nil)
+
+		err := s.AddWithdraw(ctx, models.AddWithdrawRequest{OrderNumber: "2377225624", Sum: 100001, TOTPCode: code})
+		assert.ErrorIs(t, err, ErrTOTPInvalid)
+	})
+}
@@ -5,50 +5,40 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
 	"github.com/MihailSergeenkov/gophermart/internal/app/config"
 	"github.com/MihailSergeenkov/gophermart/internal/app/data"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrUserValidationFields = errors.New("some fields have not been validated")
 	ErrUserLoginExist       = errors.New("user already exist")
 	ErrUserLoginCreds       = errors.New("user has invalid login or password")
+	ErrUserCurrentPassword  = errors.New("current password is invalid")
 )
 
 func (s *Services) RegisterUser(ctx context.Context,
 	req models.RegisterUserRequest) (models.RegisterUserResponse, error) {
 	resp := models.RegisterUserResponse{}
 
-	if err := validateRequest(req); err != nil {
-		return resp, fmt.Errorf("failed to validate fields %w", err)
-	}
-
-	hashedPassword, err := hashPassword(req.Password)
+	user, err := s.addUser(ctx, req)
 	if err != nil {
-		return resp, fmt.Errorf("failed to hash passwords %w", err)
+		return resp, err
 	}
 
-	user, err := s.store.AddUser(ctx, req.Login, hashedPassword)
-	if err != nil {
-		var pgxError *pgconn.PgError
-		if errors.As(err, &pgxError) && pgxError.Code == pgerrcode.UniqueViolation {
-			return resp, ErrUserLoginExist
-		}
-		return resp, fmt.Errorf("failed to add user %w", err)
-	}
+	s.recordUserEvent(context.WithValue(ctx, common.KeyUserID, user.ID), models.AuditUserRegistered, user.ID,
+		nil, map[string]any{"login": user.Login})
 
-	authToken, err := buildJWTString(s.settings, user.ID)
+	authToken, refreshToken, err := s.createSession(ctx, user.ID, user.Role)
 	if err != nil {
-		return resp, fmt.Errorf("failed to build auth token: %w", err)
+		return resp, fmt.Errorf("failed to create session: %w", err)
 	}
 
 	resp.AuthToken = authToken
+	resp.RefreshToken = refreshToken
 
 	return resp, nil
 }
@@ -56,66 +46,182 @@ func (s *Services) RegisterUser(ctx context.Context,
 func (s *Services) LoginUser(ctx context.Context, req models.LoginUserRequest) (models.LoginUserResponse, error) {
 	resp := models.LoginUserResponse{}
 
+	// Такого логина не может быть в базе, а в счетчик попыток он не поместится.
+	if len([]rune(req.Login)) > config.MaxLoginLength {
+		return resp, ErrUserLoginCreds
+	}
+
+	if err := s.checkLoginLock(ctx, req); err != nil {
+		if errors.Is(err, ErrUserLoginLocked) {
+			s.recordLoginFailure(ctx, 0, req.Login, "locked")
+		}
+		return resp, err
+	}
+
 	user, err := s.store.GetUserByLogin(ctx, req.Login)
 	if err != nil {
 		if errors.Is(err, data.ErrUserNotFound) {
-			return resp, ErrUserLoginCreds
+			s.recordLoginFailure(ctx, 0, req.Login, "unknown_login")
+			return resp, s.registerLoginFailure(ctx, req)
 		}
 		return resp, fmt.Errorf("failed to get user from DB %w", err)
 	}
 
-	if err := verifyPassword(user.Password, req.Password); err != nil {
-		return resp, ErrUserLoginCreds
+	needsRehash, err := s.passwords.verify(user.Password, req.Password)
+	if err != nil {
+		if errors.Is(err, ErrPasswordMismatch) {
+			s.recordLoginFailure(ctx, user.ID, req.Login, "wrong_password")
+			return resp, s.registerLoginFailure(ctx, req)
+		}
+		return resp, fmt.Errorf("failed to verify password %w", err)
 	}
 
-	authToken, err := buildJWTString(s.settings, user.ID)
+	if err := s.resetLoginFailures(ctx, req); err != nil {
+		return resp, err
+	}
+
+	if needsRehash {
+		if err := s.rehashPassword(ctx, user, req.Password); err != nil {
+			return resp, err
+		}
+	}
+
+	_, twoFactor, err := s.twoFactorEnabled(ctx, user.ID)
 	if err != nil {
-		return resp, fmt.Errorf("failed to build auth token: %w", err)
+		return resp, err
 	}
 
+	if twoFactor {
+		challengeToken, err := s.createLoginChallenge(ctx, user.ID)
+		if err != nil {
+			return resp, err
+		}
+
+		resp.ChallengeToken = challengeToken
+		return resp, nil
+	}
+
+	authToken, refreshToken, err := s.createSession(ctx, user.ID, user.Role)
+	if err != nil {
+		return resp, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	s.recordLogin(ctx, user.ID, false)
+
 	resp.AuthToken = authToken
+	resp.RefreshToken = refreshToken
 
 	return resp, nil
 }
 
-func validateRequest(req models.RegisterUserRequest) error {
-	if req.Login == "" {
-		return ErrUserValidationFields
-	}
-	if req.Password == "" {
-		return ErrUserValidationFields
-	}
+// recordLogin записывает успешный вход. Вход выполняется без аутентификации, поэтому инициатором
+// события считается сам пользователь.
+func (s *Services) recordLogin(ctx context.Context, userID int, twoFactor bool) {
+	s.recordUserEvent(context.WithValue(ctx, common.KeyUserID, userID), models.AuditLogin, userID,
+		nil, map[string]any{"two_factor": twoFactor})
+}
 
-	return nil
+// recordLoginFailure записывает неудачную попытку входа. Для неизвестного логина userID нулевой.
+func (s *Services) recordLoginFailure(ctx context.Context, userID int, login string, reason string) {
+	s.recordUserEvent(ctx, models.AuditLoginFailed, userID, nil, map[string]any{"login": login, "reason": reason})
 }
 
-func hashPassword(password string) ([]byte, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (s *Services) GetCurrentUser(ctx context.Context) (models.UserProfile, error) {
+	user, err := s.store.GetCurrentUser(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not hash password %w", err)
+		return models.UserProfile{}, fmt.Errorf("failed to get user from DB %w", err)
 	}
 
-	return hashedPassword, nil
+	return userProfile(user), nil
 }
 
-func verifyPassword(hashedPassword []byte, candidatePassword string) error {
-	err := bcrypt.CompareHashAndPassword(hashedPassword, []byte(candidatePassword))
+func userProfile(user models.User) models.UserProfile {
+	return models.UserProfile{
+		ID:                user.ID,
+		Login:             user.Login,
+		Email:             user.Email,
+		Role:              user.Role,
+		CreatedAt:         user.CreatedAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+	}
+}
+
+func (s *Services) ChangePassword(ctx context.Context, req models.ChangePasswordRequest) error {
+	user, err := s.store.GetCurrentUser(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to compare: %w", err)
+		return fmt.Errorf("failed to get user from DB %w", err)
+	}
+
+	if _, err := s.passwords.verify(user.Password, req.CurrentPassword); err != nil {
+		if errors.Is(err, ErrPasswordMismatch) {
+			return ErrUserCurrentPassword
+		}
+		return fmt.Errorf("failed to verify password %w", err)
+	}
+
+	if violations := validatePassword(s.settings.Credentials,
+		fieldNewPassword, user.Login, req.NewPassword); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	hashedPassword, err := s.passwords.hash(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash passwords %w", err)
+	}
+
+	if err := s.store.UpdatePassword(ctx, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password %w", err)
+	}
+
+	s.recordUserEvent(ctx, models.AuditPasswordChanged, user.ID, nil, nil)
+
+	return nil
+}
+
+func (s *Services) DeleteUser(ctx context.Context) error {
+	if err := s.store.AnonymizeUser(ctx); err != nil {
+		return fmt.Errorf("failed to delete user %w", err)
 	}
 
+	userID, _ := ctx.Value(common.KeyUserID).(int)
+	s.recordUserEvent(ctx, models.AuditUserDeleted, userID, nil, nil)
+
 	return nil
 }
 
-func buildJWTString(settings *config.Settings, userID int) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, models.Claims{
-		UserID: userID,
-	})
+func (s *Services) addUser(ctx context.Context, req models.RegisterUserRequest) (models.User, error) {
+	if err := validateCredentials(s.settings.Credentials, req.Login, req.Email, req.Password); err != nil {
+		return models.User{}, fmt.Errorf("failed to validate fields %w", err)
+	}
 
-	tokenString, err := token.SignedString([]byte(settings.SecretKey))
+	hashedPassword, err := s.passwords.hash(req.Password)
 	if err != nil {
-		return "", fmt.Errorf("failed to signed token: %w", err)
+		return models.User{}, fmt.Errorf("failed to hash passwords %w", err)
+	}
+
+	user, err := s.store.AddUser(ctx, req.Login, req.Email, hashedPassword)
+	if err != nil {
+		var pgxError *pgconn.PgError
+		if errors.As(err, &pgxError) && pgxError.Code == pgerrcode.UniqueViolation {
+			return models.User{}, ErrUserLoginExist
+		}
+		return models.User{}, fmt.Errorf("failed to add user %w", err)
 	}
 
-	return tokenString, nil
+	return user, nil
+}
+
+// rehashPassword пересчитывает хеш пароля текущим алгоритмом. Хеш меняется, только если пароль
+// не успели сменить параллельно.
+func (s *Services) rehashPassword(ctx context.Context, user models.User, password string) error {
+	hashedPassword, err := s.passwords.hash(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash passwords %w", err)
+	}
+
+	if err := s.store.RehashPassword(ctx, user.ID, user.Password, hashedPassword); err != nil {
+		return fmt.Errorf("failed to rehash password %w", err)
+	}
+
+	return nil
 }
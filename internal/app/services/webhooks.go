@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/clients"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+)
+
+var (
+	ErrWebhookValidation = errors.New("webhook has not been validated")
+	ErrWebhookNotFound   = errors.New("webhook not found")
+)
+
+const (
+	maxWebhookURLLength  = 2000
+	minWebhookSecretSize = 16
+	maxWebhookSecretSize = 200
+	webhookSecretSize    = 32
+)
+
+var webhookEventTypes = map[string]bool{
+	models.WebhookOrderProcessed:  true,
+	models.WebhookPointsWithdrawn: true,
+}
+
+// CreateWebhook подписывает текущего пользователя на события. Секрет возвращается только в ответе
+// на создание, им подписываются тела запросов.
+func (s *Services) CreateWebhook(ctx context.Context, req models.WebhookRequest) (models.Webhook, error) {
+	if err := s.checkWebhookURL(ctx, req.URL); err != nil {
+		return models.Webhook{}, err
+	}
+
+	eventTypes, err := webhookEvents(req.EventTypes)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = randomToken(webhookSecretSize)
+		if err != nil {
+			return models.Webhook{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	}
+
+	if len(secret) < minWebhookSecretSize || len(secret) > maxWebhookSecretSize {
+		return models.Webhook{}, fmt.Errorf("secret must have from %d to %d characters: %w",
+			minWebhookSecretSize, maxWebhookSecretSize, ErrWebhookValidation)
+	}
+
+	w, err := s.store.AddWebhook(ctx, models.Webhook{URL: req.URL, Secret: secret, EventTypes: eventTypes})
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	return w, nil
+}
+
+func (s *Services) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	webhooks, err := s.store.GetWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (s *Services) DeleteWebhook(ctx context.Context, id int64) error {
+	if err := s.store.DeleteWebhook(ctx, id); err != nil {
+		if errors.Is(err, data.ErrWebhookNotFound) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Services) GetWebhookDeliveries(ctx context.Context,
+	req models.WebhookDeliveriesRequest) ([]models.WebhookDelivery, error) {
+	limit, err := pageLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AfterID < 0 {
+		return nil, fmt.Errorf("negative cursor: %w", ErrPaginationValidation)
+	}
+
+	if err := s.checkWebhook(ctx, req.WebhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.store.GetWebhookDeliveries(ctx, req.WebhookID, req.AfterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayWebhookDeliveries возвращает в очередь неудачные доставки подписки и возвращает их количество.
+func (s *Services) ReplayWebhookDeliveries(ctx context.Context, id int64) (models.WebhookReplayResponse, error) {
+	if err := s.checkWebhook(ctx, id); err != nil {
+		return models.WebhookReplayResponse{}, err
+	}
+
+	replayed, err := s.store.ReplayWebhookDeliveries(ctx, id)
+	if err != nil {
+		return models.WebhookReplayResponse{}, fmt.Errorf("failed to replay webhook deliveries: %w", err)
+	}
+
+	return models.WebhookReplayResponse{Replayed: replayed}, nil
+}
+
+// checkWebhook проверяет, что подписка принадлежит текущему пользователю.
+func (s *Services) checkWebhook(ctx context.Context, id int64) error {
+	if _, err := s.store.GetWebhook(ctx, id); err != nil {
+		if errors.Is(err, data.ErrWebhookNotFound) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return nil
+}
+
+// checkWebhookURL принимает абсолютные адреса https. Адреса http допускаются только в режиме разработки.
+func (s *Services) checkWebhookURL(ctx context.Context, rawURL string) error {
+	if len(rawURL) > maxWebhookURLLength {
+		return fmt.Errorf("url is longer than %d characters: %w", maxWebhookURLLength, ErrWebhookValidation)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse url: %w", ErrWebhookValidation)
+	}
+
+	if u.Host == "" || u.User != nil {
+		return fmt.Errorf("url must be absolute and without credentials: %w", ErrWebhookValidation)
+	}
+
+	if u.Scheme != "https" && (u.Scheme != "http" || !s.settings.DevMode) {
+		return fmt.Errorf("url scheme %q is not allowed: %w", u.Scheme, ErrWebhookValidation)
+	}
+
+	if s.settings.Webhooks.AllowPrivate {
+		return nil
+	}
+
+	return s.checkWebhookHost(ctx, u.Hostname())
+}
+
+// checkWebhookHost запрещает адреса, которые разрешаются в локальные и частные сети. Это первая линия защиты:
+// при доставке адрес проверяется повторно, уже после разрешения имени.
+func (s *Services) checkWebhookHost(ctx context.Context, host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("host %q is not public: %w", host, ErrWebhookValidation)
+	}
+
+	addrs := make([]netip.Addr, 0, 1)
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		addrs, err = s.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil || len(addrs) == 0 {
+			return fmt.Errorf("failed to resolve host %q: %w", host, ErrWebhookValidation)
+		}
+	}
+
+	for _, addr := range addrs {
+		if !clients.IsPublicAddr(addr) {
+			return fmt.Errorf("host %q resolves to non-public address %s: %w", host, addr, ErrWebhookValidation)
+		}
+	}
+
+	return nil
+}
+
+func webhookEvents(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("event types are empty: %w", ErrWebhookValidation)
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	unique := make([]string, 0, len(eventTypes))
+
+	for _, t := range eventTypes {
+		if !webhookEventTypes[t] {
+			return nil, fmt.Errorf("unknown event type %q: %w", t, ErrWebhookValidation)
+		}
+
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+
+	return unique, nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/data"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/MihailSergeenkov/gophermart/internal/app/services/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+	s.resolver = fakeResolver{
+		"partner.example":  {netip.MustParseAddr("93.184.216.34")},
+		"internal.example": {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")},
+	}
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	secret := "0123456789abcdef"
+
+	t.Run("secret from request", func(t *testing.T) {
+		want := models.Webhook{
+			URL:        "https://partner.example/hooks",
+			Secret:     secret,
+			EventTypes: []string{models.WebhookOrderProcessed, models.WebhookPointsWithdrawn},
+		}
+		_ = store.EXPECT().AddWebhook(ctx, want).Times(1).Return(want, nil)
+
+		res, err := s.CreateWebhook(ctx, models.WebhookRequest{
+			URL:    want.URL,
+			Secret: secret,
+			EventTypes: []string{
+				models.WebhookOrderProcessed, models.WebhookPointsWithdrawn, models.WebhookOrderProcessed,
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, want, res)
+	})
+
+	t.Run("generated secret", func(t *testing.T) {
+		_ = store.EXPECT().AddWebhook(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, w models.Webhook) (models.Webhook, error) {
+				return w, nil
+			})
+
+		res, err := s.CreateWebhook(ctx, models.WebhookRequest{
+			URL:        "https://partner.example/hooks",
+			EventTypes: []string{models.WebhookOrderProcessed},
+		})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, len(res.Secret), minWebhookSecretSize)
+	})
+
+	invalid := []struct {
+		name string
+		req  models.WebhookRequest
+	}{
+		{
+			name: "http url",
+			req:  models.WebhookRequest{URL: "http://partner.example/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "relative url",
+			req:  models.WebhookRequest{URL: "/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "url with credentials",
+			req:  models.WebhookRequest{URL: "https://a:b@partner.example/", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "too long url",
+			req: models.WebhookRequest{
+				URL:        "https://partner.example/" + strings.Repeat("a", maxWebhookURLLength),
+				EventTypes: []string{"ORDER_PROCESSED"},
+			},
+		},
+		{
+			name: "no event types",
+			req:  models.WebhookRequest{URL: "https://partner.example/hooks"},
+		},
+		{
+			name: "unknown event type",
+			req:  models.WebhookRequest{URL: "https://partner.example/hooks", EventTypes: []string{"ORDER_DELETED"}},
+		},
+		{
+			name: "loopback address",
+			req:  models.WebhookRequest{URL: "https://127.0.0.1/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "private network address",
+			req:  models.WebhookRequest{URL: "https://10.0.0.5:8443/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "cloud metadata address",
+			req:  models.WebhookRequest{URL: "https://169.254.169.254/latest", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "ipv6 loopback address",
+			req:  models.WebhookRequest{URL: "https://[::1]/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "localhost",
+			req:  models.WebhookRequest{URL: "https://localhost/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "host resolves to private address",
+			req:  models.WebhookRequest{URL: "https://internal.example/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "unresolvable host",
+			req:  models.WebhookRequest{URL: "https://unknown.example/hooks", EventTypes: []string{"ORDER_PROCESSED"}},
+		},
+		{
+			name: "short secret",
+			req: models.WebhookRequest{
+				URL: "https://partner.example/hooks", Secret: "short", EventTypes: []string{"ORDER_PROCESSED"},
+			},
+		},
+	}
+
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := s.CreateWebhook(ctx, test.req)
+			assert.ErrorIs(t, err, ErrWebhookValidation)
+		})
+	}
+
+	t.Run("local http url in dev mode", func(t *testing.T) {
+		devSettings := config.Settings{DevMode: true, Webhooks: config.WebhookSettings{AllowPrivate: true}}
+		dev := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil,
+			&devSettings)
+
+		_ = store.EXPECT().AddWebhook(ctx, gomock.Any()).Times(1).Return(models.Webhook{ID: 1}, nil)
+
+		_, err := dev.CreateWebhook(ctx, models.WebhookRequest{
+			URL:        "http://localhost:9000/hooks",
+			EventTypes: []string{models.WebhookOrderProcessed},
+		})
+		require.NoError(t, err)
+	})
+}
+
+type fakeResolver map[string][]netip.Addr
+
+func (r fakeResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return addrs, nil
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+
+	_ = store.EXPECT().DeleteWebhook(ctx, int64(1)).Times(1).Return(nil)
+	require.NoError(t, s.DeleteWebhook(ctx, 1))
+
+	_ = store.EXPECT().DeleteWebhook(ctx, int64(2)).Times(1).
+		Return(fmt.Errorf("%w with ID: 2", data.ErrWebhookNotFound))
+	assert.ErrorIs(t, s.DeleteWebhook(ctx, 2), ErrWebhookNotFound)
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+	deliveries := []models.WebhookDelivery{{ID: 5, WebhookID: 1, Status: models.WebhookDeliveryFailed}}
+
+	t.Run("own webhook", func(t *testing.T) {
+		_ = store.EXPECT().GetWebhook(ctx, int64(1)).Times(1).Return(models.Webhook{ID: 1, UserID: 1}, nil)
+		_ = store.EXPECT().GetWebhookDeliveries(ctx, int64(1), int64(4), defaultPageLimit).Times(1).
+			Return(deliveries, nil)
+
+		res, err := s.GetWebhookDeliveries(ctx, models.WebhookDeliveriesRequest{WebhookID: 1, AfterID: 4})
+		require.NoError(t, err)
+		assert.Equal(t, deliveries, res)
+	})
+
+	t.Run("another user webhook", func(t *testing.T) {
+		_ = store.EXPECT().GetWebhook(ctx, int64(2)).Times(1).Return(models.Webhook{}, data.ErrWebhookNotFound)
+
+		_, err := s.GetWebhookDeliveries(ctx, models.WebhookDeliveriesRequest{WebhookID: 2})
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+	})
+
+	t.Run("bad page", func(t *testing.T) {
+		_, err := s.GetWebhookDeliveries(ctx, models.WebhookDeliveriesRequest{WebhookID: 1, Limit: maxPageLimit + 1})
+		assert.ErrorIs(t, err, ErrPaginationValidation)
+
+		_, err = s.GetWebhookDeliveries(ctx, models.WebhookDeliveriesRequest{WebhookID: 1, AfterID: -1})
+		assert.ErrorIs(t, err, ErrPaginationValidation)
+	})
+}
+
+func TestReplayWebhookDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.WithValue(context.Background(), common.KeyUserID, 1)
+
+	_ = store.EXPECT().GetWebhook(ctx, int64(1)).Times(1).Return(models.Webhook{ID: 1, UserID: 1}, nil)
+	_ = store.EXPECT().ReplayWebhookDeliveries(ctx, int64(1)).Times(1).Return(int64(3), nil)
+
+	res, err := s.ReplayWebhookDeliveries(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookReplayResponse{Replayed: 3}, res)
+
+	_ = store.EXPECT().GetWebhook(ctx, int64(2)).Times(1).Return(models.Webhook{}, data.ErrWebhookNotFound)
+
+	_, err = s.ReplayWebhookDeliveries(ctx, 2)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
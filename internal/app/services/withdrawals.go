@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/MihailSergeenkov/gophermart/internal/app/common"
 	"github.com/MihailSergeenkov/gophermart/internal/app/data"
 	"github.com/MihailSergeenkov/gophermart/internal/app/models"
 )
 
-var ErrInsufficientFunds = errors.New("user has insufficient funds")
+var (
+	ErrInsufficientFunds     = errors.New("user has insufficient funds")
+	ErrWithdrawSumValidation = errors.New("withdraw sum must be positive")
+)
 
 func (s *Services) GetWithdrawals(ctx context.Context) ([]models.Withdraw, error) {
 	withdrawals, err := s.store.GetWithdrawals(ctx)
@@ -21,17 +25,40 @@ func (s *Services) GetWithdrawals(ctx context.Context) ([]models.Withdraw, error
 }
 
 func (s *Services) AddWithdraw(ctx context.Context, req models.AddWithdrawRequest) error {
-	if err := checkOrderNumber(req.OrderNumber); err != nil {
+	if err := s.checkOrderNumber(req.OrderNumber); err != nil {
 		return fmt.Errorf("failed check order number: %w", err)
 	}
 
-	err := s.store.AddWithdraw(ctx, req.OrderNumber, req.Sum)
+	if req.Sum <= 0 {
+		return ErrWithdrawSumValidation
+	}
+
+	step, err := s.checkWithdrawTOTP(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	userID, _ := ctx.Value(common.KeyUserID).(int)
+
+	err = s.store.AddWithdraw(ctx, req.OrderNumber, req.Sum, step)
 	if err != nil {
 		if errors.Is(err, data.ErrUserInsufficientFunds) {
 			return ErrInsufficientFunds
 		}
+
+		// Код уже использован, например параллельным запросом.
+		if errors.Is(err, data.ErrTOTPStepUsed) {
+			return s.registerTOTPFailure(ctx, userID)
+		}
 		return fmt.Errorf("failed to add withdraw: %w", err)
 	}
+	s.auditor.Record(ctx, models.AuditEvent{
+		Action:     models.AuditWithdrawal,
+		TargetType: models.AuditTargetWithdrawal,
+		TargetID:   req.OrderNumber,
+		UserID:     userID,
+		After:      map[string]any{"order": req.OrderNumber, "sum": req.Sum},
+	})
 
 	return nil
 }
@@ -20,7 +20,7 @@ func TestAddWithdraw(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	errSome := errors.New("some error")
@@ -48,7 +48,7 @@ func TestAddWithdraw(t *testing.T) {
 			arg: arg{
 				req: models.AddWithdrawRequest{
 					OrderNumber: "12345678903",
-					Sum:         100,
+					Sum:         10000,
 				},
 			},
 			mResponse: mResponse{
@@ -63,7 +63,7 @@ func TestAddWithdraw(t *testing.T) {
 			arg: arg{
 				req: models.AddWithdrawRequest{
 					OrderNumber: "12345678903",
-					Sum:         100,
+					Sum:         10000,
 				},
 			},
 			mResponse: mResponse{
@@ -78,7 +78,7 @@ func TestAddWithdraw(t *testing.T) {
 			arg: arg{
 				req: models.AddWithdrawRequest{
 					OrderNumber: "12345678903",
-					Sum:         100,
+					Sum:         10000,
 				},
 			},
 			mResponse: mResponse{
@@ -92,7 +92,8 @@ func TestAddWithdraw(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_ = store.EXPECT().AddWithdraw(ctx, test.arg.req.OrderNumber, test.arg.req.Sum).Times(1).Return(test.mResponse.err)
+			_ = store.EXPECT().AddWithdraw(ctx, test.arg.req.OrderNumber, test.arg.req.Sum, int64(0)).Times(1).
+				Return(test.mResponse.err)
 
 			err := s.AddWithdraw(ctx, test.arg.req)
 
@@ -109,16 +110,16 @@ func TestValidationFailedAddWithdraw(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	req := models.AddWithdrawRequest{
 		OrderNumber: "123456789032222",
-		Sum:         100,
+		Sum:         10000,
 	}
 
 	t.Run("order number validation failed", func(t *testing.T) {
-		_ = store.EXPECT().AddWithdraw(ctx, gomock.Any(), gomock.Any()).Times(0)
+		_ = store.EXPECT().AddWithdraw(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
 		err := s.AddWithdraw(ctx, req)
 
@@ -128,13 +129,48 @@ func TestValidationFailedAddWithdraw(t *testing.T) {
 	})
 }
 
+func TestSumValidationFailedAddWithdraw(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := mocks.NewMockStorager(mockCtrl)
+	settings := config.Settings{}
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		sum  models.Points
+	}{
+		{
+			name: "zero sum",
+			sum:  0,
+		},
+		{
+			name: "negative sum",
+			sum:  -100,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_ = store.EXPECT().AddWithdraw(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			err := s.AddWithdraw(ctx, models.AddWithdrawRequest{OrderNumber: "12345678903", Sum: test.sum})
+
+			assert.ErrorIs(t, err, ErrWithdrawSumValidation)
+		})
+	}
+}
+
 func TestSuccessGetWithdrawals(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	withdrawals := []models.Withdraw{
@@ -160,7 +196,7 @@ func TestFailedGetWithdrawals(t *testing.T) {
 
 	store := mocks.NewMockStorager(mockCtrl)
 	settings := config.Settings{}
-	s := NewServices(store, &settings)
+	s := NewServices(store, testKeyset(t), mocks.NewMockNotifier(mockCtrl), nil, nopAuditor(mockCtrl), nil, &settings)
 
 	ctx := context.Background()
 	withdrawals := []models.Withdraw{}
@@ -0,0 +1,344 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/MihailSergeenkov/gophermart/internal/app/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	KeyIDHeader    = "kid"
+	defaultKeyID   = "default"
+	keysSeparator  = ","
+	keyIDSeparator = ":"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrEmptyKeyset    = errors.New("keyset has no keys")
+	ErrInvalidKey     = errors.New("invalid signing key")
+	ErrUnexpectedAlgo = errors.New("unexpected signing method")
+)
+
+// Key описывает ключ из конфигурации. Для HS256 задается Secret, для RS256 и EdDSA — PEM приватного ключа
+// (PrivateKey или PrivateKeyFile). Выведенный из оборота асимметричный ключ можно задать только публичной частью.
+type Key struct {
+	ID             string `json:"kid"`
+	Algorithm      string `json:"alg,omitempty"`
+	Secret         string `json:"secret,omitempty"`
+	PrivateKey     string `json:"private_key,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	PublicKey      string `json:"public_key,omitempty"`
+}
+
+type signingKey struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	id        string
+}
+
+// Keyset подписывает токены активным ключом и проверяет их любым из известных ключей,
+// чтобы после ротации ранее выданные токены оставались действительными.
+type Keyset struct {
+	keys   map[string]signingKey
+	order  []string
+	active string
+}
+
+type keysFile struct {
+	Active string `json:"active"`
+	Keys   []Key  `json:"keys"`
+}
+
+func NewKeyset(active string, keys ...Key) (*Keyset, error) {
+	if len(keys) == 0 {
+		return nil, ErrEmptyKeyset
+	}
+
+	ks := &Keyset{
+		keys:   make(map[string]signingKey, len(keys)),
+		order:  make([]string, 0, len(keys)),
+		active: active,
+	}
+
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, fmt.Errorf("%w: key ID must not be empty", ErrInvalidKey)
+		}
+
+		if _, ok := ks.keys[k.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate key ID %q", ErrInvalidKey, k.ID)
+		}
+
+		sk, err := parseKey(k)
+		if err != nil {
+			return nil, err
+		}
+
+		ks.keys[k.ID] = sk
+		ks.order = append(ks.order, k.ID)
+	}
+
+	if ks.active == "" {
+		ks.active = keys[0].ID
+	}
+
+	activeKey, ok := ks.keys[ks.active]
+	if !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, ks.active)
+	}
+
+	if activeKey.signKey == nil {
+		return nil, fmt.Errorf("%w: active key %q has no private part", ErrInvalidKey, ks.active)
+	}
+
+	return ks, nil
+}
+
+// LoadKeyset собирает набор ключей из файла AUTH_KEYS_FILE, переменной AUTH_KEYS
+// или, если они не заданы, из единственного SECRET_KEY. Каждый секрет HS256 проверяется config.CheckSecretKey.
+func LoadKeyset(settings *config.Settings) (*Keyset, error) {
+	active := settings.Auth.ActiveKeyID
+
+	var (
+		keys []Key
+		err  error
+	)
+
+	switch {
+	case settings.Auth.KeysFile != "":
+		active, keys, err = loadKeysFile(settings.Auth.KeysFile, active)
+	case settings.Auth.Keys != "":
+		keys, err = parseKeys(settings.Auth.Keys)
+	default:
+		active = defaultKeyID
+		keys = []Key{{ID: defaultKeyID, Secret: settings.SecretKey}}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if k.Algorithm != "" && k.Algorithm != AlgorithmHS256 {
+			continue
+		}
+
+		if err := settings.CheckSecretKey(k.Secret); err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidKey, k.ID, err)
+		}
+	}
+
+	return NewKeyset(active, keys...)
+}
+
+// ShortSecrets возвращает идентификаторы ключей HS256 с секретом короче config.MinSecretKeyLength.
+func (ks *Keyset) ShortSecrets() []string {
+	var ids []string
+
+	for _, id := range ks.order {
+		if secret, ok := ks.keys[id].signKey.([]byte); ok && len(secret) < config.MinSecretKeyLength {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func (ks *Keyset) Sign(claims jwt.Claims) (string, error) {
+	key := ks.keys[ks.active]
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header[KeyIDHeader] = key.id
+
+	tokenString, err := token.SignedString(key.signKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+func (ks *Keyset) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header[KeyIDHeader].(string)
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedAlgo, t.Header["alg"])
+	}
+
+	return key.verifyKey, nil
+}
+
+// JWKS возвращает публичные части асимметричных ключей. Секреты HS256 не публикуются.
+func (ks *Keyset) JWKS() models.JWKSet {
+	set := models.JWKSet{Keys: []models.JWK{}}
+
+	for _, id := range ks.order {
+		key := ks.keys[id]
+
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, models.JWK{
+				Kty: "RSA",
+				Kid: key.id,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, models.JWK{
+				Kty: "OKP",
+				Kid: key.id,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	return set
+}
+
+func parseKey(k Key) (signingKey, error) {
+	sk := signingKey{id: k.ID}
+
+	privatePEM, err := privateKeyPEM(k)
+	if err != nil {
+		return sk, err
+	}
+
+	switch k.Algorithm {
+	case "", AlgorithmHS256:
+		if k.Secret == "" {
+			return sk, fmt.Errorf("%w: key %q has empty secret", ErrInvalidKey, k.ID)
+		}
+		sk.method = jwt.SigningMethodHS256
+		sk.signKey = []byte(k.Secret)
+		sk.verifyKey = []byte(k.Secret)
+	case AlgorithmRS256:
+		sk.method = jwt.SigningMethodRS256
+		err = parseAsymmetricKey(&sk, privatePEM, k.PublicKey,
+			wrapParse(jwt.ParseRSAPrivateKeyFromPEM), wrapParse(jwt.ParseRSAPublicKeyFromPEM))
+	case AlgorithmEdDSA:
+		sk.method = jwt.SigningMethodEdDSA
+		err = parseAsymmetricKey(&sk, privatePEM, k.PublicKey,
+			wrapParse(jwt.ParseEdPrivateKeyFromPEM), wrapParse(jwt.ParseEdPublicKeyFromPEM))
+	default:
+		return sk, fmt.Errorf("%w: key %q has unsupported algorithm %q", ErrInvalidKey, k.ID, k.Algorithm)
+	}
+
+	if err != nil {
+		return sk, fmt.Errorf("%w: key %q: %w", ErrInvalidKey, k.ID, err)
+	}
+
+	return sk, nil
+}
+
+func parseAsymmetricKey(sk *signingKey, privatePEM string, publicPEM string,
+	parsePrivate func([]byte) (any, error), parsePublic func([]byte) (any, error)) error {
+	switch {
+	case privatePEM != "":
+		private, err := parsePrivate([]byte(privatePEM))
+		if err != nil {
+			return fmt.Errorf("failed to parse private key: %w", err)
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return ErrInvalidKey
+		}
+
+		sk.signKey = private
+		sk.verifyKey = signer.Public()
+	case publicPEM != "":
+		public, err := parsePublic([]byte(publicPEM))
+		if err != nil {
+			return fmt.Errorf("failed to parse public key: %w", err)
+		}
+
+		sk.verifyKey = public
+	default:
+		return errors.New("private or public key is required")
+	}
+
+	return nil
+}
+
+func wrapParse[T any](parse func([]byte) (T, error)) func([]byte) (any, error) {
+	return func(b []byte) (any, error) {
+		v, err := parse(b)
+		return v, err //nolint:wrapcheck // Ошибка оборачивается в parseAsymmetricKey
+	}
+}
+
+func privateKeyPEM(k Key) (string, error) {
+	if k.PrivateKeyFile == "" {
+		return k.PrivateKey, nil
+	}
+
+	content, err := os.ReadFile(k.PrivateKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read private key file: %w", err)
+	}
+
+	return string(content), nil
+}
+
+func loadKeysFile(path string, active string) (string, []Key, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+
+	var f keysFile
+	if err := json.Unmarshal(content, &f); err != nil {
+		return "", nil, fmt.Errorf("failed to parse keys file: %w", err)
+	}
+
+	if active == "" {
+		active = f.Active
+	}
+
+	return active, f.Keys, nil
+}
+
+func parseKeys(s string) ([]Key, error) {
+	parts := strings.Split(s, keysSeparator)
+	keys := make([]Key, 0, len(parts))
+
+	for _, part := range parts {
+		id, secret, ok := strings.Cut(strings.TrimSpace(part), keyIDSeparator)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected kid:secret pair", ErrInvalidKey)
+		}
+
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+
+	return keys, nil
+}
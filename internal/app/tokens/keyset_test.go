@@ -0,0 +1,283 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MihailSergeenkov/gophermart/internal/app/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysetRotation(t *testing.T) {
+	oldKeys, err := NewKeyset("old", Key{ID: "old", Secret: "old-secret"})
+	require.NoError(t, err)
+
+	oldToken, err := oldKeys.Sign(jwt.MapClaims{"sub": "1"})
+	require.NoError(t, err)
+
+	rotated, err := NewKeyset("new", Key{ID: "new", Secret: "new-secret"}, Key{ID: "old", Secret: "old-secret"})
+	require.NoError(t, err)
+
+	newToken, err := rotated.Sign(jwt.MapClaims{"sub": "1"})
+	require.NoError(t, err)
+
+	t.Run("new token is signed with active key", func(t *testing.T) {
+		token, err := jwt.Parse(newToken, rotated.Keyfunc)
+		require.NoError(t, err)
+		assert.Equal(t, "new", token.Header[KeyIDHeader])
+	})
+
+	t.Run("token signed with retired key is still valid", func(t *testing.T) {
+		token, err := jwt.Parse(oldToken, rotated.Keyfunc)
+		require.NoError(t, err)
+		assert.Equal(t, "old", token.Header[KeyIDHeader])
+	})
+
+	t.Run("token signed with removed key is rejected", func(t *testing.T) {
+		_, err := jwt.Parse(newToken, oldKeys.Keyfunc)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("token without key ID is rejected", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1"}).
+			SignedString([]byte("old-secret"))
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(token, rotated.Keyfunc)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("token with another algorithm is rejected", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "1"}).
+			SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(token, rotated.Keyfunc)
+		assert.Error(t, err)
+	})
+}
+
+func TestNewKeysetValidation(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		active string
+		keys   []Key
+	}{
+		{name: "no keys", err: ErrEmptyKeyset},
+		{name: "empty secret", keys: []Key{{ID: "a"}}, err: ErrInvalidKey},
+		{name: "duplicate key ID", keys: []Key{{ID: "a", Secret: "1"}, {ID: "a", Secret: "2"}}, err: ErrInvalidKey},
+		{name: "unknown active key", active: "b", keys: []Key{{ID: "a", Secret: "1"}}, err: ErrUnknownKey},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewKeyset(test.active, test.keys...)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestLoadKeyset(t *testing.T) {
+	const (
+		one = "first-secret-key-of-32-bytes-000"
+		two = "second-secret-key-of-32-bytes-00"
+	)
+
+	writeKeysFile := func(t *testing.T, content string) string {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		return path
+	}
+
+	keysFile := writeKeysFile(t, `{"active":"2","keys":[{"kid":"1","secret":"`+one+`"},{"kid":"2","secret":"`+two+`"}]}`)
+
+	tests := []struct {
+		name     string
+		settings config.Settings
+		active   string
+	}{
+		{
+			name:     "from secret key",
+			settings: config.Settings{SecretKey: one},
+			active:   defaultKeyID,
+		},
+		{
+			name:     "from env",
+			settings: config.Settings{Auth: config.AuthSettings{Keys: "1:" + one + ", 2:" + two}},
+			active:   "1",
+		},
+		{
+			name:     "from env with active key",
+			settings: config.Settings{Auth: config.AuthSettings{Keys: "1:" + one + ",2:" + two, ActiveKeyID: "2"}},
+			active:   "2",
+		},
+		{
+			name:     "from file",
+			settings: config.Settings{Auth: config.AuthSettings{KeysFile: keysFile}},
+			active:   "2",
+		},
+		{
+			name:     "default secret in dev mode",
+			settings: config.Settings{DevMode: true, Auth: config.AuthSettings{Keys: "1:1234567890,2:two"}},
+			active:   "1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ks, err := LoadKeyset(&test.settings)
+			require.NoError(t, err)
+			assert.Equal(t, test.active, ks.active)
+		})
+	}
+
+	t.Run("malformed env", func(t *testing.T) {
+		_, err := LoadKeyset(&config.Settings{Auth: config.AuthSettings{Keys: "secret"}})
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	defaultTests := []struct {
+		name     string
+		settings config.Settings
+	}{
+		{
+			name:     "default secret key",
+			settings: config.Settings{SecretKey: "1234567890"},
+		},
+		{
+			name:     "default secret in env",
+			settings: config.Settings{Auth: config.AuthSettings{Keys: "1:" + one + ",2:1234567890"}},
+		},
+		{
+			name: "default secret in file",
+			settings: config.Settings{Auth: config.AuthSettings{
+				KeysFile: writeKeysFile(t, `{"keys":[{"kid":"1","secret":"`+one+`"},{"kid":"2","secret":"1234567890"}]}`),
+			}},
+		},
+	}
+
+	for _, test := range defaultTests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadKeyset(&test.settings)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+			assert.ErrorIs(t, err, config.ErrDefaultSecretKey)
+		})
+	}
+
+	t.Run("short secrets are reported", func(t *testing.T) {
+		ks, err := LoadKeyset(&config.Settings{Auth: config.AuthSettings{Keys: "1:" + one + ",2:two,3:three"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2", "3"}, ks.ShortSecrets())
+	})
+}
+
+func TestAsymmetricKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	rsaDER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+	rsaPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rsaDER}))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	edPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}))
+
+	edPublicDER, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	require.NoError(t, err)
+	edPublicPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: edPublicDER}))
+
+	tests := []struct {
+		name string
+		alg  string
+		key  Key
+		kty  string
+	}{
+		{
+			name: "RS256",
+			alg:  AlgorithmRS256,
+			key:  Key{ID: "rsa", Algorithm: AlgorithmRS256, PrivateKey: rsaPEM},
+			kty:  "RSA",
+		},
+		{
+			name: "EdDSA",
+			alg:  AlgorithmEdDSA,
+			key:  Key{ID: "ed", Algorithm: AlgorithmEdDSA, PrivateKey: edPEM},
+			kty:  "OKP",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ks, err := NewKeyset("", test.key, Key{ID: "hmac", Secret: "secret"})
+			require.NoError(t, err)
+
+			signed, err := ks.Sign(jwt.MapClaims{"sub": "1"})
+			require.NoError(t, err)
+
+			token, err := jwt.Parse(signed, ks.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, test.alg, token.Method.Alg())
+
+			jwks := ks.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, test.key.ID, jwks.Keys[0].Kid)
+			assert.Equal(t, test.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, test.alg, jwks.Keys[0].Alg)
+		})
+	}
+
+	t.Run("retired public key verifies tokens", func(t *testing.T) {
+		signer, err := NewKeyset("ed", Key{ID: "ed", Algorithm: AlgorithmEdDSA, PrivateKey: edPEM})
+		require.NoError(t, err)
+
+		signed, err := signer.Sign(jwt.MapClaims{"sub": "1"})
+		require.NoError(t, err)
+
+		verifier, err := NewKeyset("hmac",
+			Key{ID: "hmac", Secret: "secret"},
+			Key{ID: "ed", Algorithm: AlgorithmEdDSA, PublicKey: edPublicPEM})
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(signed, verifier.Keyfunc)
+		assert.NoError(t, err)
+	})
+
+	t.Run("public key cannot be active", func(t *testing.T) {
+		_, err := NewKeyset("ed", Key{ID: "ed", Algorithm: AlgorithmEdDSA, PublicKey: edPublicPEM})
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("algorithm mismatch is rejected", func(t *testing.T) {
+		ks, err := NewKeyset("rsa", Key{ID: "rsa", Algorithm: AlgorithmRS256, PrivateKey: rsaPEM})
+		require.NoError(t, err)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1"})
+		token.Header[KeyIDHeader] = "rsa"
+		signed, err := token.SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(signed, ks.Keyfunc)
+		assert.ErrorIs(t, err, ErrUnexpectedAlgo)
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		_, err := NewKeyset("", Key{ID: "es", Algorithm: "ES256", PrivateKey: rsaPEM})
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
@@ -0,0 +1,71 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Секрет и ожидаемые значения из RFC 6238, приложение B (последние 6 цифр).
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		want string
+		unix int64
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, test := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(test.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, test.want, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	step, ok, err := Validate(rfcSecret, "005924", now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	_, ok, err = Validate(rfcSecret, "005924", now.Add(Period))
+	require.NoError(t, err)
+	assert.True(t, ok, "code from previous period is accepted")
+
+	_, ok, err = Validate(rfcSecret, "005924", now.Add(2*Period))
+	require.NoError(t, err)
+	assert.False(t, ok, "code from older period is rejected")
+
+	_, ok, err = Validate(rfcSecret, "12345", now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = Validate("not base32!", "123456", now)
+	assert.ErrorIs(t, err, ErrInvalidSecret)
+}
+
+func TestURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	u, err := url.Parse(URI("Gophermart", "user", secret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Gophermart:user", u.Path)
+	assert.Equal(t, secret, u.Query().Get("secret"))
+	assert.Equal(t, "Gophermart", u.Query().Get("issuer"))
+}